@@ -1,56 +1,157 @@
-package main
-
-import (
-	"Backend_Go/internal/config"
-	"Backend_Go/internal/entities"
-	"fmt"
-	"log"
-
-	"github.com/joho/godotenv"
-)
-
-func main() {
-	// load env
-	if err := godotenv.Load(".env.config"); err != nil {
-		log.Println("Warning: .env.config not found, relying on system env")
-	}
-
-	db, err := config.ConnectDB()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	fmt.Println("Starting Migration Backfill...")
-
-	// 1. Backfill Dealer Status
-	// If IsApproved is true, set Status = 'approved'.
-	// If IsApproved is false, set Status = 'pending' (and ensure IsApproved is false).
-	// We only touch records where Status is empty to avoid overwriting new data.
-	result := db.Model(&entities.Dealer{}).
-		Where("status = '' OR status IS NULL").
-		Updates(map[string]interface{}{
-			"status": db.Raw("CASE WHEN is_approved = true THEN 'approved' ELSE 'pending' END"),
-		})
-	if result.Error != nil {
-		log.Printf("Error backfilling dealers: %v\n", result.Error)
-	} else {
-		fmt.Printf("Backfilled %d dealers.\n", result.RowsAffected)
-	}
-
-	// 2. Backfill Car Status
-	// Existing cars should be 'approved' so they remain visible?
-	// Or 'pending'?
-	// Strategy: Set all existing cars to 'approved' to avoid hiding everything on production update.
-	// New cars created after this code deploy will be 'pending' by default in code.
-	resultCars := db.Model(&entities.Car{}).
-		Where("status = '' OR status IS NULL").
-		Update("status", "approved")
-
-	if resultCars.Error != nil {
-		log.Printf("Error backfilling cars: %v\n", resultCars.Error)
-	} else {
-		fmt.Printf("Backfilled %d cars to 'approved'.\n", resultCars.RowsAffected)
-	}
-
-	fmt.Println("Migration Complete.")
-}
+package main
+
+import (
+	"Backend_Go/internal/config"
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/media"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/catalog"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// load env
+	if err := godotenv.Load(".env.config"); err != nil {
+		log.Println("Warning: .env.config not found, relying on system env")
+	}
+
+	db, err := config.ConnectDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Starting Migration Backfill...")
+
+	// 1. Backfill Dealer Status
+	// If IsApproved is true, set Status = 'approved'.
+	// If IsApproved is false, set Status = 'pending' (and ensure IsApproved is false).
+	// We only touch records where Status is empty to avoid overwriting new data.
+	result := db.Model(&entities.Dealer{}).
+		Where("status = '' OR status IS NULL").
+		Updates(map[string]interface{}{
+			"status": db.Raw("CASE WHEN is_approved = true THEN 'approved' ELSE 'pending' END"),
+		})
+	if result.Error != nil {
+		log.Printf("Error backfilling dealers: %v\n", result.Error)
+	} else {
+		fmt.Printf("Backfilled %d dealers.\n", result.RowsAffected)
+	}
+
+	// 2. Backfill Car Status
+	// Existing cars should be 'approved' so they remain visible?
+	// Or 'pending'?
+	// Strategy: Set all existing cars to 'approved' to avoid hiding everything on production update.
+	// New cars created after this code deploy will be 'pending' by default in code.
+	resultCars := db.Model(&entities.Car{}).
+		Where("status = '' OR status IS NULL").
+		Update("status", "approved")
+
+	if resultCars.Error != nil {
+		log.Printf("Error backfilling cars: %v\n", resultCars.Error)
+	} else {
+		fmt.Printf("Backfilled %d cars to 'approved'.\n", resultCars.RowsAffected)
+	}
+
+	// 3. Normalize Car Brand/Model against the vehicle catalog
+	// Matched cars get catalog IDs and canonical names; unmatched spellings are
+	// listed so admins can add them as aliases and run the migration again.
+	carRepo := &repositories.CarRepository{DB: db}
+	catalogUsecase := &catalog.CatalogUsecase{
+		CatalogRepo: &repositories.CatalogRepository{DB: db},
+		CarRepo:     carRepo,
+	}
+	report, err := catalogUsecase.NormalizeExistingCars()
+	if err != nil {
+		log.Printf("Error normalizing cars against the catalog: %v\n", err)
+	} else {
+		fmt.Printf("Catalog: scanned %d cars, updated %d.\n", report.Scanned, report.Updated)
+		for _, u := range report.Unmatched {
+			fmt.Printf("  unmatched: %q %q (%d cars)\n", u.Brand, u.ModelName, u.Cars)
+		}
+	}
+
+	// 4. Backfill Car Search Text
+	// Cars created before full-text search have an empty search document.
+	if err := carRepo.ReindexSearch(0); err != nil {
+		log.Printf("Error reindexing car search text: %v\n", err)
+	} else {
+		fmt.Println("Reindexed car search text.")
+	}
+
+	// 5. Backfill Sales
+	// Cars marked sold before sales were recorded get a sale at their asking
+	// price, dated by the last transition to sold.
+	resultSales := db.Exec(`
+		INSERT INTO sales (created_at, updated_at, car_id, dealer_id, final_price, list_price,
+			sold_at, listed_at, days_on_market, recorded_by, lead_source, note)
+		SELECT NOW(), NOW(), c.id, c.dealer_id, c.price, c.price, s.sold_at, l.listed_at,
+			GREATEST(0, FLOOR(EXTRACT(EPOCH FROM s.sold_at - l.listed_at) / 86400)), 0, '', 'backfilled'
+		FROM cars c
+		CROSS JOIN LATERAL (SELECT COALESCE((SELECT MAX(h.created_at) FROM car_status_histories h
+			WHERE h.car_id = c.id AND h.to_status = 'sold'), c.updated_at) AS sold_at) s
+		CROSS JOIN LATERAL (SELECT COALESCE((SELECT MIN(h.created_at) FROM car_status_histories h
+			WHERE h.car_id = c.id AND h.to_status = 'approved'), c.created_at) AS listed_at) l
+		WHERE c.status = 'sold' AND c.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM sales x WHERE x.car_id = c.id AND x.deleted_at IS NULL)`)
+	if resultSales.Error != nil {
+		log.Printf("Error backfilling sales: %v\n", resultSales.Error)
+	} else {
+		fmt.Printf("Backfilled %d sales.\n", resultSales.RowsAffected)
+	}
+
+	// 6. Generate Image Renditions
+	// Photos uploaded before processing are served as-is, EXIF included.
+	// Each one is resized like a new upload and the raw original is removed.
+	var images []entities.CarImage
+	if err := db.Where("renditions IS NULL AND image_url LIKE ?", "/uploads/cars/%").Find(&images).Error; err != nil {
+		log.Printf("Error loading car images: %v\n", err)
+	}
+	processor, err := media.NewProcessor()
+	if err != nil {
+		log.Printf("Skipping image renditions: %v\n", err)
+		images = nil
+	}
+	processed := 0
+	for _, img := range images {
+		original := filepath.FromSlash(strings.TrimPrefix(img.ImageURL, "/"))
+		data, err := os.ReadFile(original)
+		if err != nil {
+			log.Printf("  image %d: %v\n", img.ID, err)
+			continue
+		}
+		dir, base := filepath.Dir(original), strings.TrimSuffix(filepath.Base(original), filepath.Ext(original))
+		outputs, err := processor.Process(data, dir, base)
+		if err != nil {
+			log.Printf("  image %d: %v\n", img.ID, err)
+			continue
+		}
+
+		urlDir := path.Dir(img.ImageURL)
+		renditions := map[string]entities.ImageRendition{}
+		for _, out := range outputs {
+			renditions[out.Name] = entities.ImageRendition{
+				Width:  out.Width,
+				Height: out.Height,
+				JPEG:   path.Join(urlDir, out.JPEG),
+				WebP:   path.Join(urlDir, out.WebP),
+			}
+		}
+		img.Renditions, img.ImageURL = renditions, renditions["large"].JPEG
+		if err := db.Model(&img).Select("renditions", "image_url").Updates(&img).Error; err != nil {
+			log.Printf("  image %d: %v\n", img.ID, err)
+			continue
+		}
+		os.Remove(original)
+		processed++
+	}
+	fmt.Printf("Generated renditions for %d of %d car images.\n", processed, len(images))
+
+	fmt.Println("Migration Complete.")
+}
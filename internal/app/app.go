@@ -2,20 +2,33 @@ package app
 
 import (
 	"Backend_Go/internal/controller/deliveries/http"
+	"Backend_Go/internal/media"
 	"Backend_Go/internal/repositories"
 	"Backend_Go/internal/routes"
+	"Backend_Go/internal/scheduler"
 	"Backend_Go/internal/ws"
+	"log"
+	"time"
 
 	adminUC "Backend_Go/internal/usecases/admin"
+	appointmentUC "Backend_Go/internal/usecases/appointment"
 	authUC "Backend_Go/internal/usecases/auth"
 	carUC "Backend_Go/internal/usecases/car"
 	carImageUC "Backend_Go/internal/usecases/car_image"
+	catalogUC "Backend_Go/internal/usecases/catalog"
 	"Backend_Go/internal/usecases/chat"
 	dealerUC "Backend_Go/internal/usecases/dealer"
 	favoriteUC "Backend_Go/internal/usecases/favorite"
+	feedUC "Backend_Go/internal/usecases/feed"
+	financeUC "Backend_Go/internal/usecases/finance"
+	inventoryUC "Backend_Go/internal/usecases/inventory"
 	lendUC "Backend_Go/internal/usecases/lend"
+	notificationUC "Backend_Go/internal/usecases/notification"
+	reservationUC "Backend_Go/internal/usecases/reservation"
 	reviewUC "Backend_Go/internal/usecases/review"
+	savedSearchUC "Backend_Go/internal/usecases/saved_search"
 	userUC "Backend_Go/internal/usecases/user"
+	valuationUC "Backend_Go/internal/usecases/valuation"
 	_ "Backend_Go/internal/ws"
 
 	"github.com/gofiber/fiber/v2"
@@ -24,7 +37,15 @@ import (
 )
 
 func NewApp(db *gorm.DB) *fiber.App {
-	app := fiber.New()
+	// Room for a full image upload plus the multipart overhead
+	uploadLimits := media.LoadUploadLimits()
+	imageProcessor, err := media.NewProcessor()
+	if err != nil {
+		log.Fatal(err)
+	}
+	app := fiber.New(fiber.Config{
+		BodyLimit: int(uploadLimits.MaxRequestBytes) + 1<<20,
+	})
 
 	// =====================================================
 	// ✅ STATIC FILES (ต้องอยู่บนสุด ไม่โดน middleware)
@@ -52,29 +73,92 @@ func NewApp(db *gorm.DB) *fiber.App {
 	favoriteRepo := &repositories.FavoriteRepository{DB: db}
 	reviewRepo := &repositories.ReviewRepository{DB: db}
 	reportRepo := &repositories.ReportRepository{DB: db}
+	notificationRepo := &repositories.NotificationRepository{DB: db}
+	savedSearchRepo := &repositories.SavedSearchRepository{DB: db}
+	priceHistoryRepo := &repositories.CarPriceHistoryRepository{DB: db}
+	statusHistoryRepo := &repositories.CarStatusHistoryRepository{DB: db}
+	revisionRepo := &repositories.CarRevisionRepository{DB: db}
+	importJobRepo := &repositories.ImportJobRepository{DB: db}
+	carStatRepo := &repositories.CarStatRepository{DB: db}
+	catalogRepo := &repositories.CatalogRepository{DB: db}
+	financeOfferRepo := &repositories.FinanceOfferRepository{DB: db}
+	appointmentRepo := &repositories.AppointmentRepository{DB: db}
+	reservationRepo := &repositories.ReservationRepository{DB: db}
+	saleRepo := &repositories.SaleRepository{DB: db}
 
 	userRepo := repositories.NewUserRepository(db)
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
 
+	// Chat / notification websocket hub
+	chatHub := ws.NewHub()
+	go chatHub.Run()
+
 	// =====================================================
 	// USECASES
 	// =====================================================
+	notificationUsecase := &notificationUC.NotificationUsecase{
+		NotificationRepo: notificationRepo,
+		Hub:              chatHub,
+	}
+
+	savedSearchUsecase := &savedSearchUC.SavedSearchUsecase{
+		SavedSearchRepo:     savedSearchRepo,
+		CarRepo:             carRepo,
+		NotificationUsecase: notificationUsecase,
+	}
+
 	authUsecase := authUC.NewAuthUsecase(
 		userRepo,
 		dealerRepo,
 		refreshTokenRepo,
 	)
 
+	dealerUsecase := &dealerUC.DealerUsecase{
+		DealerRepo: dealerRepo,
+		CarRepo:    carRepo,
+		ReviewRepo: reviewRepo,
+	}
+
+	catalogUsecase := &catalogUC.CatalogUsecase{
+		CatalogRepo: catalogRepo,
+		CarRepo:     carRepo,
+	}
+
+	financeUsecase := &financeUC.FinanceUsecase{
+		FinanceOfferRepo: financeOfferRepo,
+		CarRepo:          carRepo,
+	}
+
+	valuationUsecase := &valuationUC.ValuationUsecase{
+		CarRepo:        carRepo,
+		CatalogUsecase: catalogUsecase,
+	}
+
 	carUsecase := &carUC.CarUsecase{
-		CarRepo:      carRepo,
-		DealerRepo:   dealerRepo,
-		LeadRepo:     leadRepo,
-		FavoriteRepo: favoriteRepo,
+		CarRepo:             carRepo,
+		DealerRepo:          dealerRepo,
+		LeadRepo:            leadRepo,
+		FavoriteRepo:        favoriteRepo,
+		PriceHistoryRepo:    priceHistoryRepo,
+		StatusHistoryRepo:   statusHistoryRepo,
+		RevisionRepo:        revisionRepo,
+		ReservationRepo:     reservationRepo,
+		SaleRepo:            saleRepo,
+		UserRepo:            userRepo,
+		StatRepo:            carStatRepo,
+		NotificationUsecase: notificationUsecase,
+		DealerUsecase:       dealerUsecase,
+		SavedSearchUsecase:  savedSearchUsecase,
+		CatalogUsecase:      catalogUsecase,
+		FinanceUsecase:      financeUsecase,
+		ValuationUsecase:    valuationUsecase,
 	}
 
 	carImageUsecase := &carImageUC.CarImageUsecase{
-		CarImageRepo: carImageRepo,
-		CarRepo:      carRepo,
+		CarImageRepo:    carImageRepo,
+		CarRepo:         carRepo,
+		CarUsecase:      carUsecase,
+		MaxImagesPerCar: uploadLimits.MaxImagesPerCar,
 	}
 
 	leadUsecase := &lendUC.LeadUsecase{
@@ -91,6 +175,7 @@ func NewApp(db *gorm.DB) *fiber.App {
 	reviewUsecase := &reviewUC.ReviewUsecase{
 		ReviewRepo: reviewRepo,
 		DealerRepo: dealerRepo,
+		SaleRepo:   saleRepo,
 	}
 
 	adminUsecase := &adminUC.AdminUsecase{
@@ -98,12 +183,38 @@ func NewApp(db *gorm.DB) *fiber.App {
 		DealerRepo: dealerRepo,
 		ReportRepo: reportRepo,
 		CarRepo:    carRepo,
+		CarUsecase: carUsecase,
 	}
 
-	dealerUsecase := &dealerUC.DealerUsecase{
-		DealerRepo: dealerRepo,
+	inventoryUsecase := &inventoryUC.InventoryUsecase{
+		ImportJobRepo:       importJobRepo,
+		CarRepo:             carRepo,
+		CarUsecase:          carUsecase,
+		NotificationUsecase: notificationUsecase,
+	}
+	if err := inventoryUsecase.FailInterruptedImports(); err != nil {
+		log.Printf("inventory import: cannot fail interrupted jobs: %v", err)
+	}
+
+	feedUsecase := &feedUC.FeedUsecase{
 		CarRepo:    carRepo,
-		ReviewRepo: reviewRepo,
+		DealerRepo: dealerRepo,
+	}
+
+	appointmentUsecase := &appointmentUC.AppointmentUsecase{
+		AppointmentRepo:     appointmentRepo,
+		CarRepo:             carRepo,
+		DealerRepo:          dealerRepo,
+		LeadRepo:            leadRepo,
+		NotificationUsecase: notificationUsecase,
+	}
+
+	reservationUsecase := &reservationUC.ReservationUsecase{
+		ReservationRepo:     reservationRepo,
+		CarRepo:             carRepo,
+		LeadRepo:            leadRepo,
+		CarUsecase:          carUsecase,
+		NotificationUsecase: notificationUsecase,
 	}
 
 	userUsecase := &userUC.UserUsecase{
@@ -114,7 +225,11 @@ func NewApp(db *gorm.DB) *fiber.App {
 	// HANDLERS
 	// =====================================================
 	carHandler := &http.CarHandler{Usecase: carUsecase}
-	carImageHandler := &http.CarImageHandler{Usecase: carImageUsecase}
+	carImageHandler := &http.CarImageHandler{
+		Usecase: carImageUsecase,
+		Media:   imageProcessor,
+		Limits:  uploadLimits,
+	}
 	leadHandler := &http.LeadHandler{Usecase: leadUsecase}
 	favoriteHandler := &http.FavoriteHandler{Usecase: favoriteUsecase}
 	reviewHandler := &http.ReviewHandler{Usecase: reviewUsecase}
@@ -122,14 +237,21 @@ func NewApp(db *gorm.DB) *fiber.App {
 	dealerHandler := &http.DealerHandler{Usecase: dealerUsecase}
 	adminHandler := &http.AdminHandler{Usecase: adminUsecase}
 	authHandler := &http.AuthHandler{Usecase: authUsecase}
+	savedSearchHandler := &http.SavedSearchHandler{Usecase: savedSearchUsecase}
+	notificationHandler := &http.NotificationHandler{Usecase: notificationUsecase}
+	inventoryHandler := &http.InventoryHandler{Usecase: inventoryUsecase}
+	feedHandler := &http.FeedHandler{Usecase: feedUsecase}
+	catalogHandler := &http.CatalogHandler{Usecase: catalogUsecase}
+	valuationHandler := &http.ValuationHandler{Usecase: valuationUsecase}
+	financeHandler := &http.FinanceHandler{Usecase: financeUsecase}
+	appointmentHandler := &http.AppointmentHandler{Usecase: appointmentUsecase}
+	reservationHandler := &http.ReservationHandler{Usecase: reservationUsecase}
+	saleHandler := &http.SaleHandler{Usecase: carUsecase}
 
 	// =====================================================
 	// ROUTES
 	// =====================================================
 	// Chat Initialization
-	chatHub := ws.NewHub()
-	go chatHub.Run()
-
 	chatRepo := &repositories.ChatRepository{DB: db}
 	chatUsecase := &chat.ChatUsecase{
 		ChatRepo:   chatRepo,
@@ -155,8 +277,34 @@ func NewApp(db *gorm.DB) *fiber.App {
 		adminHandler,
 		authHandler,
 		chatHandler,
+		savedSearchHandler,
+		notificationHandler,
+		inventoryHandler,
+		feedHandler,
+		catalogHandler,
+		valuationHandler,
+		financeHandler,
+		appointmentHandler,
+		reservationHandler,
+		saleHandler,
 		dealerRepo,
+		carRepo,
+		carImageRepo,
 	)
 
+	// =====================================================
+	// BACKGROUND JOBS
+	// =====================================================
+	scheduler.Every("saved-search-digest", time.Hour, savedSearchUsecase.SendDailyDigests)
+	scheduler.Every("promotion-expiry", 5*time.Minute, carUsecase.ExpirePromotions)
+	scheduler.Every("promotion-reminder", 30*time.Minute, carUsecase.SendPromotionReminders)
+	scheduler.Every("car-view-prune", time.Hour, carUsecase.PruneViews)
+	scheduler.Every("listing-expiry", 15*time.Minute, carUsecase.ProcessListingExpiry)
+	scheduler.Every("scheduled-publish", time.Minute, carUsecase.PublishScheduledCars)
+	scheduler.Every("finance-offer-expiry", time.Hour, financeUsecase.ExpireOffers)
+	scheduler.Every("appointment-reminder", 15*time.Minute, appointmentUsecase.SendReminders)
+	scheduler.Every("reservation-expiry", 5*time.Minute, reservationUsecase.ExpireReservations)
+	scheduler.Every("deal-rating", 6*time.Hour, valuationUsecase.RefreshDealRatings)
+
 	return app
 }
@@ -39,6 +39,10 @@ func ConnectDB() (db *gorm.DB, err error) {
 		return nil, err
 	}
 
+	if err := migrateDealerCoordinates(db); err != nil {
+		return nil, err
+	}
+
 	// Auto Migrate
 	err = db.AutoMigrate(
 		&entities.User{},
@@ -52,11 +56,45 @@ func ConnectDB() (db *gorm.DB, err error) {
 		&entities.RefreshToken{},
 		&entities.Conversation{},
 		&entities.Message{},
+		&entities.Notification{},
+		&entities.SavedSearch{},
+		&entities.SavedSearchMatch{},
+		&entities.CarPriceHistory{},
+		&entities.CarStatusHistory{},
+		&entities.CarRevision{},
+		&entities.ImportJob{},
+		&entities.CarView{},
+		&entities.CarDailyStat{},
+		&entities.CatalogBrand{},
+		&entities.CatalogModel{},
+		&entities.CatalogTrim{},
+		&entities.FinanceOffer{},
+		&entities.DealerAvailability{},
+		&entities.DealerBlackout{},
+		&entities.Appointment{},
+		&entities.Reservation{},
+		&entities.ReservationEvent{},
+		&entities.Sale{},
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Full-text search index over the tokenized car document (see internal/search)
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_cars_search_text ON cars USING GIN (to_tsvector('simple', search_text))").Error; err != nil {
+		log.Printf("Migration warning: failed to create car search index: %v", err)
+	}
+
+	// Catalog names are matched case-insensitively (catalog.Key), so they must be unique that way
+	for _, stmt := range []string{
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_catalog_brands_name_key ON catalog_brands (LOWER(name)) WHERE deleted_at IS NULL",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_catalog_models_name_key ON catalog_models (brand_id, LOWER(name)) WHERE deleted_at IS NULL",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Printf("Migration warning: failed to create catalog name index (rename duplicate entries first): %v", err)
+		}
+	}
+
 	// MIGRATION: Fix existing cars with empty status -> 'approved'
 	// This ensures existing cars don't disappear from public listing.
 	// Only affects rows where status is NULL or empty string.
@@ -66,7 +104,53 @@ func ConnectDB() (db *gorm.DB, err error) {
 		log.Println("Migration: updated empty car statuses to 'approved'")
 	}
 
+	// MIGRATION: legacy 'hidden' status written by the old unpublish endpoint -> 'unpublished'
+	if err := db.Model(&entities.Car{}).Where("status = ?", "hidden").Update("status", entities.CarStatusUnpublished).Error; err != nil {
+		log.Printf("Migration warning: failed to update hidden car statuses: %v", err)
+	}
+
 	fmt.Println("Config Database Successful..")
 	return db, nil
 
 }
+
+// migrateDealerCoordinates converts the legacy free-text dealer latitude/longitude
+// columns to numbers. Values that are not valid coordinates are cleared first.
+func migrateDealerCoordinates(db *gorm.DB) error {
+	var dataType string
+	if err := db.Raw("SELECT data_type FROM information_schema.columns WHERE table_name = 'dealers' AND column_name = 'latitude'").
+		Scan(&dataType).Error; err != nil {
+		return err
+	}
+	if dataType != "text" && dataType != "character varying" {
+		return nil
+	}
+
+	numeric := `^\s*[-+]?[0-9]+(\.[0-9]+)?\s*$`
+	return db.Transaction(func(tx *gorm.DB) error {
+		stmts := []string{
+			"UPDATE dealers SET latitude = NULL WHERE latitude !~ ?",
+			"UPDATE dealers SET longitude = NULL WHERE longitude !~ ?",
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt, numeric).Error; err != nil {
+				return err
+			}
+		}
+
+		stmts = []string{
+			"ALTER TABLE dealers ALTER COLUMN latitude TYPE double precision USING trim(latitude)::double precision",
+			"ALTER TABLE dealers ALTER COLUMN longitude TYPE double precision USING trim(longitude)::double precision",
+			"UPDATE dealers SET latitude = NULL, longitude = NULL WHERE latitude IS NULL OR longitude IS NULL " +
+				"OR latitude NOT BETWEEN -90 AND 90 OR longitude NOT BETWEEN -180 AND 180",
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+
+		log.Println("Migration: converted dealer coordinates to numeric columns")
+		return nil
+	})
+}
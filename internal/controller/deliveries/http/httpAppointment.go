@@ -0,0 +1,220 @@
+package http
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/appointment"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AppointmentHandler struct {
+	Usecase *appointment.AppointmentUsecase
+}
+
+// appointmentError answers 409 when the slot was taken or the appointment
+// changed in the meantime
+func appointmentError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, repositories.ErrSlotTaken) || errors.Is(err, repositories.ErrAppointmentChanged) {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
+
+// ---------- Public ----------
+
+// GET /dealers/:id/slots?from=YYYY-MM-DD&days=7&car_id=
+func (h *AppointmentHandler) GetSlots(c *fiber.Ctx) error {
+	dealerID, err := c.ParamsInt("id")
+	if err != nil || dealerID <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid dealer id"})
+	}
+	slots, err := h.Usecase.GetSlots(uint(dealerID), uint(c.QueryInt("car_id")), c.Query("from"), c.QueryInt("days", 7))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(slots)
+}
+
+// ---------- Customer ----------
+
+// POST /appointments
+func (h *AppointmentHandler) Book(c *fiber.Ctx) error {
+	uid, _ := c.Locals("user_id").(uint)
+	var req appointment.BookingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	a, err := h.Usecase.Book(uid, req)
+	if err != nil {
+		return appointmentError(c, err)
+	}
+	return c.Status(201).JSON(a)
+}
+
+// GET /appointments
+func (h *AppointmentHandler) GetMyAppointments(c *fiber.Ctx) error {
+	uid, _ := c.Locals("user_id").(uint)
+	appointments, err := h.Usecase.GetMyAppointments(uid)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(appointments)
+}
+
+// POST /appointments/:id/reschedule
+func (h *AppointmentHandler) RescheduleMine(c *fiber.Ctx) error {
+	uid, _ := c.Locals("user_id").(uint)
+	return h.reschedule(c, appointment.Party{Role: appointment.PartyCustomer, ID: uid})
+}
+
+// POST /appointments/:id/cancel
+func (h *AppointmentHandler) CancelMine(c *fiber.Ctx) error {
+	uid, _ := c.Locals("user_id").(uint)
+	return h.cancel(c, appointment.Party{Role: appointment.PartyCustomer, ID: uid})
+}
+
+// ---------- Dealer ----------
+
+// GET /dealer/availability
+func (h *AppointmentHandler) GetAvailability(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	windows, err := h.Usecase.GetAvailability(dealerID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(windows)
+}
+
+// PUT /dealer/availability
+// Body: [{"weekday":1,"open_time":"09:00","close_time":"18:00","slot_minutes":60,"capacity":2}, ...]
+func (h *AppointmentHandler) SetAvailability(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	var windows []*entities.DealerAvailability
+	if err := c.BodyParser(&windows); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	saved, err := h.Usecase.SetAvailability(dealerID, windows)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(saved)
+}
+
+// GET /dealer/blackouts
+func (h *AppointmentHandler) GetBlackouts(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	blackouts, err := h.Usecase.GetBlackouts(dealerID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(blackouts)
+}
+
+// POST /dealer/blackouts
+func (h *AppointmentHandler) AddBlackout(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	var body struct {
+		Date   string `json:"date"`
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	blackout, err := h.Usecase.AddBlackout(dealerID, body.Date, body.Reason)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(blackout)
+}
+
+// DELETE /dealer/blackouts/:id
+func (h *AppointmentHandler) DeleteBlackout(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	id, _ := c.ParamsInt("id")
+	if err := h.Usecase.DeleteBlackout(dealerID, uint(id)); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ลบวันหยุดเรียบร้อย"})
+}
+
+// GET /dealer/appointments?status=&from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *AppointmentHandler) GetDealerAppointments(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	appointments, err := h.Usecase.GetDealerAppointments(dealerID, c.Query("status"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(appointments)
+}
+
+// POST /dealer/appointments/:id/confirm
+func (h *AppointmentHandler) Confirm(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	id, _ := c.ParamsInt("id")
+	a, err := h.Usecase.Confirm(dealerID, uint(id))
+	if err != nil {
+		return appointmentError(c, err)
+	}
+	return c.JSON(a)
+}
+
+// POST /dealer/appointments/:id/reschedule
+func (h *AppointmentHandler) RescheduleDealer(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	return h.reschedule(c, appointment.Party{Role: appointment.PartyDealer, ID: dealerID})
+}
+
+// POST /dealer/appointments/:id/cancel
+func (h *AppointmentHandler) CancelDealer(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	return h.cancel(c, appointment.Party{Role: appointment.PartyDealer, ID: dealerID})
+}
+
+// POST /dealer/appointments/:id/complete
+func (h *AppointmentHandler) Complete(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	id, _ := c.ParamsInt("id")
+	a, err := h.Usecase.Complete(dealerID, uint(id))
+	if err != nil {
+		return appointmentError(c, err)
+	}
+	return c.JSON(a)
+}
+
+// Body: {"start_at": "2026-01-02T10:00:00+07:00"}
+func (h *AppointmentHandler) reschedule(c *fiber.Ctx, party appointment.Party) error {
+	id, _ := c.ParamsInt("id")
+	var body struct {
+		StartAt time.Time `json:"start_at"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	a, err := h.Usecase.Reschedule(party, uint(id), body.StartAt)
+	if err != nil {
+		return appointmentError(c, err)
+	}
+	return c.JSON(a)
+}
+
+// Body: {"reason": "..."}
+func (h *AppointmentHandler) cancel(c *fiber.Ctx, party appointment.Party) error {
+	id, _ := c.ParamsInt("id")
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.BodyParser(&body)
+
+	a, err := h.Usecase.Cancel(party, uint(id), body.Reason)
+	if err != nil {
+		return appointmentError(c, err)
+	}
+	return c.JSON(a)
+}
@@ -1,214 +1,478 @@
-package http
-
-import (
-	"Backend_Go/internal/entities"
-	"Backend_Go/internal/usecases/car"
-	"Backend_Go/utils"
-	"fmt"
-
-	"github.com/gofiber/fiber/v2"
-)
-
-type CarHandler struct {
-	Usecase *car.CarUsecase
-}
-
-// POST /cars
-func (h *CarHandler) CreateCar(c *fiber.Ctx) error {
-	var carData entities.Car
-	if err := c.BodyParser(&carData); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	if err := h.Usecase.CreateCar(&carData); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.Status(201).JSON(fiber.Map{
-		"message": "สร้างรถสำเร็จ",
-		"car_id":  carData.ID,
-	})
-}
-
-// GET /cars
-func (h *CarHandler) GetCars(c *fiber.Ctx) error {
-	dealerID := c.Query("dealer_id")
-	var cars []*entities.Car
-
-	if dealerID != "" {
-		var did uint
-		if _, err := fmt.Sscanf(dealerID, "%d", &did); err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "invalid dealer_id"})
-		}
-
-		if err := h.Usecase.GetCarsByDealer(did, &cars); err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-		}
-		// Should filter by public if not the dealer themselves?
-		// For now, assume this public endpoint returns filtered cars by default or we need to add filter.
-		// UseCase.GetCarsByDealer returns all.
-		// If strict, we should filter.
-		// Let's rely on frontend or add filter in repo.
-		// For consistency with "strict role", public probably shouldn't see pending cars of a dealer.
-		// I will leave it as is for now as per minimal changes, but `GetPublicCars` for the main feed is key.
-		return c.JSON(cars)
-	}
-
-	if err := h.Usecase.GetPublicCars(&cars); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(cars)
-}
-
-// GET /cars/:id
-func (h *CarHandler) GetCarDetail(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-
-	car, err := h.Usecase.GetCarDetail(uint(id))
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(car)
-}
-
-// PUT /cars/:id
-func (h *CarHandler) UpdateCar(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-
-	var car entities.Car
-	if err := c.BodyParser(&car); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-	car.ID = uint(id)
-
-	if err := h.Usecase.UpdateCar(&car); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{"message": "แก้ไขรถเรียบร้อย"})
-}
-
-// DELETE /cars/:id
-func (h *CarHandler) DeleteCar(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-
-	uid := c.Locals("user_id")
-	if uid == nil {
-		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
-	}
-
-	if err := h.Usecase.DeleteCarByUser(uint(id), uid.(uint)); err != nil {
-		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{"message": "ส่งคำขอลบรถเรียบร้อย รอการอนุมัติจากแอดมิน"})
-}
-
-// POST /cars/:id/contact
-func (h *CarHandler) RecordContact(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-
-	var req struct {
-		Via      string `json:"via"`
-		DealerID uint   `json:"dealer_id"`
-	}
-
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	if err := h.Usecase.RecordContact(uint(id), req.DealerID, req.Via); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	go func() {
-		webhook := utils.GetEnv("NOTIFY_WEBHOOK_URL", "")
-		if webhook != "" {
-			_ = utils.SendWebhookNotification(webhook, fiber.Map{
-				"car_id":    id,
-				"dealer_id": req.DealerID,
-				"via":       req.Via,
-			})
-		}
-	}()
-
-	return c.JSON(fiber.Map{"message": "บันทึกการติดต่อเรียบร้อย"})
-}
-
-// PATCH /cars/:id/status
-func (h *CarHandler) SetStatus(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-
-	var req struct {
-		Status string `json:"status"`
-	}
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	if err := h.Usecase.SetStatus(uint(id), req.Status); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{"message": "อัปเดตสถานะเรียบร้อย"})
-}
-
-// POST /cars/:id/promote
-func (h *CarHandler) PromoteCar(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-
-	var req struct {
-		Days int `json:"days"`
-	}
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	if err := h.Usecase.PromoteCar(uint(id), req.Days); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{"message": "โปรโมทรถเรียบร้อย"})
-}
-
-// GET /cars/:id/stats
-func (h *CarHandler) GetStats(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-
-	car, err := h.Usecase.GetStats(uint(id))
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(car)
-}
-
-// PATCH /cars/:id/sold
-func (h *CarHandler) SetSold(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-	// Verify Ownership? Middleware OwnerCheck or here.
-	// For simplicity and robustness, Usecase should check or we check here.
-	// Assuming routes will use OwnerCheck middleware or we check here.
-	// Let's rely on Usecase Update logic or just call SetStatus.
-	// Ideally, check ownership.
-	// uid := c.Locals("user_id")
-	// But CarUsecase.SetStatus doesn't take UID.
-	// So we should probably use a middleware for ownership if possible, or trust restricted access.
-	// Given the context of "Production", I'll assume OwnerCheck middleware is applied in routes.
-
-	if err := h.Usecase.SetStatus(uint(id), "sold"); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(fiber.Map{"message": "Marked as sold"})
-}
-
-// PATCH /cars/:id/unpublish
-func (h *CarHandler) SetUnpublish(c *fiber.Ctx) error {
-	id, _ := c.ParamsInt("id")
-	if err := h.Usecase.SetStatus(uint(id), "hidden"); err != nil { // or "draft", "unpublished"
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(fiber.Map{"message": "Unpublished car"})
-}
+package http
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/car"
+	"Backend_Go/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CarHandler struct {
+	Usecase *car.CarUsecase
+}
+
+// POST /cars
+// "status": "draft" saves a draft instead of submitting for review; "publish_at" schedules publishing.
+func (h *CarHandler) CreateCar(c *fiber.Ctx) error {
+	var carData entities.Car
+	if err := c.BodyParser(&carData); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// The owner is always the logged-in dealer, never the request body
+	dealerID, ok := c.Locals("dealer_id").(uint)
+	if !ok {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: Dealer profile not found"})
+	}
+	carData.DealerID = dealerID
+
+	if err := h.Usecase.CreateCar(&carData); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "สร้างรถสำเร็จ",
+		"car_id":  carData.ID,
+	})
+}
+
+// GET /cars
+// Query: q, brand, model, year_min, year_max, price_min, price_max, mileage_max,
+// fuel_type, transmission, car_type, color, province, dealer_id, near, radius_km,
+// available, sort, page, limit.
+// brand, fuel_type, transmission, car_type and color accept comma-separated values.
+// available=true leaves out reserved cars.
+// page is capped at 500 and limit at 100.
+//
+// Response: {data, total, page, limit, total_pages, facets}. This endpoint used to
+// return a bare array of cars; clients must now read the cars from "data".
+func (h *CarHandler) GetCars(c *fiber.Ctx) error {
+	filter, err := parseCarFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	result, err := h.Usecase.SearchPublicCars(filter)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// GET /cars/:id
+func (h *CarHandler) GetCarDetail(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	uid, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("role").(string)
+
+	detail, err := h.Usecase.GetVisibleCar(uint(id), uid, role)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Count the view in the background; strings are cloned because Fiber reuses request buffers
+	viewer := car.Viewer{
+		UserID:    uid,
+		Role:      strings.Clone(role),
+		IP:        strings.Clone(c.IP()),
+		UserAgent: strings.Clone(c.Get(fiber.HeaderUserAgent)),
+	}
+	if !h.Usecase.QueueView(detail, viewer) {
+		log.Printf("view queue full, view of car %d dropped", detail.ID)
+	}
+
+	return c.JSON(detail)
+}
+
+// GET /dealer/cars/:id/stats?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *CarHandler) GetCarStats(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+
+	stats, err := h.Usecase.GetCarStats(uint(id), c.Query("from"), c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(stats)
+}
+
+// GET /cars/compare?ids=1,2,3&near=lat,lon
+func (h *CarHandler) CompareCars(c *fiber.Ctx) error {
+	var ids []uint
+	for _, part := range splitQuery(c.Query("ids")) {
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || id == 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid ids"})
+		}
+		ids = append(ids, uint(id))
+	}
+
+	near, err := parseNear(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	comparison, err := h.Usecase.CompareCars(ids, near)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(comparison)
+}
+
+// GET /cars/:id/similar?limit=8
+func (h *CarHandler) GetSimilarCars(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid car id"})
+	}
+
+	uid, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("role").(string)
+
+	cars, err := h.Usecase.GetSimilarCars(uint(id), c.QueryInt("limit", 8), uid, role)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(cars)
+}
+
+// GET /cars/:id/price-history
+func (h *CarHandler) GetPriceHistory(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid car id"})
+	}
+
+	uid, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("role").(string)
+	if _, err := h.Usecase.GetVisibleCar(uint(id), uid, role); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	history, err := h.Usecase.GetPriceHistory(uint(id))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(history)
+}
+
+// PUT, PATCH /cars/:id
+// Partial update: only the fields present in the body are changed
+func (h *CarHandler) UpdateCar(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+
+	// Only whitelisted fields are accepted; anything else (views, status, ...) is rejected
+	var req car.CarUpdate
+	dec := json.NewDecoder(bytes.NewReader(c.Body()))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	updated, err := h.Usecase.UpdateCar(uint(id), req, dealerActor(c))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "แก้ไขรถเรียบร้อย", "car": updated})
+}
+
+// DELETE /cars/:id
+func (h *CarHandler) DeleteCar(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.Usecase.DeleteCarByUser(uint(id), uid.(uint)); err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "ส่งคำขอลบรถเรียบร้อย รอการอนุมัติจากแอดมิน"})
+}
+
+// POST /cars/:id/contact
+func (h *CarHandler) RecordContact(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+
+	var req struct {
+		Via      string `json:"via"`
+		DealerID uint   `json:"dealer_id"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.Usecase.RecordContact(uint(id), req.DealerID, req.Via); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	go func() {
+		webhook := utils.GetEnv("NOTIFY_WEBHOOK_URL", "")
+		if webhook != "" {
+			_ = utils.SendWebhookNotification(webhook, fiber.Map{
+				"car_id":    id,
+				"dealer_id": req.DealerID,
+				"via":       req.Via,
+			})
+		}
+	}()
+
+	return c.JSON(fiber.Map{"message": "บันทึกการติดต่อเรียบร้อย"})
+}
+
+// PATCH /cars/:id/status
+func (h *CarHandler) SetStatus(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+
+	var req struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	updated, err := h.Usecase.ChangeStatus(uint(id), req.Status, dealerActor(c), req.Reason)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "อัปเดตสถานะเรียบร้อย", "status": updated.Status})
+}
+
+// GET /cars/:id/status-history
+func (h *CarHandler) GetStatusHistory(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+
+	history, err := h.Usecase.GetStatusHistory(uint(id))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(history)
+}
+
+// POST /cars/:id/promote
+func (h *CarHandler) PromoteCar(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+
+	var req struct {
+		Days int `json:"days"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.Usecase.PromoteCar(uint(id), req.Days); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "โปรโมทรถเรียบร้อย"})
+}
+
+// GET /cars/:id/stats
+func (h *CarHandler) GetStats(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+
+	car, err := h.Usecase.GetStats(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(car)
+}
+
+// PATCH /cars/:id/sold
+// Body (optional): {"final_price": 450000, "sold_at": "...", "buyer_id": 3, "lead_source": "line", "note": "..."}
+func (h *CarHandler) SetSold(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	// Ownership is checked by middleware.RequireCarOwner in routes
+
+	var in car.SaleInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&in); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	sale, err := h.Usecase.MarkSold(uint(id), dealerActor(c), in)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Marked as sold", "sale": sale})
+}
+
+// PATCH /cars/:id/unpublish
+func (h *CarHandler) SetUnpublish(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	if _, err := h.Usecase.ChangeStatus(uint(id), entities.CarStatusUnpublished, dealerActor(c), ""); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Unpublished car"})
+}
+
+// POST /cars/:id/renew
+func (h *CarHandler) RenewCar(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	renewed, err := h.Usecase.RenewCar(uint(id), dealerActor(c))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ต่ออายุประกาศเรียบร้อย", "expires_at": renewed.ExpiresAt})
+}
+
+// POST /cars/:id/schedule
+// Body: {"publish_at": "2026-01-02T09:00:00+07:00"}; null clears the schedule.
+func (h *CarHandler) SchedulePublish(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	var body struct {
+		PublishAt *time.Time `json:"publish_at"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	scheduled, err := h.Usecase.SchedulePublish(uint(id), body.PublishAt)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message":    "ตั้งเวลาเผยแพร่เรียบร้อย",
+		"status":     scheduled.Status,
+		"publish_at": scheduled.PublishAt,
+	})
+}
+
+// GET /dealer/cars/expired
+func (h *CarHandler) GetExpiredCars(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	cars, err := h.Usecase.GetExpiredCars(dealerID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(cars)
+}
+
+// dealerActor identifies the logged-in dealer for status transitions
+func dealerActor(c *fiber.Ctx) car.Actor {
+	uid, _ := c.Locals("user_id").(uint)
+	return car.Actor{UserID: uid, Role: car.ActorDealer}
+}
+
+// parseCarFilter reads the public listing query parameters
+func parseCarFilter(c *fiber.Ctx) (repositories.CarFilter, error) {
+	filter := repositories.CarFilter{
+		Query:        c.Query("q"),
+		Brands:       splitQuery(c.Query("brand")),
+		ModelName:    strings.TrimSpace(c.Query("model")),
+		FuelTypes:    splitQuery(c.Query("fuel_type")),
+		Transmission: splitQuery(c.Query("transmission")),
+		CarTypes:     splitQuery(c.Query("car_type")),
+		Colors:       splitQuery(c.Query("color")),
+		Province:     strings.TrimSpace(c.Query("province")),
+		Sort:         c.Query("sort"),
+		Available:    c.QueryBool("available"),
+	}
+
+	ints := map[string]*int{
+		"year_min":    &filter.YearMin,
+		"year_max":    &filter.YearMax,
+		"mileage_max": &filter.MileageMax,
+		"page":        &filter.Page,
+		"limit":       &filter.Limit,
+	}
+	for key, dst := range ints {
+		if v := c.Query(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return filter, fmt.Errorf("invalid %s", key)
+			}
+			*dst = n
+		}
+	}
+
+	floats := map[string]*float64{
+		"price_min": &filter.PriceMin,
+		"price_max": &filter.PriceMax,
+	}
+	for key, dst := range floats {
+		if v := c.Query(key); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f < 0 {
+				return filter, fmt.Errorf("invalid %s", key)
+			}
+			*dst = f
+		}
+	}
+
+	near, err := parseNear(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.Near = near
+
+	if v := c.Query("dealer_id"); v != "" {
+		did, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("invalid dealer_id")
+		}
+		filter.DealerID = uint(did)
+	}
+
+	return filter, nil
+}
+
+// parseNear reads near=lat,lon and radius_km; nil when near is absent
+func parseNear(c *fiber.Ctx) (*repositories.GeoPoint, error) {
+	near := c.Query("near")
+	if near == "" {
+		return nil, nil
+	}
+
+	lat, lon, ok := strings.Cut(near, ",")
+	if !ok {
+		return nil, fmt.Errorf("near must be lat,lon")
+	}
+	latitude, longitude, err := utils.ParseCoordinates(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	// "near=," parses as no location
+	if latitude == nil || longitude == nil {
+		return nil, fmt.Errorf("near must be lat,lon")
+	}
+
+	point := &repositories.GeoPoint{Lat: *latitude, Lon: *longitude}
+	if v := c.Query("radius_km"); v != "" {
+		radius, err := strconv.ParseFloat(v, 64)
+		if err != nil || !(radius > 0) || math.IsInf(radius, 1) {
+			return nil, fmt.Errorf("invalid radius_km")
+		}
+		point.RadiusKm = radius
+	}
+	return point, nil
+}
+
+// splitQuery splits a comma-separated query value, dropping blanks
+func splitQuery(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
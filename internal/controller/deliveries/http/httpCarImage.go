@@ -1,197 +1,334 @@
-package http
-
-import (
-	"Backend_Go/internal/entities"
-	carimage "Backend_Go/internal/usecases/car_image"
-	"fmt"
-	"log"
-	"os"
-	"path/filepath"
-	"strconv"
-	"time"
-
-	"github.com/gofiber/fiber/v2"
-)
-
-type CarImageHandler struct {
-	Usecase *carimage.CarImageUsecase
-}
-
-// POST /cars/:id/images - AddImages creates car images (supports multiple files)
-func (h *CarImageHandler) AddImages(c *fiber.Ctx) error {
-	// 1️⃣ parse carID
-	carIDParam := c.Params("id")
-	carID, err := strconv.ParseUint(carIDParam, 10, 32)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "invalid car_id",
-		})
-	}
-
-	// 2️⃣ get multipart form
-	form, err := c.MultipartForm()
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "invalid multipart form",
-		})
-	}
-
-	files := form.File["images"]
-	if len(files) == 0 {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "at least one image file is required",
-		})
-	}
-
-	// 3️⃣ สร้างโฟลเดอร์ (อิงจาก WORKDIR)
-	uploadDir := filepath.Join("uploads", "cars", carIDParam)
-	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "cannot create upload directory",
-		})
-	}
-
-	var createdImages []*entities.CarImage
-
-	// 4️⃣ loop save files
-	for i, file := range files {
-		// ป้องกันชื่อไฟล์ซ้ำ
-		filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(file.Filename))
-		fullPath := filepath.Join(uploadDir, filename)
-
-		// 🔥 สำคัญมาก
-		if err := c.SaveFile(file, fullPath); err != nil {
-			log.Println("SaveFile error:", err)
-			continue
-		}
-
-		// 5️⃣ URL ที่ frontend ใช้
-		imageURL := fmt.Sprintf(
-			"/uploads/cars/%s/%s",
-			carIDParam,
-			filename,
-		)
-
-		image := &entities.CarImage{
-			CarID:     uint(carID),
-			ImageURL:  imageURL,
-			SortOrder: i,
-		}
-
-		// 6️⃣ save DB
-		if err := h.Usecase.CreateCarImage(image); err != nil {
-			log.Println("DB save error:", err)
-			continue
-		}
-
-		createdImages = append(createdImages, image)
-	}
-
-	if len(createdImages) == 0 {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "failed to save any images",
-		})
-	}
-
-	return c.Status(201).JSON(fiber.Map{
-		"message": "อัปโหลดรูปภาพสำเร็จ",
-		"images":  createdImages,
-		"count":   len(createdImages),
-	})
-}
-
-// GET /cars/:id/images - GetImages retrieves all images for a car
-func (h *CarImageHandler) GetImages(c *fiber.Ctx) error {
-	carID, err := strconv.ParseUint(c.Params("id"), 10, 32)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid car_id"})
-	}
-
-	images, err := h.Usecase.GetCarImages(uint(carID))
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{
-		"images": images,
-	})
-}
-
-// GET /images/:id - GetImage retrieves a specific image
-func (h *CarImageHandler) GetImage(c *fiber.Ctx) error {
-	imageID, err := strconv.ParseUint(c.Params("id"), 10, 32)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid image_id"})
-	}
-
-	image, err := h.Usecase.GetCarImage(uint(imageID))
-	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "image not found"})
-	}
-
-	return c.JSON(image)
-}
-
-// PUT /images/:id - UpdateImage updates a car image
-func (h *CarImageHandler) UpdateImage(c *fiber.Ctx) error {
-	imageID, err := strconv.ParseUint(c.Params("id"), 10, 32)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid image_id"})
-	}
-
-	var req struct {
-		ImageURL  string `json:"image_url"`
-		SortOrder int    `json:"sort_order"`
-	}
-
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
-	}
-
-	image := &entities.CarImage{
-		ImageURL:  req.ImageURL,
-		SortOrder: req.SortOrder,
-	}
-	image.ID = uint(imageID)
-
-	if err := h.Usecase.UpdateCarImage(image); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{
-		"message": "image updated successfully",
-		"image":   image,
-	})
-}
-
-// DELETE /images/:id - DeleteImage deletes a car image
-func (h *CarImageHandler) DeleteImage(c *fiber.Ctx) error {
-	imageID, err := strconv.ParseUint(c.Params("id"), 10, 32)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid image_id"})
-	}
-
-	if err := h.Usecase.DeleteCarImage(uint(imageID)); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{
-		"message": "image deleted successfully",
-	})
-}
-
-// DELETE /cars/:id/images - DeleteImages deletes all images for a car
-func (h *CarImageHandler) DeleteImages(c *fiber.Ctx) error {
-	carID, err := strconv.ParseUint(c.Params("id"), 10, 32)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid car_id"})
-	}
-
-	if err := h.Usecase.DeleteCarImages(uint(carID)); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{
-		"message": "all images deleted successfully",
-	})
-}
+package http
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/media"
+	carimage "Backend_Go/internal/usecases/car_image"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CarImageHandler struct {
+	Usecase *carimage.CarImageUsecase
+	Media   *media.Processor
+	Limits  media.UploadLimits
+}
+
+// POST /cars/:id/images - AddImages creates car images (supports multiple files)
+func (h *CarImageHandler) AddImages(c *fiber.Ctx) error {
+	// 1️⃣ parse carID
+	carIDParam := c.Params("id")
+	carID, err := strconv.ParseUint(carIDParam, 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "invalid car_id",
+		})
+	}
+
+	// 2️⃣ get multipart form
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "invalid multipart form",
+		})
+	}
+
+	files := form.File["images"]
+	if len(files) == 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "at least one image file is required",
+		})
+	}
+
+	// 3️⃣ ตรวจขนาดรวมและจำนวนรูปก่อนเริ่มประมวลผล
+	var total int64
+	for _, file := range files {
+		total += file.Size
+	}
+	if total > h.Limits.MaxRequestBytes {
+		return c.Status(413).JSON(fiber.Map{
+			"error": fmt.Sprintf("images must not exceed %d MB in total", h.Limits.MaxRequestBytes>>20),
+		})
+	}
+	remaining, err := h.Usecase.RemainingImageSlots(uint(carID))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if remaining == 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("a car can have at most %d images", h.Limits.MaxImagesPerCar),
+		})
+	}
+
+	// 4️⃣ สร้างโฟลเดอร์ (อิงจาก WORKDIR)
+	uploadDir := filepath.Join("uploads", "cars", carIDParam)
+	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "cannot create upload directory",
+		})
+	}
+
+	processed := []*entities.CarImage{}
+	uploadErrors := []uploadError{}
+
+	// 5️⃣ loop process files - ไฟล์ที่ไม่ผ่านจะถูกรายงานแยกทีละไฟล์
+	for i, file := range files {
+		if len(processed) == remaining {
+			uploadErrors = append(uploadErrors, newUploadError(i, file,
+				fmt.Sprintf("a car can have at most %d images", h.Limits.MaxImagesPerCar)))
+			continue
+		}
+		image, err := h.saveImage(file, uint(carID), carIDParam, uploadDir)
+		if err != nil {
+			uploadErrors = append(uploadErrors, newUploadError(i, file, err.Error()))
+			continue
+		}
+		image.SortOrder = i
+		processed = append(processed, image)
+	}
+
+	// Nothing usable was uploaded, so the listing is left as it is
+	if len(processed) == 0 {
+		return c.Status(422).JSON(fiber.Map{
+			"error":  "no images were saved",
+			"errors": uploadErrors,
+		})
+	}
+
+	// New photos on a live car may have to wait for review
+	uid, _ := c.Locals("user_id").(uint)
+	revisionID, err := h.Usecase.PrepareNewImages(uint(carID), uid)
+	if err != nil {
+		for _, image := range processed {
+			removeRenditions(uploadDir, image)
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// 6️⃣ save DB - นับจำนวนรูปซ้ำตอนบันทึก เผื่อมีการอัปโหลดอื่นเสร็จก่อน
+	for _, image := range processed {
+		image.RevisionID = revisionID
+	}
+	rejected, err := h.Usecase.SaveNewImages(uint(carID), processed)
+	if err != nil {
+		log.Println("DB save error:", err)
+		for _, image := range processed {
+			removeRenditions(uploadDir, image)
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "could not save images"})
+	}
+	for _, image := range rejected {
+		removeRenditions(uploadDir, image)
+		uploadErrors = append(uploadErrors, newUploadError(image.SortOrder, files[image.SortOrder],
+			fmt.Sprintf("a car can have at most %d images", h.Limits.MaxImagesPerCar)))
+	}
+	createdImages := processed[:len(processed)-len(rejected)]
+
+	if len(createdImages) == 0 {
+		return c.Status(422).JSON(fiber.Map{
+			"error":  "no images were saved",
+			"errors": uploadErrors,
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":        "อัปโหลดรูปภาพสำเร็จ",
+		"images":         createdImages,
+		"count":          len(createdImages),
+		"errors":         uploadErrors,
+		"pending_review": revisionID != nil,
+	})
+}
+
+// uploadError reports why one file of an upload was rejected
+type uploadError struct {
+	Index    int    `json:"index"`
+	Filename string `json:"filename"`
+	Error    string `json:"error"`
+}
+
+func newUploadError(index int, file *multipart.FileHeader, msg string) uploadError {
+	return uploadError{Index: index, Filename: filepath.Base(file.Filename), Error: msg}
+}
+
+// saveImage validates one upload and writes its renditions. The returned
+// error is safe to show to the dealer.
+func (h *CarImageHandler) saveImage(file *multipart.FileHeader, carID uint, carIDParam, uploadDir string) (*entities.CarImage, error) {
+	if file.Size > h.Limits.MaxFileBytes {
+		return nil, fmt.Errorf("file must not exceed %d MB", h.Limits.MaxFileBytes>>20)
+	}
+	data, err := readUpload(file, h.Limits.MaxFileBytes)
+	if err != nil {
+		log.Println("read upload error:", err)
+		return nil, errors.New("could not read file")
+	}
+
+	// ป้องกันชื่อไฟล์ซ้ำ - ไม่ใช้ชื่อไฟล์จากผู้ใช้
+	base := strconv.FormatInt(time.Now().UnixNano(), 10)
+	outputs, err := h.Media.Process(data, uploadDir, base)
+	if errors.Is(err, media.ErrInvalidImage) {
+		return nil, err
+	}
+	if err != nil {
+		log.Println("image processing error:", err)
+		return nil, errors.New("could not process image")
+	}
+
+	// URL ที่ frontend ใช้
+	image := &entities.CarImage{
+		CarID:      carID,
+		Renditions: map[string]entities.ImageRendition{},
+	}
+	for _, out := range outputs {
+		image.Renditions[out.Name] = entities.ImageRendition{
+			Width:  out.Width,
+			Height: out.Height,
+			JPEG:   uploadURL(carIDParam, out.JPEG),
+			WebP:   uploadURL(carIDParam, out.WebP),
+		}
+	}
+	image.ImageURL = image.Renditions["large"].JPEG
+	return image, nil
+}
+
+// removeRenditions deletes the files written for an image that was not saved
+func removeRenditions(uploadDir string, image *entities.CarImage) {
+	for _, r := range image.Renditions {
+		for _, url := range []string{r.JPEG, r.WebP} {
+			if url == "" {
+				continue
+			}
+			if err := os.Remove(filepath.Join(uploadDir, path.Base(url))); err != nil && !os.IsNotExist(err) {
+				log.Println("remove rendition error:", err)
+			}
+		}
+	}
+}
+
+// readUpload reads an uploaded file of at most limit bytes into memory
+func readUpload(file *multipart.FileHeader, limit int64) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err == nil && int64(len(data)) > limit {
+		err = errors.New("file is larger than its declared size")
+	}
+	return data, err
+}
+
+// uploadURL is the public URL of a file under ./uploads/cars/:id
+func uploadURL(carID, filename string) string {
+	return fmt.Sprintf("/uploads/cars/%s/%s", carID, filename)
+}
+
+// GET /cars/:id/images - GetImages retrieves all images for a car
+func (h *CarImageHandler) GetImages(c *fiber.Ctx) error {
+	carID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid car_id"})
+	}
+
+	uid, _ := c.Locals("user_id").(uint)
+	role, _ := c.Locals("role").(string)
+	images, err := h.Usecase.GetCarImages(uint(carID), uid, role)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"images": images,
+	})
+}
+
+// GET /images/:id - GetImage retrieves a specific image
+func (h *CarImageHandler) GetImage(c *fiber.Ctx) error {
+	imageID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid image_id"})
+	}
+
+	image, err := h.Usecase.GetCarImage(uint(imageID))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "image not found"})
+	}
+
+	return c.JSON(image)
+}
+
+// PUT /images/:id - UpdateImage changes the sort order of a live car image.
+// Photos themselves are replaced by uploading new ones (POST /cars/:id/images).
+func (h *CarImageHandler) UpdateImage(c *fiber.Ctx) error {
+	imageID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid image_id"})
+	}
+
+	var req struct {
+		SortOrder *int `json:"sort_order"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.SortOrder == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "sort_order is required"})
+	}
+
+	image, err := h.Usecase.UpdateSortOrder(uint(imageID), *req.SortOrder)
+	if errors.Is(err, carimage.ErrImageNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "image updated successfully",
+		"image":   image,
+	})
+}
+
+// DELETE /images/:id - DeleteImage deletes a car image
+func (h *CarImageHandler) DeleteImage(c *fiber.Ctx) error {
+	imageID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid image_id"})
+	}
+
+	if err := h.Usecase.DeleteCarImage(uint(imageID)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "image deleted successfully",
+	})
+}
+
+// DELETE /cars/:id/images - DeleteImages deletes all images for a car
+func (h *CarImageHandler) DeleteImages(c *fiber.Ctx) error {
+	carID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid car_id"})
+	}
+
+	if err := h.Usecase.DeleteCarImages(uint(carID)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "all images deleted successfully",
+	})
+}
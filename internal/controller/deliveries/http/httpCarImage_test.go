@@ -0,0 +1,39 @@
+package http
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"Backend_Go/internal/entities"
+)
+
+func TestRemoveRenditions(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"1_large.jpg", "1_large.webp", "1_thumb.jpg"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keep := filepath.Join(dir, "2_large.jpg")
+	if err := os.WriteFile(keep, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	image := &entities.CarImage{Renditions: map[string]entities.ImageRendition{
+		"large": {JPEG: uploadURL("7", "1_large.jpg"), WebP: uploadURL("7", "1_large.webp")},
+		"thumb": {JPEG: uploadURL("7", "1_thumb.jpg")},
+		"small": {JPEG: uploadURL("7", "1_small.jpg")}, // never written
+	}}
+	removeRenditions(dir, image)
+
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", name)
+		}
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Errorf("file of another image was removed: %v", err)
+	}
+}
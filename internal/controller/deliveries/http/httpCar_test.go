@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParseNear(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if _, err := parseNear(c); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.SendStatus(200)
+	})
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 200},
+		{"?near=13.75,100.5", 200},
+		{"?near=13.75,100.5&radius_km=25", 200},
+		{"?near=,", 400},
+		{"?near=%20,%20", 400},
+		{"?near=13.75", 400},
+		{"?near=NaN,100", 400},
+		{"?near=13.75,100.5&radius_km=NaN", 400},
+		{"?near=13.75,100.5&radius_km=Inf", 400},
+		{"?near=13.75,100.5&radius_km=-1", 400},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", "/"+tt.query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET /%s = %d, want %d", tt.query, resp.StatusCode, tt.want)
+		}
+	}
+}
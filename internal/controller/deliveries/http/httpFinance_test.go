@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestFinanceCalculateValidatesQuery(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", (&FinanceHandler{}).Calculate)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"?price=500000&down_percent=25&term=48&rate=3", 200},
+		{"?price=NaN&down_percent=25&term=48&rate=3", 400},
+		{"?price=Inf&down_percent=25&term=48&rate=3", 400},
+		{"?price=500000&down_percent=-Inf&term=48&rate=3", 400},
+		{"?price=500000&down_percent=25&term=48&rate=NaN", 400},
+		{"?price=500000&down_percent=25&term=6.9&rate=3", 400},
+		{"?price=500000&down_percent=25&term=&rate=3", 400},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", "/"+tt.query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET /%s = %d, want %d", tt.query, resp.StatusCode, tt.want)
+		}
+	}
+}
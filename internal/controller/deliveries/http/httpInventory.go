@@ -0,0 +1,91 @@
+package http
+
+import (
+	"Backend_Go/internal/usecases/inventory"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type InventoryHandler struct {
+	Usecase *inventory.InventoryUsecase
+}
+
+// POST /dealer/inventory/import
+// Multipart: file (.csv or .xlsx), mapping (optional JSON {"brand": "Make", ...}), dry_run (true/false)
+func (h *InventoryHandler) ImportInventory(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	uid, _ := c.Locals("user_id").(uint)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "file is required"})
+	}
+	f, err := file.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "cannot read file"})
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "cannot read file"})
+	}
+
+	var mapping map[string]string
+	if raw := c.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid mapping"})
+		}
+	}
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+
+	job, err := h.Usecase.StartImport(dealerID, uid, file.Filename, data, mapping, dryRun)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(202).JSON(job)
+}
+
+// GET /dealer/inventory/export?format=csv|xlsx
+func (h *InventoryHandler) ExportInventory(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	format := c.Query("format", "csv")
+
+	data, err := h.Usecase.ExportInventory(dealerID, format)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Attachment(fmt.Sprintf("inventory-%s.%s", time.Now().Format("20060102"), format))
+	if format == "xlsx" {
+		c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	}
+	return c.Send(data)
+}
+
+// GET /dealer/inventory/imports
+func (h *InventoryHandler) GetImportJobs(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	jobs, err := h.Usecase.GetImportJobs(dealerID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(jobs)
+}
+
+// GET /dealer/inventory/import/:id
+func (h *InventoryHandler) GetImportJob(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	id, _ := c.ParamsInt("id")
+	job, err := h.Usecase.GetImportJob(dealerID, uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(job)
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"Backend_Go/internal/usecases/notification"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationHandler struct {
+	Usecase *notification.NotificationUsecase
+}
+
+// GET /users/me/notifications?unread=true
+func (h *NotificationHandler) GetMyNotifications(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	notifications, err := h.Usecase.GetNotifications(uid.(uint), c.QueryBool("unread"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(notifications)
+}
+
+// GET /users/me/notifications/unread-count
+func (h *NotificationHandler) GetUnreadCount(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	count, err := h.Usecase.GetUnreadCount(uid.(uint))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"unread_count": count})
+}
+
+// PATCH /users/me/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid notification id"})
+	}
+
+	if err := h.Usecase.MarkRead(uid.(uint), uint(id)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "marked as read"})
+}
+
+// PATCH /users/me/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.Usecase.MarkRead(uid.(uint), 0); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "marked all as read"})
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/car"
+	"Backend_Go/internal/usecases/reservation"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReservationHandler struct {
+	Usecase *reservation.ReservationUsecase
+}
+
+func customerParty(c *fiber.Ctx) reservation.Party {
+	uid, _ := c.Locals("user_id").(uint)
+	return reservation.Party{Role: reservation.PartyCustomer, ID: uid, UserID: uid}
+}
+
+func dealerParty(c *fiber.Ctx) reservation.Party {
+	uid, _ := c.Locals("user_id").(uint)
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	return reservation.Party{Role: reservation.PartyDealer, ID: dealerID, UserID: uid}
+}
+
+// reservationError answers 409 when the reservation or its car changed in the meantime
+func reservationError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, repositories.ErrReservationChanged) || errors.Is(err, repositories.ErrStatusChanged) {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
+
+// ---------- Customer ----------
+
+// POST /reservations
+// Body: {"car_id": 1, "note": "..."}
+func (h *ReservationHandler) Request(c *fiber.Ctx) error {
+	uid, _ := c.Locals("user_id").(uint)
+	var body struct {
+		CarID uint   `json:"car_id"`
+		Note  string `json:"note"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	r, err := h.Usecase.Request(uid, body.CarID, body.Note)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(r)
+}
+
+// GET /reservations
+func (h *ReservationHandler) GetMyReservations(c *fiber.Ctx) error {
+	uid, _ := c.Locals("user_id").(uint)
+	reservations, err := h.Usecase.GetMyReservations(uid)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(reservations)
+}
+
+// GET /reservations/:id
+func (h *ReservationHandler) GetMine(c *fiber.Ctx) error {
+	return h.get(c, customerParty(c))
+}
+
+// POST /reservations/:id/cancel
+// Body: {"reason": "..."}
+func (h *ReservationHandler) Cancel(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	r, err := h.Usecase.Cancel(customerParty(c), uint(id), bodyReason(c))
+	if err != nil {
+		return reservationError(c, err)
+	}
+	return c.JSON(r)
+}
+
+// ---------- Dealer ----------
+
+// GET /dealer/reservations?status=
+func (h *ReservationHandler) GetDealerReservations(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	reservations, err := h.Usecase.GetDealerReservations(dealerID, c.Query("status"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(reservations)
+}
+
+// GET /dealer/reservations/:id
+func (h *ReservationHandler) GetDealer(c *fiber.Ctx) error {
+	return h.get(c, dealerParty(c))
+}
+
+// POST /dealer/reservations/:id/accept
+// Body: {"deposit_amount": 5000, "hours": 72}
+func (h *ReservationHandler) Accept(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	var body struct {
+		DepositAmount float64 `json:"deposit_amount"`
+		Hours         int     `json:"hours"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	r, err := h.Usecase.Accept(dealerParty(c), uint(id), body.DepositAmount, body.Hours)
+	if err != nil {
+		return reservationError(c, err)
+	}
+	return c.JSON(r)
+}
+
+// POST /dealer/reservations/:id/reject
+// Body: {"reason": "..."}
+func (h *ReservationHandler) Reject(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	r, err := h.Usecase.Reject(dealerParty(c), uint(id), bodyReason(c))
+	if err != nil {
+		return reservationError(c, err)
+	}
+	return c.JSON(r)
+}
+
+// POST /dealer/reservations/:id/release
+// Body: {"reason": "..."}
+func (h *ReservationHandler) Release(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	r, err := h.Usecase.Release(dealerParty(c), uint(id), bodyReason(c))
+	if err != nil {
+		return reservationError(c, err)
+	}
+	return c.JSON(r)
+}
+
+// POST /dealer/reservations/:id/complete
+// Body (optional): {"final_price": 450000, "sold_at": "...", "note": "..."}
+func (h *ReservationHandler) Complete(c *fiber.Ctx) error {
+	id, _ := c.ParamsInt("id")
+	var in car.SaleInput
+	_ = c.BodyParser(&in)
+
+	r, err := h.Usecase.Complete(dealerParty(c), uint(id), in)
+	if err != nil {
+		return reservationError(c, err)
+	}
+	return c.JSON(r)
+}
+
+func (h *ReservationHandler) get(c *fiber.Ctx, party reservation.Party) error {
+	id, _ := c.ParamsInt("id")
+	r, err := h.Usecase.Get(party, uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(r)
+}
+
+// bodyReason reads an optional {"reason": "..."} body
+func bodyReason(c *fiber.Ctx) string {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.BodyParser(&body)
+	return body.Reason
+}
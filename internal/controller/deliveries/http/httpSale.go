@@ -0,0 +1,60 @@
+package http
+
+import (
+	"Backend_Go/internal/usecases/car"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SaleHandler struct {
+	Usecase *car.CarUsecase
+}
+
+// GET /dealer/sales?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *SaleHandler) GetSales(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	sales, err := h.Usecase.GetSales(dealerID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(sales)
+}
+
+// GET /dealer/sales/stats?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *SaleHandler) GetSalesStats(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	stats, err := h.Usecase.GetSalesStats(dealerID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(stats)
+}
+
+// PUT /dealer/sales/:id
+// Body: {"final_price": 450000, "sold_at": "...", "buyer_id": 3, "lead_source": "chat", "note": "..."}
+// Only the fields sent are changed; "buyer_id": 0 removes the buyer.
+func (h *SaleHandler) CorrectSale(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	id, _ := c.ParamsInt("id")
+	var in car.SaleCorrection
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	sale, err := h.Usecase.CorrectSale(dealerID, uint(id), in)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(sale)
+}
+
+// DELETE /dealer/sales/:id
+// Undoes a sale; a car that is still sold goes back on sale.
+func (h *SaleHandler) UndoSale(c *fiber.Ctx) error {
+	dealerID, _ := c.Locals("dealer_id").(uint)
+	id, _ := c.ParamsInt("id")
+	if err := h.Usecase.UndoSale(dealerID, uint(id), dealerActor(c)); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ยกเลิกการขายเรียบร้อย"})
+}
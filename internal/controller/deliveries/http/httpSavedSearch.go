@@ -0,0 +1,102 @@
+package http
+
+import (
+	"Backend_Go/internal/entities"
+	savedsearch "Backend_Go/internal/usecases/saved_search"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SavedSearchHandler struct {
+	Usecase *savedsearch.SavedSearchUsecase
+}
+
+type savedSearchRequest struct {
+	Name      string                     `json:"name"`
+	Filters   entities.SavedSearchFilter `json:"filters"`
+	Frequency string                     `json:"frequency"`
+	IsActive  *bool                      `json:"is_active"`
+}
+
+// GET /users/me/saved-searches
+func (h *SavedSearchHandler) GetMySavedSearches(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	searches, err := h.Usecase.GetSavedSearches(uid.(uint))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(searches)
+}
+
+// POST /users/me/saved-searches
+func (h *SavedSearchHandler) CreateSavedSearch(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var req savedSearchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	s := &entities.SavedSearch{
+		Name:      req.Name,
+		Filters:   req.Filters,
+		Frequency: req.Frequency,
+	}
+	if err := h.Usecase.CreateSavedSearch(uid.(uint), s); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(s)
+}
+
+// PUT /users/me/saved-searches/:id
+func (h *SavedSearchHandler) UpdateSavedSearch(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid saved search id"})
+	}
+
+	var req savedSearchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	in := &entities.SavedSearch{
+		Name:      req.Name,
+		Filters:   req.Filters,
+		Frequency: req.Frequency,
+		IsActive:  req.IsActive == nil || *req.IsActive,
+	}
+	s, err := h.Usecase.UpdateSavedSearch(uid.(uint), uint(id), in)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(s)
+}
+
+// DELETE /users/me/saved-searches/:id
+func (h *SavedSearchHandler) DeleteSavedSearch(c *fiber.Ctx) error {
+	uid := c.Locals("user_id")
+	if uid == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid saved search id"})
+	}
+
+	if err := h.Usecase.DeleteSavedSearch(uid.(uint), uint(id)); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "ลบการค้นหาที่บันทึกไว้เรียบร้อย"})
+}
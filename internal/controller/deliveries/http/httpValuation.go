@@ -0,0 +1,51 @@
+package http
+
+import (
+	"Backend_Go/internal/usecases/valuation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ValuationHandler struct {
+	Usecase *valuation.ValuationUsecase
+}
+
+// GET /valuation?brand=&model=&year=&mileage=
+func (h *ValuationHandler) Estimate(c *fiber.Ctx) error {
+	q := valuation.Query{
+		Brand:     c.Query("brand"),
+		ModelName: c.Query("model"),
+	}
+	year, err := strconv.Atoi(c.Query("year"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "year must be a number"})
+	}
+	q.Year = year
+	if raw := c.Query("mileage"); raw != "" {
+		mileage, err := strconv.Atoi(raw)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "mileage must be a number"})
+		}
+		q.Mileage = &mileage
+	}
+
+	v, err := h.Usecase.Estimate(q)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(v)
+}
+
+// GET /cars/:id/valuation
+func (h *ValuationHandler) EstimateCar(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid car id"})
+	}
+	v, err := h.Usecase.EstimateCar(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(v)
+}
@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Appointment statuses. Pending and confirmed appointments hold their slot.
+const (
+	AppointmentPending   = "pending"
+	AppointmentConfirmed = "confirmed"
+	AppointmentCancelled = "cancelled"
+	AppointmentCompleted = "completed"
+)
+
+// ActiveAppointmentStatuses are the statuses that block a slot
+var ActiveAppointmentStatuses = []string{AppointmentPending, AppointmentConfirmed}
+
+// Appointment types
+const (
+	AppointmentTestDrive = "test_drive"
+	AppointmentShowroom  = "showroom_visit"
+)
+
+// DealerAvailability is a weekly opening window split into bookable slots.
+// Times are "HH:MM" in the marketplace time zone.
+type DealerAvailability struct {
+	gorm.Model
+	DealerID    uint   `gorm:"index" json:"dealer_id"`
+	Weekday     int    `json:"weekday"` // 0 = Sunday
+	OpenTime    string `gorm:"type:varchar(5)" json:"open_time"`
+	CloseTime   string `gorm:"type:varchar(5)" json:"close_time"`
+	SlotMinutes int    `gorm:"default:60" json:"slot_minutes"`
+	Capacity    int    `gorm:"default:1" json:"capacity"` // appointments the dealer can take per slot
+}
+
+// DealerBlackout is a day on which the dealer takes no appointments
+type DealerBlackout struct {
+	gorm.Model
+	DealerID uint   `gorm:"index" json:"dealer_id"`
+	Date     string `gorm:"type:date;index" json:"date"` // YYYY-MM-DD
+	Reason   string `json:"reason"`
+}
+
+// Appointment is a customer's test drive or showroom visit for a car
+type Appointment struct {
+	gorm.Model
+	CarID        uint       `gorm:"index" json:"car_id"`
+	DealerID     uint       `gorm:"index" json:"dealer_id"`
+	CustomerID   uint       `gorm:"index" json:"customer_id"`
+	LeadID       *uint      `gorm:"index" json:"lead_id,omitempty"`
+	Type         string     `gorm:"type:varchar(20);default:'test_drive'" json:"type"`
+	StartAt      time.Time  `gorm:"index" json:"start_at"`
+	EndAt        time.Time  `json:"end_at"`
+	Status       string     `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	Note         string     `gorm:"type:text" json:"note"`
+	CancelReason string     `gorm:"type:text" json:"cancel_reason,omitempty"`
+	CancelledBy  string     `gorm:"type:varchar(20)" json:"cancelled_by,omitempty"` // customer, dealer
+	RemindedAt   *time.Time `json:"-"`
+
+	Car      Car  `gorm:"foreignKey:CarID" json:"car"`
+	Customer User `gorm:"foreignKey:CustomerID" json:"-"`
+	// Contact details for the dealer, set in AfterFind when Customer is preloaded
+	CustomerName  string `gorm:"-" json:"customer_name,omitempty"`
+	CustomerPhone string `gorm:"-" json:"customer_phone,omitempty"`
+}
+
+// AfterFind copies the preloaded customer's contact details
+func (a *Appointment) AfterFind(tx *gorm.DB) error {
+	if a.Customer.ID != 0 {
+		a.CustomerName, a.CustomerPhone = a.Customer.Name, a.Customer.Phone
+	}
+	return nil
+}
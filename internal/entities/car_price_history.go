@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// CarPriceHistory records every change of Car.Price
+type CarPriceHistory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CarID     uint      `gorm:"index" json:"car_id"`
+	OldPrice  float64   `json:"old_price"`
+	NewPrice  float64   `json:"new_price"`
+	CreatedAt time.Time `json:"changed_at"`
+}
@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Car revision review states
+const (
+	RevisionPending  = "pending"
+	RevisionApproved = "approved"
+	RevisionRejected = "rejected"
+)
+
+// CarRevision holds material edits to a live car until an admin reviews them.
+// Changes maps car columns to proposed values; new photos are CarImages with RevisionID set.
+type CarRevision struct {
+	gorm.Model
+	CarID        uint                   `gorm:"index" json:"car_id"`
+	SubmittedBy  uint                   `json:"submitted_by"`
+	Changes      map[string]interface{} `gorm:"serializer:json;type:jsonb" json:"changes"`
+	Status       string                 `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	ReviewedBy   *uint                  `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time             `json:"reviewed_at,omitempty"`
+	ReviewReason string                 `gorm:"type:text" json:"review_reason,omitempty"`
+
+	Images []CarImage `gorm:"foreignKey:RevisionID" json:"images"`
+}
@@ -0,0 +1,63 @@
+package entities
+
+import "time"
+
+// Car listing statuses. Allowed transitions are enforced by usecases/car.
+const (
+	CarStatusDraft           = "draft"
+	CarStatusPending         = "pending"
+	CarStatusApproved        = "approved"
+	CarStatusRejected        = "rejected"
+	CarStatusSelling         = "selling"
+	CarStatusReserved        = "reserved"
+	CarStatusSold            = "sold"
+	CarStatusUnpublished     = "unpublished"
+	CarStatusDeleteRequested = "delete_requested"
+	CarStatusExpired         = "expired"
+)
+
+// PublicCarStatuses are the statuses shown in public listings
+var PublicCarStatuses = []string{CarStatusApproved, CarStatusSelling}
+
+// ListedCarStatuses are the statuses visible to buyers: public cars plus reserved
+// ones, which are shown but left out of "available" searches
+var ListedCarStatuses = []string{CarStatusApproved, CarStatusSelling, CarStatusReserved}
+
+// IsPublicStatus reports whether cars in status are shown in public listings
+func IsPublicStatus(status string) bool {
+	return hasStatus(PublicCarStatuses, status)
+}
+
+// IsListedStatus reports whether cars in status are visible to buyers (public or reserved)
+func IsListedStatus(status string) bool {
+	return hasStatus(ListedCarStatuses, status)
+}
+
+func hasStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublic mirrors the public listing query: a listed status, not hidden,
+// not expired and not scheduled for later
+func (c *Car) IsPublic(now time.Time) bool {
+	return IsListedStatus(c.Status) && !c.IsHidden &&
+		(c.ExpiresAt == nil || c.ExpiresAt.After(now)) &&
+		(c.PublishAt == nil || !c.PublishAt.After(now))
+}
+
+// CarStatusHistory records every status transition of a car
+type CarStatusHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CarID      uint      `gorm:"index" json:"car_id"`
+	FromStatus string    `gorm:"type:varchar(20)" json:"from_status"`
+	ToStatus   string    `gorm:"type:varchar(20)" json:"to_status"`
+	ActorID    uint      `json:"actor_id"`
+	ActorRole  string    `gorm:"type:varchar(20)" json:"actor_role"` // dealer, admin, system
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCarIsPublic(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		car  Car
+		want bool
+	}{
+		{"approved", Car{Status: CarStatusApproved}, true},
+		{"reserved is still listed", Car{Status: CarStatusReserved}, true},
+		{"draft", Car{Status: CarStatusDraft}, false},
+		{"pending", Car{Status: CarStatusPending}, false},
+		{"rejected", Car{Status: CarStatusRejected}, false},
+		{"sold", Car{Status: CarStatusSold}, false},
+		{"hidden", Car{Status: CarStatusSelling, IsHidden: true}, false},
+		{"expired", Car{Status: CarStatusSelling, ExpiresAt: &past}, false},
+		{"not yet expired", Car{Status: CarStatusSelling, ExpiresAt: &future}, true},
+		{"scheduled for later", Car{Status: CarStatusApproved, PublishAt: &future}, false},
+		{"publish time passed", Car{Status: CarStatusApproved, PublishAt: &past}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.car.IsPublic(now); got != tt.want {
+			t.Errorf("%s: IsPublic = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStatusHelpers(t *testing.T) {
+	tests := []struct {
+		status         string
+		public, listed bool
+	}{
+		{CarStatusApproved, true, true},
+		{CarStatusSelling, true, true},
+		{CarStatusReserved, false, true},
+		{CarStatusSold, false, false},
+		{CarStatusDraft, false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicStatus(tt.status); got != tt.public {
+			t.Errorf("IsPublicStatus(%q) = %v, want %v", tt.status, got, tt.public)
+		}
+		if got := IsListedStatus(tt.status); got != tt.listed {
+			t.Errorf("IsListedStatus(%q) = %v, want %v", tt.status, got, tt.listed)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// CarView is one deduplicated listing view. Rows are only needed for the
+// dedupe window and are pruned afterwards; CarDailyStat keeps the totals.
+type CarView struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CarID     uint      `gorm:"index:idx_car_views_dedupe,priority:1" json:"car_id"`
+	ViewerKey string    `gorm:"type:varchar(64);index:idx_car_views_dedupe,priority:2" json:"-"` // u:<user id>, d:<device hash> or a:<ip+agent hash>
+	UserID    *uint     `json:"user_id,omitempty"`
+	CreatedAt time.Time `gorm:"index:idx_car_views_dedupe,priority:3" json:"created_at"`
+}
+
+// CarDailyStat is the per-car, per-day rollup of views
+type CarDailyStat struct {
+	CarID uint      `gorm:"primaryKey;autoIncrement:false" json:"car_id"`
+	Day   time.Time `gorm:"primaryKey;type:date" json:"day"`
+	Views int       `gorm:"not null;default:0" json:"views"`
+}
@@ -0,0 +1,41 @@
+package entities
+
+import "gorm.io/gorm"
+
+// CatalogBrand is a vehicle make in the master catalog. Cars are mapped to it by
+// name or by one of its aliases (other spellings, e.g. "โตโยต้า" for Toyota).
+type CatalogBrand struct {
+	gorm.Model
+	Name    string         `gorm:"type:varchar(100);index" json:"name"`
+	Aliases []string       `gorm:"serializer:json;type:jsonb" json:"aliases"`
+	Models  []CatalogModel `gorm:"foreignKey:BrandID" json:"models,omitempty"`
+}
+
+// CatalogModel is a model of a catalog brand
+type CatalogModel struct {
+	gorm.Model
+	BrandID uint          `gorm:"index" json:"brand_id"`
+	Name    string        `gorm:"type:varchar(100)" json:"name"`
+	Aliases []string      `gorm:"serializer:json;type:jsonb" json:"aliases"`
+	CarType string        `gorm:"type:varchar(50)" json:"car_type"` // default body type
+	Trims   []CatalogTrim `gorm:"foreignKey:ModelID" json:"trims,omitempty"`
+}
+
+// CatalogTrim is a generation or trim of a model, sold over a range of years,
+// with the default specs filled into cars that leave them empty
+type CatalogTrim struct {
+	gorm.Model
+	ModelID      uint   `gorm:"index" json:"model_id"`
+	Name         string `gorm:"type:varchar(100)" json:"name"`
+	YearFrom     int    `json:"year_from"`
+	YearTo       int    `json:"year_to"` // 0 while still on sale
+	FuelType     string `gorm:"type:varchar(50)" json:"fuel_type"`
+	Transmission string `gorm:"type:varchar(50)" json:"transmission"`
+	EngineCC     int    `json:"engine_cc"`
+	Seats        int    `json:"seats"`
+}
+
+// Covers reports whether the trim was sold in the given model year
+func (t *CatalogTrim) Covers(year int) bool {
+	return year >= t.YearFrom && (t.YearTo == 0 || year <= t.YearTo)
+}
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FinanceOffer is a dealer's hire-purchase offer, for all of the dealer's cars
+// or (with CarID) for one car. Rates are flat yearly rates by term.
+type FinanceOffer struct {
+	gorm.Model
+	DealerID       uint          `gorm:"index" json:"dealer_id"`
+	CarID          *uint         `gorm:"index" json:"car_id,omitempty"`
+	Name           string        `json:"name"` // e.g. finance company or campaign
+	MinDownPercent float64       `json:"min_down_percent"`
+	Rates          []FinanceRate `gorm:"serializer:json;type:jsonb" json:"rates"`
+	ValidUntil     *time.Time    `json:"valid_until,omitempty"`
+	IsActive       bool          `gorm:"default:true" json:"is_active"`
+}
+
+type FinanceRate struct {
+	TermMonths  int     `json:"term_months"`
+	RatePercent float64 `json:"rate_percent"` // flat rate per year
+}
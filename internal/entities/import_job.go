@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Inventory import job states
+const (
+	ImportQueued    = "queued"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// ImportRowError is one problem found in an uploaded inventory row (Row is 1-based, header = row 1)
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportJob tracks a dealer's bulk CSV/XLSX inventory upload
+type ImportJob struct {
+	gorm.Model
+	DealerID      uint              `gorm:"index" json:"dealer_id"`
+	UserID        uint              `json:"user_id"`
+	FileName      string            `json:"file_name"`
+	Format        string            `gorm:"type:varchar(10)" json:"format"` // csv, xlsx
+	DryRun        bool              `json:"dry_run"`
+	Mapping       map[string]string `gorm:"serializer:json;type:jsonb" json:"mapping"` // car field -> column header
+	Status        string            `gorm:"type:varchar(20);default:'queued'" json:"status"`
+	Message       string            `json:"message,omitempty"`
+	TotalRows     int               `json:"total_rows"`
+	ProcessedRows int               `json:"processed_rows"`
+	ValidRows     int               `json:"valid_rows"`
+	CreatedCount  int               `json:"created_count"`
+	Errors        []ImportRowError  `gorm:"serializer:json;type:jsonb" json:"errors"`
+	StartedAt     *time.Time        `json:"started_at,omitempty"`
+	FinishedAt    *time.Time        `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,14 @@
+package entities
+
+import "gorm.io/gorm"
+
+// Notification is an in-app message for a user (also pushed over websocket)
+type Notification struct {
+	gorm.Model
+	UserID uint   `gorm:"index" json:"user_id"`
+	Type   string `gorm:"type:varchar(40)" json:"type"` // saved_search, ...
+	Title  string `json:"title"`
+	Body   string `gorm:"type:text" json:"body"`
+	CarID  *uint  `json:"car_id,omitempty"`
+	IsRead bool   `gorm:"default:false;index" json:"is_read"`
+}
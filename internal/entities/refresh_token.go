@@ -1,12 +1,12 @@
-package entities
-
-import "time"
-
-type RefreshToken struct {
-	ID        uint `gorm:"primaryKey"`
-	UserID    uint
-	Token     string `gorm:"unique"`
-	Revoked   bool
-	ExpiresAt time.Time
-	CreatedAt time.Time
-}
+package entities
+
+import "time"
+
+type RefreshToken struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint
+	Token     string `gorm:"unique"`
+	Revoked   bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
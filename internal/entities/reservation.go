@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reservation statuses. An accepted reservation holds the car (status reserved)
+// until it expires, the dealer releases it or the car is sold.
+const (
+	ReservationRequested = "requested"
+	ReservationAccepted  = "accepted"
+	ReservationRejected  = "rejected"
+	ReservationCancelled = "cancelled"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+	ReservationCompleted = "completed"
+)
+
+// OpenReservationStatuses are the statuses a customer can have only one of per car
+var OpenReservationStatuses = []string{ReservationRequested, ReservationAccepted}
+
+// Reservation is a customer's request to hold a car against a deposit
+type Reservation struct {
+	gorm.Model
+	CarID         uint       `gorm:"index" json:"car_id"`
+	DealerID      uint       `gorm:"index" json:"dealer_id"`
+	CustomerID    uint       `gorm:"index" json:"customer_id"`
+	LeadID        *uint      `gorm:"index" json:"lead_id,omitempty"`
+	Status        string     `gorm:"type:varchar(20);default:'requested';index" json:"status"`
+	Note          string     `gorm:"type:text" json:"note"`
+	DepositAmount float64    `json:"deposit_amount"` // set by the dealer on accept
+	AcceptedAt    *time.Time `json:"accepted_at,omitempty"`
+	ExpiresAt     *time.Time `gorm:"index" json:"expires_at,omitempty"` // end of the hold, set on accept
+
+	Car      Car                `gorm:"foreignKey:CarID" json:"car"`
+	Customer User               `gorm:"foreignKey:CustomerID" json:"-"`
+	Events   []ReservationEvent `gorm:"foreignKey:ReservationID" json:"events,omitempty"`
+	// Contact details for the dealer, set in AfterFind when Customer is preloaded
+	CustomerName  string `gorm:"-" json:"customer_name,omitempty"`
+	CustomerPhone string `gorm:"-" json:"customer_phone,omitempty"`
+}
+
+// AfterFind copies the preloaded customer's contact details
+func (r *Reservation) AfterFind(tx *gorm.DB) error {
+	if r.Customer.ID != 0 {
+		r.CustomerName, r.CustomerPhone = r.Customer.Name, r.Customer.Phone
+	}
+	return nil
+}
+
+// ReservationEvent records every status change of a reservation (audit trail)
+type ReservationEvent struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ReservationID uint      `gorm:"index" json:"reservation_id"`
+	FromStatus    string    `gorm:"type:varchar(20)" json:"from_status"`
+	ToStatus      string    `gorm:"type:varchar(20)" json:"to_status"`
+	ActorID       uint      `json:"actor_id"`
+	ActorRole     string    `gorm:"type:varchar(20)" json:"actor_role"` // customer, dealer, system
+	Reason        string    `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Lead sources of a sale
+const (
+	SaleSourceCall        = "call"
+	SaleSourceLine        = "line"
+	SaleSourceChat        = "chat"
+	SaleSourceAppointment = "appointment"
+	SaleSourceReservation = "reservation"
+	SaleSourceWalkIn      = "walk_in"
+	SaleSourceOther       = "other"
+)
+
+// SaleSources lists the accepted lead sources
+var SaleSources = []string{
+	SaleSourceCall, SaleSourceLine, SaleSourceChat, SaleSourceAppointment,
+	SaleSourceReservation, SaleSourceWalkIn, SaleSourceOther,
+}
+
+// Sale records a car marked sold. Undoing the sale soft-deletes it.
+type Sale struct {
+	gorm.Model
+	CarID         uint      `gorm:"index" json:"car_id"`
+	DealerID      uint      `gorm:"index" json:"dealer_id"`
+	BuyerID       *uint     `gorm:"index" json:"buyer_id,omitempty"`
+	LeadID        *uint     `json:"lead_id,omitempty"`
+	ReservationID *uint     `json:"reservation_id,omitempty"`
+	FinalPrice    float64   `json:"final_price"`
+	ListPrice     float64   `json:"list_price"` // asking price when sold
+	SoldAt        time.Time `gorm:"index" json:"sold_at"`
+	LeadSource    string    `gorm:"type:varchar(20)" json:"lead_source,omitempty"`
+	Note          string    `gorm:"type:text" json:"note,omitempty"`
+	// Days on market, from the first approval (or creation) to SoldAt
+	ListedAt     time.Time `json:"listed_at"`
+	DaysOnMarket int       `json:"days_on_market"`
+	RecordedBy   uint      `json:"recorded_by"`
+
+	Car Car `gorm:"foreignKey:CarID" json:"car"`
+}
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedSearchFilter is the subset of the public car filters a user can save
+type SavedSearchFilter struct {
+	Query        string   `json:"q,omitempty"`
+	Brands       []string `json:"brands,omitempty"`
+	ModelName    string   `json:"model,omitempty"`
+	YearMin      int      `json:"year_min,omitempty"`
+	YearMax      int      `json:"year_max,omitempty"`
+	PriceMin     float64  `json:"price_min,omitempty"`
+	PriceMax     float64  `json:"price_max,omitempty"`
+	MileageMax   int      `json:"mileage_max,omitempty"`
+	FuelTypes    []string `json:"fuel_types,omitempty"`
+	Transmission []string `json:"transmission,omitempty"`
+	CarTypes     []string `json:"car_types,omitempty"`
+	Colors       []string `json:"colors,omitempty"`
+	Province     string   `json:"province,omitempty"`
+}
+
+type SavedSearch struct {
+	gorm.Model
+	UserID         uint              `gorm:"index" json:"user_id"`
+	Name           string            `json:"name"`
+	Filters        SavedSearchFilter `gorm:"serializer:json;type:jsonb" json:"filters"`
+	Frequency      string            `gorm:"type:varchar(20);default:'instant'" json:"frequency"` // instant, daily
+	IsActive       bool              `gorm:"default:true" json:"is_active"`
+	LastNotifiedAt *time.Time        `json:"last_notified_at"`
+}
+
+// SavedSearchMatch is a newly approved car matching a saved search.
+// NotifiedAt stays nil until the match is delivered (instantly or in the daily digest).
+type SavedSearchMatch struct {
+	gorm.Model
+	SavedSearchID uint       `gorm:"uniqueIndex:idx_saved_search_car" json:"saved_search_id"`
+	CarID         uint       `gorm:"uniqueIndex:idx_saved_search_car" json:"car_id"`
+	UserID        uint       `gorm:"index" json:"user_id"`
+	NotifiedAt    *time.Time `gorm:"index" json:"notified_at"`
+}
@@ -0,0 +1,227 @@
+// Package media turns uploaded car photos into resized web renditions.
+//
+// Decoding applies the EXIF orientation and re-encoding drops all metadata
+// (GPS position, camera details), so no original upload is ever served.
+// JPEG is encoded in pure Go; WebP is encoded with the cwebp tool (CWEBP_PATH
+// or on PATH), which is required: NewProcessor fails without it. HEIC uploads
+// are decoded with libheif's heif-convert (HEIF_CONVERT_PATH) when available.
+// Both tools are killed after IMAGE_TOOL_TIMEOUT_SECONDS (default 30).
+package media
+
+import (
+	"Backend_Go/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp" // accept WebP uploads; imaging registers the other formats
+)
+
+// Rendition is a named size a photo is scaled down to fit in (never up)
+type Rendition struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// Renditions generated for every photo, smallest first
+var Renditions = []Rendition{
+	{Name: "thumb", MaxWidth: 320, MaxHeight: 240},
+	{Name: "medium", MaxWidth: 800, MaxHeight: 600},
+	{Name: "large", MaxWidth: 1600, MaxHeight: 1200},
+}
+
+// ErrTooLarge is returned for images with more pixels than IMAGE_MAX_PIXELS
+var ErrTooLarge = fmt.Errorf("%w: image dimensions are too large", ErrInvalidImage)
+
+// Output is one generated rendition; JPEG and WebP are file names in the output directory
+type Output struct {
+	Name   string
+	Width  int
+	Height int
+	JPEG   string
+	WebP   string
+}
+
+// Processor generates renditions, running at most IMAGE_WORKERS jobs at a time
+type Processor struct {
+	slots       chan struct{}
+	jpegQuality int
+	webpQuality int
+	maxPixels   int
+	toolTimeout time.Duration
+	cwebp       string
+	heifConvert string
+}
+
+// NewProcessor reads the image settings from the environment. It fails when the
+// cwebp encoder is missing, since every rendition must also be served as WebP.
+func NewProcessor() (*Processor, error) {
+	workers := envInt("IMAGE_WORKERS", runtime.NumCPU()/2)
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Processor{
+		slots:       make(chan struct{}, workers),
+		jpegQuality: envInt("IMAGE_JPEG_QUALITY", 82),
+		webpQuality: envInt("IMAGE_WEBP_QUALITY", 80),
+		maxPixels:   envInt("IMAGE_MAX_PIXELS", 50_000_000),
+		toolTimeout: time.Duration(envInt("IMAGE_TOOL_TIMEOUT_SECONDS", 30)) * time.Second,
+	}
+	cwebp, err := exec.LookPath(utils.GetEnv("CWEBP_PATH", "cwebp"))
+	if err != nil {
+		return nil, fmt.Errorf("media: WebP encoder not found (install cwebp or set CWEBP_PATH): %w", err)
+	}
+	p.cwebp = cwebp
+	if path, err := exec.LookPath(utils.GetEnv("HEIF_CONVERT_PATH", "heif-convert")); err == nil {
+		p.heifConvert = path
+	} else {
+		log.Println("media: heif-convert not found, HEIC uploads are rejected")
+	}
+	return p, nil
+}
+
+// Process validates and decodes an uploaded photo and writes its renditions
+// to dir as base_<rendition>.jpg (and .webp). Files of a failed run are removed.
+// Rejected uploads return an error wrapping ErrInvalidImage.
+func (p *Processor) Process(data []byte, dir, base string) (outputs []Output, err error) {
+	format, data, err := Validate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p.slots <- struct{}{}
+	defer func() { <-p.slots }()
+
+	if format == FormatHEIC {
+		if p.heifConvert == "" {
+			return nil, ErrHEICUnavailable
+		}
+		if data, err = p.convertHEIC(data); err != nil {
+			return nil, err
+		}
+		format = FormatPNG
+	}
+
+	// Check the size before decoding so a small file cannot claim gigabytes of memory
+	cfg, decoded, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: corrupt %s file", ErrInvalidImage, format)
+	}
+	if decoded != format {
+		return nil, ErrFormatMismatch
+	}
+	if cfg.Width*cfg.Height > p.maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("%w: corrupt %s file", ErrInvalidImage, format)
+	}
+
+	var written []string
+	defer func() {
+		if err != nil {
+			for _, f := range written {
+				os.Remove(filepath.Join(dir, f))
+			}
+		}
+	}()
+
+	for _, r := range Renditions {
+		scaled := imaging.Fit(img, r.MaxWidth, r.MaxHeight, imaging.Lanczos)
+		out := Output{Name: r.Name, Width: scaled.Bounds().Dx(), Height: scaled.Bounds().Dy()}
+
+		out.JPEG = fmt.Sprintf("%s_%s.jpg", base, r.Name)
+		if err = imaging.Save(scaled, filepath.Join(dir, out.JPEG), imaging.JPEGQuality(p.jpegQuality)); err != nil {
+			return nil, err
+		}
+		written = append(written, out.JPEG)
+
+		out.WebP = fmt.Sprintf("%s_%s.webp", base, r.Name)
+		if err = p.encodeWebP(scaled, filepath.Join(dir, out.WebP)); err != nil {
+			return nil, err
+		}
+		written = append(written, out.WebP)
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+// encodeWebP converts through a lossless PNG so the photo is compressed only once
+func (p *Processor) encodeWebP(img image.Image, dst string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".webp-src-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = imaging.Encode(tmp, img, imaging.PNG, imaging.PNGCompressionLevel(png.NoCompression))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if out, err := p.run(p.cwebp, "-quiet", "-metadata", "none", "-q", strconv.Itoa(p.webpQuality), tmp.Name(), "-o", dst); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("cwebp: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// convertHEIC decodes a HEIC photo to PNG, applying its rotation
+func (p *Processor) convertHEIC(data []byte) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "heic-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	src, dst := filepath.Join(dir, "src.heic"), filepath.Join(dir, "out.png")
+	if err := os.WriteFile(src, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	if out, err := p.run(p.heifConvert, src, dst); err != nil {
+		log.Printf("heif-convert: %v: %s", err, bytes.TrimSpace(out))
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("heif-convert: %w", err)
+		}
+		return nil, fmt.Errorf("%w: corrupt heic file", ErrInvalidImage)
+	}
+	return os.ReadFile(dst)
+}
+
+// run executes an external tool, killing it after toolTimeout so a stuck
+// encoder cannot hold a worker slot forever
+func (p *Processor) run(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.toolTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	// Children of a killed tool may keep the output pipe open; stop waiting for them
+	cmd.WaitDelay = time.Second
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return out, fmt.Errorf("timed out after %v: %w", p.toolTimeout, ctx.Err())
+	}
+	return out, err
+}
+
+func envInt(key string, fallback int) int {
+	n, err := strconv.Atoi(utils.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,100 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewProcessorRequiresWebPEncoder(t *testing.T) {
+	t.Setenv("CWEBP_PATH", filepath.Join(t.TempDir(), "missing-cwebp"))
+	if _, err := NewProcessor(); err == nil {
+		t.Fatal("NewProcessor succeeded without a WebP encoder")
+	}
+}
+
+// fakeCWebP installs a stand-in for cwebp that copies its input to the output file
+func fakeCWebP(t *testing.T) {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "cwebp")
+	// cwebp -quiet -metadata none -q <quality> <src> -o <dst>
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncp \"$6\" \"$8\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CWEBP_PATH", script)
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, x%h, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessWritesJPEGAndWebP(t *testing.T) {
+	fakeCWebP(t)
+	p, err := NewProcessor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	outputs, err := p.Process(testJPEG(t, 2000, 1000), dir, "photo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != len(Renditions) {
+		t.Fatalf("%d renditions, want %d", len(outputs), len(Renditions))
+	}
+	for i, out := range outputs {
+		r := Renditions[i]
+		if out.Width > r.MaxWidth || out.Height > r.MaxHeight {
+			t.Errorf("%s is %dx%d, larger than %dx%d", out.Name, out.Width, out.Height, r.MaxWidth, r.MaxHeight)
+		}
+		if out.JPEG == "" || out.WebP == "" {
+			t.Errorf("%s is missing a format: %+v", out.Name, out)
+			continue
+		}
+		for _, f := range []string{out.JPEG, out.WebP} {
+			if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+				t.Errorf("%s: %v", f, err)
+			}
+		}
+	}
+}
+
+func TestProcessKillsStuckEncoder(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "cwebp")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 30\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CWEBP_PATH", script)
+	t.Setenv("IMAGE_TOOL_TIMEOUT_SECONDS", "1")
+	p, err := NewProcessor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	start := time.Now()
+	if _, err := p.Process(testJPEG(t, 400, 300), dir, "photo"); err == nil {
+		t.Fatal("Process succeeded with a stuck encoder")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Process took %v, the encoder was not killed", elapsed)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("files of the failed run were left behind: %v", files)
+	}
+}
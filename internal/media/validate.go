@@ -0,0 +1,140 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Upload formats, detected from the file content rather than its name
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+	FormatHEIC = "heic"
+)
+
+// ErrInvalidImage wraps every reason an upload is rejected for its content
+var ErrInvalidImage = errors.New("invalid image")
+
+var (
+	ErrUnsupportedType = fmt.Errorf("%w: only JPEG, PNG, WebP and HEIC files are accepted", ErrInvalidImage)
+	ErrHEICUnavailable = fmt.Errorf("%w: HEIC files are not supported on this server, please upload JPEG", ErrInvalidImage)
+	ErrTrailingData    = fmt.Errorf("%w: file has missing or extra data after the image", ErrInvalidImage)
+	ErrFormatMismatch  = fmt.Errorf("%w: file content does not match its image type", ErrInvalidImage)
+)
+
+var (
+	heifBrands   = []string{"heic", "heix", "heim", "heis", "hevc", "hevx", "mif1", "msf1"}
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	pngEnd       = []byte("IEND\xae\x42\x60\x82")
+)
+
+// UploadLimits bound what one upload request may carry
+type UploadLimits struct {
+	MaxFileBytes    int64 // per file, IMAGE_MAX_FILE_MB (default 10)
+	MaxRequestBytes int64 // all files of a request, IMAGE_MAX_REQUEST_MB (default 50)
+	MaxImagesPerCar int   // IMAGE_MAX_PER_CAR (default 20)
+}
+
+// LoadUploadLimits reads the upload limits from the environment
+func LoadUploadLimits() UploadLimits {
+	return UploadLimits{
+		MaxFileBytes:    int64(envInt("IMAGE_MAX_FILE_MB", 10)) << 20,
+		MaxRequestBytes: int64(envInt("IMAGE_MAX_REQUEST_MB", 50)) << 20,
+		MaxImagesPerCar: envInt("IMAGE_MAX_PER_CAR", 20),
+	}
+}
+
+// Sniff detects the format of an upload from its magic bytes
+func Sniff(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return FormatJPEG, nil
+	case bytes.HasPrefix(data, pngSignature):
+		return FormatPNG, nil
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return FormatWebP, nil
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		brand := string(data[8:12])
+		for _, b := range heifBrands {
+			if brand == b {
+				return FormatHEIC, nil
+			}
+		}
+	}
+	return "", ErrUnsupportedType
+}
+
+// Validate rejects uploads that are not a plain image of an accepted format:
+// unknown magic bytes, or PNG and WebP files carrying a second payload after
+// the image. It returns the image data to decode. JPEG files often carry a
+// trailer after the end-of-image marker (motion photo video, Samsung SEFT
+// data, zero padding), which is cut off instead. Process checks that the image
+// itself decodes; only the re-encoded renditions are ever served.
+func Validate(data []byte) (string, []byte, error) {
+	format, err := Sniff(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch format {
+	case FormatJPEG:
+		end, ok := jpegImageEnd(data)
+		if !ok {
+			return "", nil, ErrTrailingData
+		}
+		data = data[:end]
+	case FormatPNG:
+		if !bytes.HasSuffix(data, pngEnd) {
+			return "", nil, ErrTrailingData
+		}
+	case FormatWebP:
+		// The RIFF header holds the size of everything after its first 8 bytes (plus a pad byte)
+		size, rest := int64(binary.LittleEndian.Uint32(data[4:8])), int64(len(data)-8)
+		if rest < size || rest > size+1 {
+			return "", nil, ErrTrailingData
+		}
+	}
+	return format, data, nil
+}
+
+// jpegImageEnd walks the JPEG segments and returns the offset just past the
+// end-of-image marker. Segment lengths are followed, so an EXIF thumbnail's
+// own marker does not end the image early; in scan data 0xFF is always
+// followed by 0x00 or a restart marker until the next segment.
+func jpegImageEnd(data []byte) (int, bool) {
+	i := 2 // after SOI
+	for i+1 < len(data) {
+		if data[i] != 0xFF {
+			return 0, false
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF: // fill byte
+			i++
+			continue
+		case marker == 0xD9: // EOI
+			return i + 2, true
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // no length
+			i += 2
+			continue
+		}
+		if i+4 > len(data) {
+			return 0, false
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 {
+			return 0, false
+		}
+		i += 2 + length
+		if marker != 0xDA { // SOS: the entropy-coded scan follows its header
+			continue
+		}
+		for i+1 < len(data) && (data[i] != 0xFF || data[i+1] == 0x00 || (data[i+1] >= 0xD0 && data[i+1] <= 0xD7)) {
+			i++
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testWebP(payload []byte) []byte {
+	data := append([]byte("RIFF\x00\x00\x00\x00WEBP"), payload...)
+	binary.LittleEndian.PutUint32(data[4:8], uint32(len(data)-8))
+	return data
+}
+
+func TestValidate(t *testing.T) {
+	jpg := testJPEG(t, 16, 16)
+	pngData := testPNG(t)
+	webp := testWebP([]byte("VP8 \x04\x00\x00\x00abcd"))
+	concat := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+	// A motion photo: the video follows the still image
+	motion := concat(jpg, []byte("\x00\x00\x00\x18ftypmp42"), bytes.Repeat([]byte{0xFF, 0xD9, 0x42}, 8))
+	// An EXIF thumbnail ends with its own EOI inside the APP1 segment
+	markup := concat(jpg[:len(jpg)-2], []byte("<svg<html"), []byte{0xFF, 0xD9})
+	thumb := concat(jpg[:2], []byte{0xFF, 0xE1, 0x00, 0x08, 'E', 'x', 'i', 'f', 0xFF, 0xD9}, jpg[2:])
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		image  []byte
+		err    error
+	}{
+		{"jpeg", jpg, FormatJPEG, jpg, nil},
+		{"jpeg with zero padding", concat(jpg, make([]byte, 64)), FormatJPEG, jpg, nil},
+		{"jpeg with a payload after the image", concat(jpg, []byte("<?php echo 1; ?>")), FormatJPEG, jpg, nil},
+		{"jpeg motion photo", motion, FormatJPEG, jpg, nil},
+		{"jpeg with an EXIF thumbnail", thumb, FormatJPEG, thumb, nil},
+		{"truncated jpeg", jpg[:len(jpg)-2], "", nil, ErrTrailingData},
+		{"png", pngData, FormatPNG, pngData, nil},
+		{"png with a payload after the image", concat(pngData, []byte("PK\x03\x04")), "", nil, ErrTrailingData},
+		{"webp", webp, FormatWebP, webp, nil},
+		{"webp with a payload after the image", concat(webp, []byte("extra")), "", nil, ErrTrailingData},
+		{"markup bytes inside the image are fine", markup, FormatJPEG, markup, nil},
+		{"unknown type", []byte("GIF89a......"), "", nil, ErrUnsupportedType},
+		{"empty", nil, "", nil, ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		format, img, err := Validate(tt.data)
+		if format != tt.format || !errors.Is(err, tt.err) {
+			t.Errorf("%s: Validate = %q, %v; want %q, %v", tt.name, format, err, tt.format, tt.err)
+		}
+		if !bytes.Equal(img, tt.image) {
+			t.Errorf("%s: Validate kept %d bytes, want %d", tt.name, len(img), len(tt.image))
+		}
+		if err != nil && !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("%s: %v does not wrap ErrInvalidImage", tt.name, err)
+		}
+	}
+}
+
+func TestSniffHEIC(t *testing.T) {
+	data := []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00")
+	if format, err := Sniff(data); err != nil || format != FormatHEIC {
+		t.Errorf("Sniff = %q, %v; want heic", format, err)
+	}
+}
@@ -1,76 +1,79 @@
-package middleware
-
-import (
-	"strings"
-
-	"github.com/gofiber/fiber/v2"
-	jwt "github.com/golang-jwt/jwt/v4"
-)
-
-// RequireRole returns a Fiber middleware that validates JWT and checks the role claim.
-func RequireRole(role string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		auth := c.Get("Authorization")
-		if auth == "" {
-			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
-		}
-		parts := strings.SplitN(auth, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
-		}
-		tokenStr := parts[1]
-
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-		if err != nil || !token.Valid {
-			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
-		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
-		}
-		r, _ := claims["role"].(string)
-		idf, _ := claims["user_id"].(float64)
-
-		if r != role {
-			return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
-		}
-
-		// store in locals for handlers
-		c.Locals("user_id", uint(idf))
-		c.Locals("role", r)
-		return c.Next()
-	}
-}
-
-// RequireAuth validates JWT and sets user info, without checking role.
-func RequireAuth() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		auth := c.Get("Authorization")
-		if auth == "" {
-			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
-		}
-		parts := strings.SplitN(auth, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
-		}
-		tokenStr := parts[1]
-
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-		if err != nil || !token.Valid {
-			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
-		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
-		}
-		idf, _ := claims["user_id"].(float64)
-		r, _ := claims["role"].(string)
-		c.Locals("user_id", uint(idf))
-		c.Locals("role", r)
-		return c.Next()
-	}
-}
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+var errNoToken = errors.New("missing bearer token")
+
+// parseToken reads the bearer JWT of the request and returns its user id and role.
+// Only tokens signed with our HMAC secret are accepted.
+func parseToken(c *fiber.Ctx) (uint, string, error) {
+	parts := strings.SplitN(c.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return 0, "", errNoToken
+	}
+
+	token, err := jwt.Parse(parts[1], func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return 0, "", errors.New("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, "", errors.New("invalid token claims")
+	}
+	idf, _ := claims["user_id"].(float64)
+	r, _ := claims["role"].(string)
+	return uint(idf), r, nil
+}
+
+// RequireRole returns a Fiber middleware that validates JWT and checks the role claim.
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, r, err := parseToken(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
+		}
+		if r != role {
+			return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
+		}
+
+		// store in locals for handlers
+		c.Locals("user_id", uid)
+		c.Locals("role", r)
+		return c.Next()
+	}
+}
+
+// RequireAuth validates JWT and sets user info, without checking role.
+func RequireAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, r, err := parseToken(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "session expired"})
+		}
+		c.Locals("user_id", uid)
+		c.Locals("role", r)
+		return c.Next()
+	}
+}
+
+// OptionalAuth sets user info when a valid JWT is sent; anonymous requests pass through.
+func OptionalAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if uid, r, err := parseToken(c); err == nil {
+			c.Locals("user_id", uid)
+			c.Locals("role", r)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+func authApp() *fiber.App {
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error {
+		uid, _ := c.Locals("user_id").(uint)
+		role, _ := c.Locals("role").(string)
+		return c.JSON(fiber.Map{"user_id": uid, "role": role})
+	}
+	app.Get("/admin", RequireRole("admin"), ok)
+	app.Get("/me", RequireAuth(), ok)
+	app.Get("/optional", OptionalAuth(), ok)
+	return app
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	admin, _ := GenerateToken(1, "admin")
+	user, _ := GenerateToken(2, "user")
+	expired, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 2, "role": "user", "exp": time.Now().Add(-time.Minute).Unix(),
+	}).SignedString(jwtSecret)
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"user_id": 1, "role": "admin",
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	tests := []struct {
+		path, auth string
+		want       int
+	}{
+		{"/admin", "Bearer " + admin, 200},
+		{"/admin", "Bearer " + user, 403},
+		{"/admin", "", 401},
+		{"/admin", "Bearer " + unsigned, 401},
+		{"/me", "Bearer " + user, 200},
+		{"/me", "bearer " + user, 200},
+		{"/me", user, 401},
+		{"/me", "Bearer " + expired, 401},
+		{"/optional", "", 200},
+		{"/optional", "Bearer " + expired, 200},
+		{"/optional", "Bearer " + user, 200},
+	}
+	app := authApp()
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s with %q = %d, want %d", tt.path, tt.auth, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestOptionalAuthSetsUserOnlyForValidTokens(t *testing.T) {
+	user, _ := GenerateToken(2, "user")
+	app := fiber.New()
+	app.Get("/", OptionalAuth(), func(c *fiber.Ctx) error {
+		uid, _ := c.Locals("user_id").(uint)
+		if uid != 0 {
+			return c.SendStatus(200)
+		}
+		return c.SendStatus(204)
+	})
+
+	for auth, want := range map[string]int{"Bearer " + user: 200, "Bearer not-a-jwt": 204, "": 204} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", auth)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("Authorization %q = %d, want %d", auth, resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"Backend_Go/internal/entities"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CarOwnerFinder looks up the dealer of a car (repositories.CarRepository)
+type CarOwnerFinder interface {
+	FindDealerID(carID uint) (uint, error)
+}
+
+// CarImageFinder looks up a car image (repositories.CarImageRepository)
+type CarImageFinder interface {
+	FindByID(id uint) (*entities.CarImage, error)
+}
+
+// RequireCarOwner allows the request only when the car in :id belongs to the
+// dealer set by RequireActiveDealer
+func RequireCarOwner(carRepo CarOwnerFinder) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		carID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid car_id"})
+		}
+
+		ownerID, err := carRepo.FindDealerID(uint(carID))
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "car not found"})
+		}
+
+		return requireDealer(c, ownerID)
+	}
+}
+
+// RequireCarImageOwner allows the request only when the image in :id belongs to
+// a car of the dealer set by RequireActiveDealer
+func RequireCarImageOwner(imageRepo CarImageFinder, carRepo CarOwnerFinder) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		imageID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid image_id"})
+		}
+
+		image, err := imageRepo.FindByID(uint(imageID))
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "image not found"})
+		}
+		ownerID, err := carRepo.FindDealerID(image.CarID)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "car not found"})
+		}
+
+		return requireDealer(c, ownerID)
+	}
+}
+
+func requireDealer(c *fiber.Ctx, ownerID uint) error {
+	dealerID, ok := c.Locals("dealer_id").(uint)
+	if !ok || dealerID == 0 {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Forbidden: Dealer profile not found",
+		})
+	}
+	if ownerID != dealerID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Forbidden: Car belongs to another dealer",
+		})
+	}
+	return c.Next()
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"Backend_Go/internal/entities"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	dealerA uint = 1
+	dealerB uint = 2
+)
+
+type fakeCars map[uint]uint // car id -> dealer id
+
+func (f fakeCars) FindDealerID(carID uint) (uint, error) {
+	if dealerID, ok := f[carID]; ok {
+		return dealerID, nil
+	}
+	return 0, errors.New("record not found")
+}
+
+type fakeImages map[uint]uint // image id -> car id
+
+func (f fakeImages) FindByID(id uint) (*entities.CarImage, error) {
+	if carID, ok := f[id]; ok {
+		return &entities.CarImage{CarID: carID}, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+// ownerTestApp mounts the dealer car and image routes the way routes.go does,
+// with the dealer id that RequireActiveDealer would set taken from a header
+func ownerTestApp() *fiber.App {
+	cars := fakeCars{10: dealerA, 20: dealerB}
+	images := fakeImages{100: 10, 200: 20}
+	carOwner := RequireCarOwner(cars)
+	imageOwner := RequireCarImageOwner(images, cars)
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+
+	app := fiber.New()
+	dealer := func(c *fiber.Ctx) error {
+		if id, err := strconv.Atoi(c.Get("X-Test-Dealer")); err == nil {
+			c.Locals("dealer_id", uint(id))
+		}
+		return c.Next()
+	}
+	dealerCars := app.Group("/cars", dealer)
+	dealerCars.Put("/:id", carOwner, ok)
+	dealerCars.Delete("/:id", carOwner, ok)
+	dealerCars.Patch("/:id/status", carOwner, ok)
+	dealerCars.Post("/:id/images", carOwner, ok)
+	dealerCars.Delete("/:id/images", carOwner, ok)
+
+	dealerImages := app.Group("/images", dealer)
+	dealerImages.Put("/:id", imageOwner, ok)
+	dealerImages.Delete("/:id", imageOwner, ok)
+	return app
+}
+
+func TestOwnerMiddlewareCrossDealer(t *testing.T) {
+	app := ownerTestApp()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		dealer string
+		want   int
+	}{
+		{"owner updates own car", "PUT", "/cars/10", "1", 200},
+		{"other dealer updates car", "PUT", "/cars/10", "2", 403},
+		{"other dealer deletes car", "DELETE", "/cars/10", "2", 403},
+		{"other dealer changes status", "PATCH", "/cars/10/status", "2", 403},
+		{"other dealer adds images", "POST", "/cars/10/images", "2", 403},
+		{"other dealer deletes all images", "DELETE", "/cars/10/images", "2", 403},
+		{"owner edits own image", "PUT", "/images/100", "1", 200},
+		{"other dealer edits image by id", "PUT", "/images/100", "2", 403},
+		{"other dealer deletes image by id", "DELETE", "/images/100", "2", 403},
+		{"dealer B on own image", "DELETE", "/images/200", "2", 200},
+		{"no dealer profile", "PUT", "/cars/10", "", 403},
+		{"unknown car", "PUT", "/cars/99", "1", 404},
+		{"unknown image", "PUT", "/images/999", "1", 404},
+		{"invalid car id", "PUT", "/cars/abc", "1", 400},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.dealer != "" {
+			req.Header.Set("X-Test-Dealer", tt.dealer)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: %s %s = %d, want %d", tt.name, tt.method, tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
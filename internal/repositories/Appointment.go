@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrSlotTaken is returned when an appointment would double-book a car or the dealer
+var ErrSlotTaken = errors.New("this time slot is no longer available")
+
+// ErrAppointmentChanged is returned when an appointment's status changed after it was loaded
+var ErrAppointmentChanged = errors.New("the appointment was changed in the meantime, please reload")
+
+type AppointmentRepository struct{ DB *gorm.DB }
+
+// ---------- Availability ----------
+
+func (r *AppointmentRepository) FindAvailability(dealerID uint, windows *[]*entities.DealerAvailability) error {
+	return r.DB.Where("dealer_id = ?", dealerID).Order("weekday, open_time").Find(windows).Error
+}
+
+// ReplaceAvailability swaps the dealer's weekly windows for new ones
+func (r *AppointmentRepository) ReplaceAvailability(dealerID uint, windows []*entities.DealerAvailability) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("dealer_id = ?", dealerID).Delete(&entities.DealerAvailability{}).Error; err != nil {
+			return err
+		}
+		if len(windows) == 0 {
+			return nil
+		}
+		return tx.Create(&windows).Error
+	})
+}
+
+// FindBlackouts returns the dealer's blackout days from one date to another (YYYY-MM-DD, inclusive)
+func (r *AppointmentRepository) FindBlackouts(dealerID uint, from, to string, blackouts *[]*entities.DealerBlackout) error {
+	return r.DB.Where("dealer_id = ? AND date BETWEEN ? AND ?", dealerID, from, to).Order("date").Find(blackouts).Error
+}
+
+func (r *AppointmentRepository) CreateBlackout(blackout *entities.DealerBlackout) error {
+	return r.DB.Create(blackout).Error
+}
+
+// DeleteBlackout removes one of the dealer's blackout days
+func (r *AppointmentRepository) DeleteBlackout(dealerID, id uint) error {
+	res := r.DB.Where("dealer_id = ?", dealerID).Delete(&entities.DealerBlackout{}, id)
+	if res.Error == nil && res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return res.Error
+}
+
+// ---------- Appointments ----------
+
+func (r *AppointmentRepository) FindByID(id uint, a *entities.Appointment) error {
+	return r.DB.Preload("Car").Preload("Customer").First(a, id).Error
+}
+
+// FindByDealerID returns the dealer's appointments starting in [from, to), optionally in one status
+func (r *AppointmentRepository) FindByDealerID(dealerID uint, status string, from, to time.Time, appointments *[]*entities.Appointment) error {
+	q := r.DB.Preload("Car").Preload("Customer").
+		Where("dealer_id = ? AND start_at >= ? AND start_at < ?", dealerID, from, to)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	return q.Order("start_at").Find(appointments).Error
+}
+
+func (r *AppointmentRepository) FindByCustomerID(customerID uint, appointments *[]*entities.Appointment) error {
+	return r.DB.Preload("Car").
+		Where("customer_id = ?", customerID).
+		Order("start_at DESC").
+		Find(appointments).Error
+}
+
+// FindActive returns the dealer's slot-holding appointments overlapping [from, to)
+func (r *AppointmentRepository) FindActive(dealerID uint, from, to time.Time, appointments *[]*entities.Appointment) error {
+	return r.DB.Where("dealer_id = ? AND status IN ? AND start_at < ? AND end_at > ?",
+		dealerID, entities.ActiveAppointmentStatuses, to, from).
+		Find(appointments).Error
+}
+
+// SaveIfFree creates or updates an appointment unless the car already has an
+// active appointment at that time or the dealer is booked to capacity. A
+// per-dealer advisory lock serializes concurrent bookings.
+func (r *AppointmentRepository) SaveIfFree(a *entities.Appointment, capacity int) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", fmt.Sprintf("appointment:%d", a.DealerID)).Error; err != nil {
+			return err
+		}
+
+		overlapping := func(column string, id uint) (int64, error) {
+			var count int64
+			err := tx.Model(&entities.Appointment{}).
+				Where(column+" = ? AND id <> ? AND status IN ?", id, a.ID, entities.ActiveAppointmentStatuses).
+				Where("start_at < ? AND end_at > ?", a.EndAt, a.StartAt).
+				Count(&count).Error
+			return count, err
+		}
+		carBooked, err := overlapping("car_id", a.CarID)
+		if err != nil {
+			return err
+		}
+		dealerBooked, err := overlapping("dealer_id", a.DealerID)
+		if err != nil {
+			return err
+		}
+		if carBooked > 0 || dealerBooked >= int64(capacity) {
+			return ErrSlotTaken
+		}
+
+		return tx.Omit("Car", "Customer").Save(a).Error
+	})
+}
+
+// SetLead links an appointment to the customer's lead for the car
+func (r *AppointmentRepository) SetLead(id, leadID uint) error {
+	return r.DB.Model(&entities.Appointment{}).Where("id = ?", id).UpdateColumn("lead_id", leadID).Error
+}
+
+// UpdateStatus saves a status change that frees or keeps the slot (no overlap
+// check needed). It only applies while the appointment is still in status from.
+func (r *AppointmentRepository) UpdateStatus(a *entities.Appointment, from string) error {
+	res := r.DB.Model(a).Where("status = ?", from).Select("status", "cancel_reason", "cancelled_by").Updates(a)
+	if res.Error == nil && res.RowsAffected == 0 {
+		return ErrAppointmentChanged
+	}
+	return res.Error
+}
+
+// FindDueReminders returns active appointments starting before t that have not been reminded
+func (r *AppointmentRepository) FindDueReminders(t time.Time, appointments *[]*entities.Appointment) error {
+	return r.DB.Preload("Car").Preload("Car.Dealer").
+		Where("status = ? AND start_at > NOW() AND start_at <= ? AND reminded_at IS NULL", entities.AppointmentConfirmed, t).
+		Find(appointments).Error
+}
+
+func (r *AppointmentRepository) MarkReminded(id uint, at time.Time) error {
+	return r.DB.Model(&entities.Appointment{}).Where("id = ?", id).UpdateColumn("reminded_at", at).Error
+}
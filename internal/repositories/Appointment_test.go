@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"Backend_Go/internal/entities"
+)
+
+func TestAppointmentUpdateStatusIsGuarded(t *testing.T) {
+	db := dryRunDB(t)
+	queries := captureQueries(t, db)
+	repo := &AppointmentRepository{DB: db}
+	a := &entities.Appointment{Status: entities.AppointmentConfirmed}
+	a.ID = 3
+
+	// A dry run affects no rows, like an appointment changed in the meantime
+	err := repo.UpdateStatus(a, entities.AppointmentPending)
+	if !errors.Is(err, ErrAppointmentChanged) {
+		t.Errorf("err = %v, want ErrAppointmentChanged", err)
+	}
+	want := `WHERE status = 'pending'`
+	if len(*queries) != 1 || !strings.Contains((*queries)[0], want) {
+		t.Errorf("queries = %q, want a guard %q", *queries, want)
+	}
+}
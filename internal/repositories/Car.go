@@ -1,66 +1,696 @@
-package repositories
-
-import (
-	"Backend_Go/internal/entities"
-
-	"gorm.io/gorm"
-)
-
-type CarRepository struct {
-	DB *gorm.DB
-}
-
-func (r *CarRepository) Create(car *entities.Car) error {
-	return r.DB.Create(car).Error
-}
-
-func (r *CarRepository) FindAll(cars *[]*entities.Car) error {
-	return r.DB.
-		Preload("CarImages", func(db *gorm.DB) *gorm.DB {
-			return db.Order("sort_order ASC")
-		}).
-		Preload("Dealer").
-		Preload("Dealer.User").
-		Order("created_at DESC").
-		Find(cars).Error
-}
-
-func (r *CarRepository) FindByID(id uint, car *entities.Car) error {
-	return r.DB.
-		Preload("CarImages", func(db *gorm.DB) *gorm.DB {
-			return db.Order("sort_order ASC")
-		}).
-		Preload("Dealer").
-		Preload("Dealer.User").
-		First(car, id).Error
-}
-
-func (r *CarRepository) Update(car *entities.Car) error {
-	return r.DB.Save(car).Error
-}
-
-func (r *CarRepository) Delete(id uint) error {
-	return r.DB.Delete(&entities.Car{}, id).Error
-}
-
-func (r *CarRepository) FindByDealerID(dealerID uint, cars *[]*entities.Car) error {
-	return r.DB.
-		Preload("CarImages", func(db *gorm.DB) *gorm.DB {
-			return db.Order("sort_order ASC")
-		}).
-		Preload("Dealer").
-		Preload("Dealer.User").
-		Where("dealer_id = ?", dealerID).
-		Find(cars).Error
-}
-
-func (r *CarRepository) FindPublic(cars *[]*entities.Car) error {
-	return r.DB.
-		Preload("CarImages", func(db *gorm.DB) *gorm.DB {
-			return db.Order("sort_order ASC")
-		}).
-		Preload("Dealer").
-		Preload("Dealer.User").
-		Where("status = ? AND is_hidden = ?", "approved", false).
-		Find(cars).Error
-}
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/search"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CarRepository struct {
+	DB *gorm.DB
+}
+
+// CarFilter holds the public listing filters, sort mode and paging
+type CarFilter struct {
+	Query        string // normalized with search.Query
+	DealerID     uint
+	Brands       []string
+	ModelName    string
+	YearMin      int
+	YearMax      int
+	PriceMin     float64
+	PriceMax     float64
+	MileageMax   int
+	FuelTypes    []string
+	Transmission []string
+	CarTypes     []string
+	Colors       []string
+	Province     string
+	Near         *GeoPoint
+	Available    bool // leave out reserved cars
+	Sort         string
+	Page         int
+	Limit        int
+}
+
+// FacetCount is the number of matching cars for one value of a facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// CarSortOrders maps the public sort modes to ORDER BY clauses
+var CarSortOrders = map[string]string{
+	"newest":      "cars.created_at DESC",
+	"price_asc":   "cars.price ASC",
+	"price_desc":  "cars.price DESC",
+	"mileage":     "cars.mileage ASC",
+	"most_viewed": "cars.views DESC",
+	"promoted":    "(cars.is_promoted AND cars.promoted_until > NOW()) DESC, cars.created_at DESC", // rotated in FindPublicPage
+}
+
+// PromotionRotationInterval is how often the order of promoted cars is reshuffled
+const PromotionRotationInterval = time.Hour
+
+// promotedOrder ranks active promotions first. Promoted cars are ordered by a hash
+// of their id and the current rotation window, so each gets a fair share of the
+// top slots while paging stays stable within a window.
+func promotedOrder(now time.Time) clause.Expr {
+	seed := strconv.FormatInt(now.Truncate(PromotionRotationInterval).Unix(), 10)
+	return clause.Expr{
+		SQL: "(cars.is_promoted AND cars.promoted_until > NOW()) DESC, " +
+			"CASE WHEN cars.is_promoted AND cars.promoted_until > NOW() THEN md5(cars.id::text || ?) END, " +
+			"cars.created_at DESC",
+		Vars: []interface{}{seed},
+	}
+}
+
+// CarFacetColumns maps the facet names to their car columns
+var CarFacetColumns = map[string]string{
+	"brand":        "cars.brand",
+	"fuel_type":    "cars.fuel_type",
+	"transmission": "cars.transmission",
+}
+
+func (r *CarRepository) Create(car *entities.Car) error {
+	r.applySearchText(car)
+	return r.DB.Create(car).Error
+}
+
+// FindAll returns every car for the admin dashboard; drafts are private to the dealer
+func (r *CarRepository) FindAll(cars *[]*entities.Car) error {
+	return r.DB.
+		Preload("CarImages", liveCarImages).
+		Preload("Dealer").
+		Preload("Dealer.User").
+		Where("status <> ?", entities.CarStatusDraft).
+		Order("created_at DESC").
+		Find(cars).Error
+}
+
+func (r *CarRepository) FindByID(id uint, car *entities.Car) error {
+	return r.DB.
+		Preload("CarImages", liveCarImages).
+		Preload("Dealer").
+		Preload("Dealer.User").
+		First(car, id).Error
+}
+
+// liveCarImages preloads the published images of a car; images waiting in a
+// pending revision are excluded
+func liveCarImages(db *gorm.DB) *gorm.DB {
+	return db.Where("revision_id IS NULL").Order("sort_order ASC")
+}
+
+// ExpirePromotions clears promotions whose end time has passed
+func (r *CarRepository) ExpirePromotions(now time.Time) (int64, error) {
+	res := r.DB.Model(&entities.Car{}).
+		Where("is_promoted = ? AND (promoted_until IS NULL OR promoted_until <= ?)", true, now).
+		UpdateColumn("is_promoted", false)
+	return res.RowsAffected, res.Error
+}
+
+// FindPromotionsEndingBefore returns promoted cars ending before t whose dealer
+// has not been reminded yet
+func (r *CarRepository) FindPromotionsEndingBefore(t time.Time, cars *[]*entities.Car) error {
+	return r.DB.Preload("Dealer").
+		Where("is_promoted = ? AND promoted_until > NOW() AND promoted_until <= ?", true, t).
+		Where("promotion_reminded_at IS NULL").
+		Find(cars).Error
+}
+
+// MarkPromotionReminded records that the dealer was told the promotion is ending
+func (r *CarRepository) MarkPromotionReminded(carID uint, at time.Time) error {
+	return r.DB.Model(&entities.Car{}).Where("id = ?", carID).UpdateColumn("promotion_reminded_at", at).Error
+}
+
+// FindByDealerIDAndStatus returns the dealer's cars in one status, most recently changed first
+func (r *CarRepository) FindByDealerIDAndStatus(dealerID uint, status string, cars *[]*entities.Car) error {
+	return r.DB.
+		Preload("CarImages", liveCarImages).
+		Where("dealer_id = ? AND status = ?", dealerID, status).
+		Order("updated_at DESC").
+		Find(cars).Error
+}
+
+// AssignMissingExpiry gives live cars without an expiry date one (cars approved
+// before listing expiry existed)
+func (r *CarRepository) AssignMissingExpiry(expiresAt time.Time) (int64, error) {
+	res := r.DB.Model(&entities.Car{}).
+		Where("status IN ? AND expires_at IS NULL", entities.PublicCarStatuses).
+		UpdateColumn("expires_at", expiresAt)
+	return res.RowsAffected, res.Error
+}
+
+// FindExpiringListings returns live cars expiring before t whose dealer has not been warned
+func (r *CarRepository) FindExpiringListings(t time.Time, cars *[]*entities.Car) error {
+	return r.DB.Preload("Dealer").
+		Where("status IN ? AND expires_at > NOW() AND expires_at <= ?", entities.PublicCarStatuses, t).
+		Where("expiry_warned_at IS NULL").
+		Find(cars).Error
+}
+
+func (r *CarRepository) MarkExpiryWarned(carID uint, at time.Time) error {
+	return r.DB.Model(&entities.Car{}).Where("id = ?", carID).UpdateColumn("expiry_warned_at", at).Error
+}
+
+// FindExpiredListings returns cars still in a live status whose expiry date has passed
+func (r *CarRepository) FindExpiredListings(cars *[]*entities.Car) error {
+	return r.DB.Preload("Dealer").
+		Where("status IN ? AND expires_at <= NOW()", entities.PublicCarStatuses).
+		Find(cars).Error
+}
+
+// FindDueDrafts returns drafts whose scheduled publish time has passed
+func (r *CarRepository) FindDueDrafts(cars *[]*entities.Car) error {
+	return r.DB.Preload("Dealer").
+		Where("status = ? AND publish_at <= NOW()", entities.CarStatusDraft).
+		Find(cars).Error
+}
+
+// FindDuePublications returns approved cars whose scheduled publish time has passed
+func (r *CarRepository) FindDuePublications(cars *[]*entities.Car) error {
+	return r.DB.Preload("Dealer").
+		Where("status IN ? AND publish_at <= NOW()", entities.PublicCarStatuses).
+		Find(cars).Error
+}
+
+// ClearPublishAt drops a car's publish schedule; updated_at is bumped so feeds pick the car up
+func (r *CarRepository) ClearPublishAt(carID uint) error {
+	return r.DB.Model(&entities.Car{}).Where("id = ?", carID).Update("publish_at", nil).Error
+}
+
+// UpdateDealRating stores the market price rating of a car ("" without enough comparables)
+func (r *CarRepository) UpdateDealRating(carID uint, rating string) error {
+	return r.DB.Model(&entities.Car{}).Where("id = ?", carID).UpdateColumn("deal_rating", rating).Error
+}
+
+// EachPublicCar calls fn, in batches, for every publicly listed car
+func (r *CarRepository) EachPublicCar(fn func(car *entities.Car) error) error {
+	var batch []*entities.Car
+	return r.publicQuery(CarFilter{}).
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			for _, c := range batch {
+				if err := fn(c); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// UpdateMonthlyFrom stores the lowest finance installment of a car (nil without offers)
+func (r *CarRepository) UpdateMonthlyFrom(carID uint, monthly *float64) error {
+	return r.DB.Model(&entities.Car{}).Where("id = ?", carID).UpdateColumn("monthly_from", monthly).Error
+}
+
+// RecordContact counts a call or LINE contact, and the lead it creates, without
+// touching updated_at
+func (r *CarRepository) RecordContact(carID uint, via string) error {
+	column := "call_count"
+	if via == "line" {
+		column = "line_count"
+	}
+	return r.DB.Model(&entities.Car{}).Where("id = ?", carID).UpdateColumns(map[string]interface{}{
+		column:       gorm.Expr(column + " + 1"),
+		"lead_count": gorm.Expr("lead_count + 1"),
+	}).Error
+}
+
+// IncrementLeadCount counts a new lead for a car without touching updated_at
+func (r *CarRepository) IncrementLeadCount(carID uint) error {
+	return r.DB.Model(&entities.Car{}).Where("id = ?", carID).UpdateColumn("lead_count", gorm.Expr("lead_count + 1")).Error
+}
+
+// FindDealerID returns the dealer that owns a car
+func (r *CarRepository) FindDealerID(carID uint) (uint, error) {
+	var car entities.Car
+	err := r.DB.Select("id", "dealer_id").First(&car, carID).Error
+	return car.DealerID, err
+}
+
+func (r *CarRepository) Update(car *entities.Car) error {
+	r.applySearchText(car)
+	return r.DB.Save(car).Error
+}
+
+// ErrStatusChanged is returned when a car's status changed after it was loaded
+var ErrStatusChanged = errors.New("the car status was changed in the meantime, please reload")
+
+// statusColumns are the car columns a status transition may change
+var statusColumns = []string{"status", "expires_at", "expiry_warned_at", "reserved_until", "is_hidden", "violation_reason", "updated_at"}
+
+// UpdateStatus writes the status columns of a car still in status history.FromStatus
+// and records the transition, in one transaction. Other columns (views,
+// counters, edits made meanwhile) are left alone.
+func (r *CarRepository) UpdateStatus(car *entities.Car, history *entities.CarStatusHistory) error {
+	return r.UpdateStatusWithSale(car, history, nil)
+}
+
+// UpdateStatusWithSale is UpdateStatus that also saves the sale of a car being
+// sold, so a sold car never lacks its sale record. Columns in extra are written
+// in the same update as the status (e.g. publish_at when a schedule is used up).
+func (r *CarRepository) UpdateStatusWithSale(car *entities.Car, history *entities.CarStatusHistory, sale *entities.Sale, extra ...string) error {
+	columns := append(append([]string{}, statusColumns...), extra...)
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(car).Where("status = ?", history.FromStatus).Select(columns).Updates(car)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+		if err := tx.Create(history).Error; err != nil {
+			return err
+		}
+		if sale == nil {
+			return nil
+		}
+		return tx.Omit(clause.Associations).Create(sale).Error
+	})
+}
+
+// UpdateFields writes only the given columns of a car and refreshes its search text
+func (r *CarRepository) UpdateFields(car *entities.Car, fields map[string]interface{}) error {
+	r.applySearchText(car)
+	fields["search_text"] = car.SearchText
+	return r.DB.Model(car).Updates(fields).Error
+}
+
+// UpdateFieldsWithHistory is UpdateFields that also logs a status history entry,
+// for moderation changes such as hiding a car that leave its status as it is
+func (r *CarRepository) UpdateFieldsWithHistory(car *entities.Car, fields map[string]interface{}, history *entities.CarStatusHistory) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := (&CarRepository{DB: tx}).UpdateFields(car, fields); err != nil {
+			return err
+		}
+		return tx.Create(history).Error
+	})
+}
+
+// UpdateFieldsWithPrice is UpdateFields that also logs a price change, so the new
+// price is never stored without its history row
+func (r *CarRepository) UpdateFieldsWithPrice(car *entities.Car, fields map[string]interface{}, history *entities.CarPriceHistory) error {
+	if history == nil {
+		return r.UpdateFields(car, fields)
+	}
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := (&CarRepository{DB: tx}).UpdateFields(car, fields); err != nil {
+			return err
+		}
+		return tx.Create(history).Error
+	})
+}
+
+func (r *CarRepository) Delete(id uint) error {
+	return r.DB.Delete(&entities.Car{}, id).Error
+}
+
+func (r *CarRepository) FindByDealerID(dealerID uint, cars *[]*entities.Car) error {
+	return r.DB.
+		Preload("CarImages", liveCarImages).
+		Preload("Dealer").
+		Preload("Dealer.User").
+		Where("dealer_id = ?", dealerID).
+		Find(cars).Error
+}
+
+func (r *CarRepository) FindPublic(cars *[]*entities.Car) error {
+	return r.DB.
+		Preload("CarImages", liveCarImages).
+		Preload("Dealer").
+		Preload("Dealer.User").
+		Where("status IN ? AND is_hidden = ?", entities.ListedCarStatuses, false).
+		Where("expires_at IS NULL OR expires_at > NOW()").
+		Where("publish_at IS NULL OR publish_at <= NOW()").
+		Order(promotedOrder(time.Now())).
+		Find(cars).Error
+}
+
+// FindPublicPage returns one page of public cars matching the filter and the total match count
+func (r *CarRepository) FindPublicPage(filter CarFilter, cars *[]*entities.Car) (int64, error) {
+	var total int64
+	if err := r.publicQuery(filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	q := r.publicQuery(filter)
+	if filter.Near != nil {
+		q = q.Select("cars.*, ? AS distance_km", distanceExpr(*filter.Near))
+	}
+	if filter.Sort == "distance" && filter.Near != nil {
+		q = q.Order("distance_km ASC")
+	} else if filter.Sort == "relevance" && filter.Query != "" {
+		q = q.Order(clause.Expr{
+			SQL:  "ts_rank(to_tsvector('simple', cars.search_text), plainto_tsquery('simple', ?)) DESC",
+			Vars: []interface{}{filter.Query},
+		})
+	} else if filter.Sort == "promoted" {
+		q = q.Order(promotedOrder(time.Now()))
+	} else {
+		order, ok := CarSortOrders[filter.Sort]
+		if !ok {
+			order = CarSortOrders["newest"]
+		}
+		q = q.Order(order)
+	}
+
+	err := q.
+		Preload("CarImages", liveCarImages).
+		Preload("Dealer").
+		Preload("Dealer.User").
+		Order("cars.id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(cars).Error
+	return total, err
+}
+
+// CountPublicFacet counts public cars per value of a facet.
+// The facet's own filter is ignored so the other values stay selectable.
+func (r *CarRepository) CountPublicFacet(filter CarFilter, facet string) ([]FacetCount, error) {
+	column, ok := CarFacetColumns[facet]
+	if !ok {
+		return nil, nil
+	}
+
+	switch facet {
+	case "brand":
+		filter.Brands = nil
+	case "fuel_type":
+		filter.FuelTypes = nil
+	case "transmission":
+		filter.Transmission = nil
+	}
+
+	var counts []FacetCount
+	err := r.publicQuery(filter).
+		Select(column + " AS value, COUNT(*) AS count").
+		Where(column + " <> ''").
+		Group(column).
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// publicQuery builds the base query for publicly listed, visible cars matching
+// the filter. Reserved cars are listed unless the filter asks for available ones.
+func (r *CarRepository) publicQuery(filter CarFilter) *gorm.DB {
+	q := r.DB.Model(&entities.Car{}).
+		Where("cars.status IN ? AND cars.is_hidden = ?", entities.ListedCarStatuses, false).
+		Where("cars.expires_at IS NULL OR cars.expires_at > NOW()").
+		Where("cars.publish_at IS NULL OR cars.publish_at <= NOW()")
+
+	if filter.Available {
+		q = q.Where("cars.status <> ?", entities.CarStatusReserved)
+	}
+	if filter.Query != "" {
+		q = q.Where("to_tsvector('simple', cars.search_text) @@ plainto_tsquery('simple', ?)", filter.Query)
+	}
+	if filter.DealerID != 0 {
+		q = q.Where("cars.dealer_id = ?", filter.DealerID)
+	}
+	if len(filter.Brands) > 0 {
+		q = q.Where("LOWER(cars.brand) IN ?", lowerAll(filter.Brands))
+	}
+	if filter.ModelName != "" {
+		q = q.Where("cars.model_name ILIKE ?", "%"+filter.ModelName+"%")
+	}
+	if filter.YearMin > 0 {
+		q = q.Where("cars.year >= ?", filter.YearMin)
+	}
+	if filter.YearMax > 0 {
+		q = q.Where("cars.year <= ?", filter.YearMax)
+	}
+	if filter.PriceMin > 0 {
+		q = q.Where("cars.price >= ?", filter.PriceMin)
+	}
+	if filter.PriceMax > 0 {
+		q = q.Where("cars.price <= ?", filter.PriceMax)
+	}
+	if filter.MileageMax > 0 {
+		q = q.Where("cars.mileage <= ?", filter.MileageMax)
+	}
+	if len(filter.FuelTypes) > 0 {
+		q = q.Where("LOWER(cars.fuel_type) IN ?", lowerAll(filter.FuelTypes))
+	}
+	if len(filter.Transmission) > 0 {
+		q = q.Where("LOWER(cars.transmission) IN ?", lowerAll(filter.Transmission))
+	}
+	if len(filter.CarTypes) > 0 {
+		q = q.Where("LOWER(cars.car_type) IN ?", lowerAll(filter.CarTypes))
+	}
+	if len(filter.Colors) > 0 {
+		q = q.Where("LOWER(cars.color) IN ?", lowerAll(filter.Colors))
+	}
+	if filter.Province != "" || filter.Near != nil {
+		q = q.Joins("JOIN dealers ON dealers.id = cars.dealer_id AND dealers.deleted_at IS NULL")
+	}
+	if filter.Province != "" {
+		q = q.Where("dealers.province = ?", filter.Province)
+	}
+	if filter.Near != nil {
+		q = q.Where("dealers.latitude IS NOT NULL AND dealers.longitude IS NOT NULL")
+		if filter.Near.RadiusKm > 0 {
+			q = q.Where("? <= ?", distanceExpr(*filter.Near), filter.Near.RadiusKm)
+		}
+	}
+	return q
+}
+
+// FeedCursor is the position of the last car on a feed page
+type FeedCursor struct {
+	UpdatedAt time.Time
+	ID        uint
+}
+
+// FindFeedCars returns up to limit public cars (of one dealer when dealerID != 0)
+// changed after since, in change order, starting after the cursor
+func (r *CarRepository) FindFeedCars(dealerID uint, since *time.Time, after *FeedCursor, limit int, cars *[]*entities.Car) error {
+	q := r.publicQuery(CarFilter{DealerID: dealerID}).
+		Preload("CarImages", liveCarImages).
+		Preload("Dealer")
+	if since != nil {
+		q = q.Where("cars.updated_at > ?", *since)
+	}
+	if after != nil {
+		q = q.Where("(cars.updated_at, cars.id) > (?, ?)", after.UpdatedAt, after.ID)
+	}
+	return q.Order("cars.updated_at ASC, cars.id ASC").Limit(limit).Find(cars).Error
+}
+
+// FindFeedRemovedIDs returns cars changed or deleted after since that were
+// listed before and are no longer public. Cars that never went live (drafts,
+// pending, rejected) are not reported.
+func (r *CarRepository) FindFeedRemovedIDs(dealerID uint, since time.Time) ([]uint, error) {
+	public := r.publicQuery(CarFilter{}).Select("cars.id")
+	wasListed := r.DB.Model(&entities.CarStatusHistory{}).
+		Select("1").
+		Where("car_status_histories.car_id = cars.id AND car_status_histories.to_status IN ?", entities.ListedCarStatuses)
+	q := r.DB.Unscoped().Model(&entities.Car{}).
+		Where("cars.updated_at > ? OR cars.deleted_at > ?", since, since).
+		Where("cars.deleted_at IS NOT NULL OR cars.id NOT IN (?)", public).
+		Where("cars.status IN ? OR EXISTS (?)", entities.ListedCarStatuses, wasListed)
+	if dealerID != 0 {
+		q = q.Where("cars.dealer_id = ?", dealerID)
+	}
+	var ids []uint
+	err := q.Order("cars.id").Pluck("cars.id", &ids).Error
+	return ids, err
+}
+
+// FeedVersion returns the number of cars and the time of the latest change
+// (including deletions), used to validate cached feeds
+func (r *CarRepository) FeedVersion(dealerID uint) (int64, time.Time, error) {
+	var row struct {
+		Count        int64
+		LastModified *time.Time
+	}
+	q := r.DB.Unscoped().Model(&entities.Car{}).
+		Select("COUNT(*) FILTER (WHERE deleted_at IS NULL) AS count, MAX(GREATEST(updated_at, deleted_at)) AS last_modified")
+	if dealerID != 0 {
+		q = q.Where("dealer_id = ?", dealerID)
+	}
+	if err := q.Scan(&row).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	if row.LastModified == nil {
+		return row.Count, time.Time{}, nil
+	}
+	return row.Count, *row.LastModified, nil
+}
+
+// SimilarWeights are the points a candidate earns per matching attribute. The
+// SQL ranking below and similarityScore in usecases/car both use them.
+var SimilarWeights = struct {
+	Brand, Model, CarType float64
+	Price, PriceBand      float64 // full points at the same price, none at ±PriceBand
+	Year, YearBand        float64 // full points in the same year, none at ±YearBand years
+	Fuel, Transmission    float64
+}{
+	Brand: 3, Model: 4, CarType: 2,
+	Price: 3, PriceBand: 0.3,
+	Year: 2, YearBand: 4,
+	Fuel: 0.5, Transmission: 0.5,
+}
+
+// FindSimilarCandidates returns the available cars closest to the given car:
+// the same model at any price, or the same car type or brand priced within
+// PriceBand. They are ranked in SQL by SimilarWeights (co-favorites are added
+// later), so the limit keeps the best matches, not the newest.
+func (r *CarRepository) FindSimilarCandidates(car *entities.Car, limit int, cars *[]*entities.Car) error {
+	w := SimilarWeights
+	args := map[string]interface{}{
+		"brand":     car.Brand,
+		"model":     strings.TrimSpace(car.ModelName),
+		"car_type":  car.CarType,
+		"price":     car.Price,
+		"price_min": car.Price * (1 - w.PriceBand),
+		"price_max": car.Price * (1 + w.PriceBand),
+		"year":      car.Year,
+		"fuel":      car.FuelType,
+		"gear":      car.Transmission,
+	}
+	sameBrand := "LOWER(cars.brand) = LOWER(@brand)"
+	sameModel := "(" + sameBrand + " AND LOWER(TRIM(cars.model_name)) = LOWER(@model))"
+	sameType := "LOWER(cars.car_type) = LOWER(@car_type)"
+
+	related := sameBrand
+	if car.CarType != "" {
+		related = "(" + sameBrand + " OR " + sameType + ")"
+	}
+	if car.Price > 0 {
+		related += " AND cars.price BETWEEN @price_min AND @price_max"
+	}
+
+	points := func(cond string, weight float64) string {
+		return fmt.Sprintf("CASE WHEN %s THEN %g ELSE 0 END", cond, weight)
+	}
+	score := []string{points(sameBrand, w.Brand), points(sameModel, w.Model)}
+	if car.CarType != "" {
+		score = append(score, points(sameType, w.CarType))
+	}
+	if car.Price > 0 {
+		score = append(score, fmt.Sprintf("%g * GREATEST(0, 1 - ABS(cars.price - @price) / @price / %f)", w.Price, w.PriceBand))
+	}
+	if car.Year > 0 {
+		score = append(score, fmt.Sprintf("CASE WHEN cars.year > 0 THEN %g * GREATEST(0, 1 - ABS(cars.year - @year) / %f) ELSE 0 END", w.Year, w.YearBand))
+	}
+	if car.FuelType != "" {
+		score = append(score, points("LOWER(cars.fuel_type) = LOWER(@fuel)", w.Fuel))
+	}
+	if car.Transmission != "" {
+		score = append(score, points("LOWER(cars.transmission) = LOWER(@gear)", w.Transmission))
+	}
+
+	return r.publicQuery(CarFilter{Available: true}).
+		Preload("CarImages", liveCarImages).
+		Preload("Dealer").
+		Where("cars.id <> ?", car.ID).
+		Where(sameModel+" OR ("+related+")", args).
+		Clauses(clause.OrderBy{Expression: clause.NamedExpr{
+			SQL:  "(" + strings.Join(score, " + ") + ") DESC, cars.created_at DESC",
+			Vars: []interface{}{args},
+		}}).
+		Limit(limit).
+		Find(cars).Error
+}
+
+// ComparableFilter selects the listings a valuation is based on
+type ComparableFilter struct {
+	ModelID   *uint // catalog model; Brand/ModelName are matched for cars without one
+	Brand     string
+	ModelName string
+	YearMin   int
+	YearMax   int
+	SoldSince time.Time // sold listings older than this are ignored
+	ExcludeID uint
+}
+
+// FindComparables returns the price, year and mileage of live and recently sold
+// cars of the same model, most recently changed first
+func (r *CarRepository) FindComparables(f ComparableFilter, cars *[]*entities.Car) error {
+	q := r.DB.Model(&entities.Car{}).
+		Select("id", "price", "year", "mileage", "status").
+		Where("price > 0 AND year BETWEEN ? AND ?", f.YearMin, f.YearMax).
+		Where("(status IN ? AND is_hidden = ?) OR (status = ? AND updated_at >= ?)",
+			entities.ListedCarStatuses, false, entities.CarStatusSold, f.SoldSince)
+
+	byName := "LOWER(brand) = ? AND LOWER(model_name) = ?"
+	if f.ModelID != nil {
+		q = q.Where("model_id = ? OR (model_id IS NULL AND "+byName+")",
+			*f.ModelID, strings.ToLower(f.Brand), strings.ToLower(f.ModelName))
+	} else {
+		q = q.Where(byName, strings.ToLower(f.Brand), strings.ToLower(f.ModelName))
+	}
+	if f.ExcludeID != 0 {
+		q = q.Where("id <> ?", f.ExcludeID)
+	}
+	return q.Order("updated_at DESC").Limit(500).Find(cars).Error
+}
+
+// EachCar calls fn for every car, in batches
+func (r *CarRepository) EachCar(fn func(car *entities.Car) error) error {
+	var batch []*entities.Car
+	return r.DB.Preload("Dealer").
+		FindInBatches(&batch, 200, func(tx *gorm.DB, _ int) error {
+			for _, car := range batch {
+				if err := fn(car); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// ReindexSearch rebuilds the search document of every car, or of one dealer's cars
+func (r *CarRepository) ReindexSearch(dealerID uint) error {
+	q := r.DB.Preload("Dealer")
+	if dealerID != 0 {
+		q = q.Where("dealer_id = ?", dealerID)
+	}
+
+	var batch []*entities.Car
+	return q.FindInBatches(&batch, 200, func(tx *gorm.DB, _ int) error {
+		for _, car := range batch {
+			r.applySearchText(car)
+			if err := r.DB.Model(car).UpdateColumn("search_text", car.SearchText).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// applySearchText rebuilds the car's full-text search document
+func (r *CarRepository) applySearchText(car *entities.Car) {
+	shopName := car.Dealer.ShopName
+	if car.Dealer.ID != car.DealerID {
+		var names []string
+		r.DB.Model(&entities.Dealer{}).Where("id = ?", car.DealerID).Pluck("shop_name", &names)
+		if len(names) > 0 {
+			shopName = names[0]
+		}
+	}
+
+	year := ""
+	if car.Year > 0 {
+		year = strconv.Itoa(car.Year)
+	}
+	car.SearchText = search.Document(car.Brand, car.ModelName, year, car.Description, shopName)
+}
+
+func lowerAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToLower(v)
+	}
+	return out
+}
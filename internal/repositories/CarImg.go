@@ -1,37 +1,87 @@
-package repositories
-
-import (
-	"Backend_Go/internal/entities"
-
-	"gorm.io/gorm"
-)
-
-type CarImageRepository struct{ DB *gorm.DB }
-
-func (r *CarImageRepository) Create(img *entities.CarImage) error {
-	return r.DB.Create(img).Error
-}
-
-func (r *CarImageRepository) FindByCarID(carID uint) ([]*entities.CarImage, error) {
-	var images []*entities.CarImage
-	err := r.DB.Where("car_id = ?", carID).Find(&images).Error
-	return images, err
-}
-
-func (r *CarImageRepository) FindByID(id uint) (*entities.CarImage, error) {
-	var image *entities.CarImage
-	err := r.DB.First(&image, id).Error
-	return image, err
-}
-
-func (r *CarImageRepository) Delete(id uint) error {
-	return r.DB.Delete(&entities.CarImage{}, id).Error
-}
-
-func (r *CarImageRepository) DeleteByCarID(carID uint) error {
-	return r.DB.Where("car_id = ?", carID).Delete(&entities.CarImage{}).Error
-}
-
-func (r *CarImageRepository) Update(img *entities.CarImage) error {
-	return r.DB.Save(img).Error
-}
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CarImageRepository struct{ DB *gorm.DB }
+
+func (r *CarImageRepository) Create(img *entities.CarImage) error {
+	return r.DB.Create(img).Error
+}
+
+// CreateWithinLimit saves new images of a car while it has room for them
+// (limit <= 0: no limit). The car row is locked and the images are recounted in
+// the same transaction, so concurrent uploads cannot pass the limit together.
+// Images that do not fit are returned unsaved.
+func (r *CarImageRepository) CreateWithinLimit(carID uint, images []*entities.CarImage, limit int) ([]*entities.CarImage, error) {
+	var rejected []*entities.CarImage
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&entities.Car{}, carID).Error; err != nil {
+			return err
+		}
+		accepted := images
+		if limit > 0 {
+			count, err := (&CarImageRepository{DB: tx}).CountAfterReview(carID)
+			if err != nil {
+				return err
+			}
+			if room := max(0, limit-int(count)); room < len(images) {
+				accepted, rejected = images[:room], images[room:]
+			}
+		}
+		if len(accepted) == 0 {
+			return nil
+		}
+		return tx.Create(&accepted).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rejected, nil
+}
+
+func (r *CarImageRepository) FindByCarID(carID uint) ([]*entities.CarImage, error) {
+	var images []*entities.CarImage
+	err := r.DB.Where("car_id = ? AND revision_id IS NULL", carID).Order("sort_order ASC").Find(&images).Error
+	return images, err
+}
+
+// CountAfterReview counts the images a car will show once its pending
+// revision is approved: the live images plus those of the pending revision
+func (r *CarImageRepository) CountAfterReview(carID uint) (int64, error) {
+	pending := r.DB.Model(&entities.CarRevision{}).Select("id").
+		Where("car_id = ? AND status = ?", carID, entities.RevisionPending)
+	var count int64
+	err := r.DB.Model(&entities.CarImage{}).
+		Where("car_id = ? AND (revision_id IS NULL OR revision_id IN (?))", carID, pending).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *CarImageRepository) FindByID(id uint) (*entities.CarImage, error) {
+	var image *entities.CarImage
+	err := r.DB.First(&image, id).Error
+	return image, err
+}
+
+func (r *CarImageRepository) Delete(id uint) error {
+	return r.DB.Delete(&entities.CarImage{}, id).Error
+}
+
+func (r *CarImageRepository) DeleteByCarID(carID uint) error {
+	return r.DB.Where("car_id = ?", carID).Delete(&entities.CarImage{}).Error
+}
+
+// UpdateSortOrder moves a live image. Images waiting in a revision are not
+// touched, so reordering cannot publish them; it reports false when no live
+// image has the id.
+func (r *CarImageRepository) UpdateSortOrder(id uint, sortOrder int) (bool, error) {
+	res := r.DB.Model(&entities.CarImage{}).
+		Where("id = ? AND revision_id IS NULL", id).
+		Update("sort_order", sortOrder)
+	return res.RowsAffected > 0, res.Error
+}
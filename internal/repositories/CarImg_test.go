@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"Backend_Go/internal/entities"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUpdateSortOrderWritesOnlyTheSortOrderOfLiveImages(t *testing.T) {
+	db := dryRunDB(t)
+	queries := captureQueries(t, db)
+	repo := &CarImageRepository{DB: db}
+
+	if _, err := repo.UpdateSortOrder(5, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(*queries) != 1 {
+		t.Fatalf("queries = %q, want one update", *queries)
+	}
+	sql := (*queries)[0]
+	if !strings.HasPrefix(sql, `UPDATE "car_images" SET "sort_order"=2,"updated_at"=`) {
+		t.Errorf("update writes more than the sort order: %s", sql)
+	}
+	if !strings.Contains(sql, "WHERE (id = 5 AND revision_id IS NULL)") {
+		t.Errorf("update is not limited to the live image: %s", sql)
+	}
+}
+
+func TestCreateWithinLimitRecountsUnderTheCarLock(t *testing.T) {
+	db, mock := mockDB(t)
+	repo := &CarImageRepository{DB: db}
+	images := []*entities.CarImage{
+		{CarID: 7, ImageURL: "/a.jpg"},
+		{CarID: 7, ImageURL: "/b.jpg"},
+		{CarID: 7, ImageURL: "/c.jpg"},
+	}
+
+	// Another upload filled the car up to 9 of 10 images after the first check
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "cars" WHERE "cars"."id" = \$1 .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "car_images"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(9))
+	mock.ExpectQuery(`INSERT INTO "car_images"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	rejected, err := repo.CreateWithinLimit(7, images, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rejected) != 2 || rejected[0] != images[1] || rejected[1] != images[2] {
+		t.Errorf("rejected = %v, want the last two images", rejected)
+	}
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+)
+
+type CarPriceHistoryRepository struct{ DB *gorm.DB }
+
+func (r *CarPriceHistoryRepository) Create(h *entities.CarPriceHistory) error {
+	return r.DB.Create(h).Error
+}
+
+func (r *CarPriceHistoryRepository) FindByCarID(carID uint, history *[]*entities.CarPriceHistory) error {
+	return r.DB.Where("car_id = ?", carID).Order("created_at ASC").Find(history).Error
+}
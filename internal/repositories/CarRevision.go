@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"errors"
+
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+)
+
+type CarRevisionRepository struct{ DB *gorm.DB }
+
+// ErrRevisionClosed is returned when a revision was reviewed in the meantime
+var ErrRevisionClosed = errors.New("this revision was already reviewed")
+
+var revisionReviewColumns = []string{"status", "reviewed_by", "reviewed_at", "review_reason", "updated_at"}
+
+func (r *CarRevisionRepository) Create(rev *entities.CarRevision) error {
+	return r.DB.Create(rev).Error
+}
+
+func (r *CarRevisionRepository) Update(rev *entities.CarRevision) error {
+	return r.DB.Omit("Images").Save(rev).Error
+}
+
+func (r *CarRevisionRepository) FindByID(id uint, rev *entities.CarRevision) error {
+	return r.DB.Preload("Images").First(rev, id).Error
+}
+
+// FindPending returns the open revision of a car, if any
+func (r *CarRevisionRepository) FindPending(carID uint, rev *entities.CarRevision) error {
+	return r.DB.Where("car_id = ? AND status = ?", carID, entities.RevisionPending).First(rev).Error
+}
+
+func (r *CarRevisionRepository) FindByCarID(carID uint, revisions *[]*entities.CarRevision) error {
+	return r.DB.Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC")
+	}).Where("car_id = ?", carID).Order("created_at DESC").Find(revisions).Error
+}
+
+// Reject closes a revision that is still pending and removes its photos, in one
+// transaction. ErrRevisionClosed means it was reviewed in the meantime.
+func (r *CarRevisionRepository) Reject(rev *entities.CarRevision) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(rev).Where("status = ?", entities.RevisionPending).Select(revisionReviewColumns).Updates(rev)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRevisionClosed
+		}
+		return tx.Where("revision_id = ?", rev.ID).Delete(&entities.CarImage{}).Error
+	})
+}
+
+// Approve writes the approved fields to the car, records the price change (if any),
+// publishes the revision's photos and closes the revision in one transaction
+func (r *CarRevisionRepository) Approve(rev *entities.CarRevision, car *entities.Car, fields map[string]interface{}, history *entities.CarPriceHistory) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(rev).Where("status = ?", entities.RevisionPending).Select(revisionReviewColumns).Updates(rev)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRevisionClosed
+		}
+		if len(fields) > 0 {
+			if err := (&CarRepository{DB: tx}).UpdateFields(car, fields); err != nil {
+				return err
+			}
+		}
+		if history != nil {
+			if err := tx.Create(history).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&entities.CarImage{}).Where("revision_id = ?", rev.ID).Update("revision_id", nil).Error
+	})
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CarStatRepository struct{ DB *gorm.DB }
+
+// RecordView stores a view unless the same viewer saw the car within window.
+// The car counter and the rollup row of day are updated in the same transaction.
+func (r *CarStatRepository) RecordView(view *entities.CarView, day time.Time, window time.Duration) (bool, error) {
+	recorded := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// Serialize concurrent requests of the same viewer for the same car
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", fmt.Sprintf("car_view:%d:%s", view.CarID, view.ViewerKey)).Error; err != nil {
+			return err
+		}
+
+		var seen int64
+		if err := tx.Model(&entities.CarView{}).
+			Where("car_id = ? AND viewer_key = ? AND created_at > ?", view.CarID, view.ViewerKey, view.CreatedAt.Add(-window)).
+			Count(&seen).Error; err != nil {
+			return err
+		}
+		if seen > 0 {
+			return nil
+		}
+
+		if err := tx.Create(view).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "car_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("car_daily_stats.views + 1")}),
+		}).Create(&entities.CarDailyStat{CarID: view.CarID, Day: dateOnly(day), Views: 1}).Error; err != nil {
+			return err
+		}
+		// UpdateColumn keeps updated_at (and the syndication feeds) untouched
+		if err := tx.Model(&entities.Car{}).Where("id = ?", view.CarID).
+			UpdateColumn("views", gorm.Expr("views + 1")).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// FindDaily returns the daily rows of a car between from and to (inclusive dates)
+func (r *CarStatRepository) FindDaily(carID uint, from, to time.Time, stats *[]*entities.CarDailyStat) error {
+	return r.DB.Where("car_id = ? AND day BETWEEN ? AND ?", carID, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("day ASC").Find(stats).Error
+}
+
+// dateOnly keeps the calendar date of t, so the date column doesn't shift with time zones
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// PruneViews deletes raw view rows older than before
+func (r *CarStatRepository) PruneViews(before time.Time) (int64, error) {
+	res := r.DB.Where("created_at < ?", before).Delete(&entities.CarView{})
+	return res.RowsAffected, res.Error
+}
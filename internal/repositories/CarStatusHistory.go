@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CarStatusHistoryRepository struct{ DB *gorm.DB }
+
+func (r *CarStatusHistoryRepository) FindByCarID(carID uint, history *[]*entities.CarStatusHistory) error {
+	return r.DB.Where("car_id = ?", carID).Order("created_at ASC, id ASC").Find(history).Error
+}
+
+// FirstApprovedAt returns when the car was first approved, nil if it never was
+func (r *CarStatusHistoryRepository) FirstApprovedAt(carID uint) (*time.Time, error) {
+	var at *time.Time
+	err := r.DB.Model(&entities.CarStatusHistory{}).
+		Select("MIN(created_at)").
+		Where("car_id = ? AND to_status = ?", carID, entities.CarStatusApproved).
+		Scan(&at).Error
+	return at, err
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"Backend_Go/internal/entities"
+)
+
+func TestFindSimilarCandidatesRanksBySimilarWeights(t *testing.T) {
+	db := dryRunDB(t)
+	queries := captureQueries(t, db)
+	repo := &CarRepository{DB: db}
+	car := &entities.Car{ID: 1, Brand: "Toyota", ModelName: "Camry", CarType: "sedan",
+		Price: 800000, Year: 2020, FuelType: "hybrid", Transmission: "auto"}
+
+	var cars []*entities.Car
+	if err := repo.FindSimilarCandidates(car, 10, &cars); err != nil {
+		t.Fatal(err)
+	}
+	sql := (*queries)[0]
+	w := SimilarWeights
+	for _, want := range []string{
+		fmt.Sprintf("LOWER(cars.brand) = LOWER('Toyota') THEN %g ELSE 0 END", w.Brand),
+		fmt.Sprintf("LOWER(TRIM(cars.model_name)) = LOWER('Camry')) THEN %g ELSE 0 END", w.Model),
+		fmt.Sprintf("LOWER(cars.car_type) = LOWER('sedan') THEN %g ELSE 0 END", w.CarType),
+		fmt.Sprintf("%g * GREATEST(0, 1 - ABS(cars.price - 800000) / 800000 / %f)", w.Price, w.PriceBand),
+		fmt.Sprintf("%g * GREATEST(0, 1 - ABS(cars.year - 2020) / %f)", w.Year, w.YearBand),
+		fmt.Sprintf("LOWER(cars.fuel_type) = LOWER('hybrid') THEN %g ELSE 0 END", w.Fuel),
+		fmt.Sprintf("LOWER(cars.transmission) = LOWER('auto') THEN %g ELSE 0 END", w.Transmission),
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("query is missing %q:\n%s", want, sql)
+		}
+	}
+}
+
+func TestRecordContactIncrementsInSQL(t *testing.T) {
+	db := dryRunDB(t)
+	queries := captureQueries(t, db)
+	repo := &CarRepository{DB: db}
+
+	if err := repo.RecordContact(5, "line"); err != nil {
+		t.Fatal(err)
+	}
+	want := `UPDATE "cars" SET "lead_count"=lead_count + 1,"line_count"=line_count + 1 WHERE id = 5`
+	if len(*queries) != 1 || !strings.HasPrefix((*queries)[0], want) {
+		t.Errorf("queries = %q, want %q", *queries, want)
+	}
+}
+
+func TestFindFeedCarsPagesAfterCursor(t *testing.T) {
+	db := dryRunDB(t)
+	queries := captureQueries(t, db)
+	repo := &CarRepository{DB: db}
+	after := &FeedCursor{UpdatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), ID: 42}
+
+	var cars []*entities.Car
+	if err := repo.FindFeedCars(0, nil, after, 51, &cars); err != nil {
+		t.Fatal(err)
+	}
+	sql := (*queries)[0]
+	for _, want := range []string{
+		"(cars.updated_at, cars.id) > ('2025-01-02 03:04:05'",
+		", 42)",
+		"ORDER BY cars.updated_at ASC, cars.id ASC LIMIT 51",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("query is missing %q:\n%s", want, sql)
+		}
+	}
+}
+
+func TestFindFeedRemovedIDsOnlyReportsCarsThatWereListed(t *testing.T) {
+	db := dryRunDB(t)
+	queries := captureQueries(t, db)
+	repo := &CarRepository{DB: db}
+
+	if _, err := repo.FindFeedRemovedIDs(7, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	sql := (*queries)[len(*queries)-1]
+	for _, want := range []string{
+		"cars.status IN ('approved','selling','reserved') OR EXISTS (SELECT 1 FROM \"car_status_histories\" WHERE car_status_histories.car_id = cars.id AND car_status_histories.to_status IN ('approved','selling','reserved'))",
+		"cars.dealer_id = 7",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("query is missing %q:\n%s", want, sql)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+type CatalogRepository struct{ DB *gorm.DB }
+
+// ---------- Brands ----------
+
+func (r *CatalogRepository) FindBrands(brands *[]*entities.CatalogBrand) error {
+	return r.DB.Order("name").Find(brands).Error
+}
+
+func (r *CatalogRepository) FindBrandByID(id uint, brand *entities.CatalogBrand) error {
+	return r.DB.First(brand, id).Error
+}
+
+// FindBrandByKey finds a brand whose lower-cased name or alias equals key
+func (r *CatalogRepository) FindBrandByKey(key string, brand *entities.CatalogBrand) error {
+	return r.DB.Where("LOWER(name) = ? OR aliases @> ?::jsonb", key, aliasJSON(key)).First(brand).Error
+}
+
+func (r *CatalogRepository) CreateBrand(brand *entities.CatalogBrand) error {
+	return r.DB.Create(brand).Error
+}
+
+func (r *CatalogRepository) UpdateBrand(brand *entities.CatalogBrand) error {
+	return r.DB.Omit("Models").Save(brand).Error
+}
+
+func (r *CatalogRepository) DeleteBrand(id uint) error {
+	return r.DB.Delete(&entities.CatalogBrand{}, id).Error
+}
+
+// ---------- Models ----------
+
+func (r *CatalogRepository) FindModelsByBrandID(brandID uint, models *[]*entities.CatalogModel) error {
+	return r.DB.Where("brand_id = ?", brandID).Order("name").Find(models).Error
+}
+
+func (r *CatalogRepository) FindModelByID(id uint, model *entities.CatalogModel) error {
+	return r.DB.First(model, id).Error
+}
+
+// FindModelByKey finds a model of the brand whose lower-cased name or alias equals key
+func (r *CatalogRepository) FindModelByKey(brandID uint, key string, model *entities.CatalogModel) error {
+	return r.DB.Where("brand_id = ?", brandID).
+		Where("LOWER(name) = ? OR aliases @> ?::jsonb", key, aliasJSON(key)).
+		First(model).Error
+}
+
+func (r *CatalogRepository) CreateModel(model *entities.CatalogModel) error {
+	return r.DB.Create(model).Error
+}
+
+func (r *CatalogRepository) UpdateModel(model *entities.CatalogModel) error {
+	return r.DB.Omit("Trims").Save(model).Error
+}
+
+func (r *CatalogRepository) DeleteModel(id uint) error {
+	return r.DB.Delete(&entities.CatalogModel{}, id).Error
+}
+
+// ---------- Trims ----------
+
+// FindTrimsByModelID returns the model's trims, only those sold in year when year != 0
+func (r *CatalogRepository) FindTrimsByModelID(modelID uint, year int, trims *[]*entities.CatalogTrim) error {
+	q := r.DB.Where("model_id = ?", modelID)
+	if year != 0 {
+		q = q.Where("year_from <= ? AND (year_to = 0 OR year_to >= ?)", year, year)
+	}
+	return q.Order("year_from DESC, name").Find(trims).Error
+}
+
+func (r *CatalogRepository) FindTrimByID(id uint, trim *entities.CatalogTrim) error {
+	return r.DB.First(trim, id).Error
+}
+
+func (r *CatalogRepository) CreateTrim(trim *entities.CatalogTrim) error {
+	return r.DB.Create(trim).Error
+}
+
+func (r *CatalogRepository) UpdateTrim(trim *entities.CatalogTrim) error {
+	return r.DB.Save(trim).Error
+}
+
+func (r *CatalogRepository) DeleteTrim(id uint) error {
+	return r.DB.Delete(&entities.CatalogTrim{}, id).Error
+}
+
+// CountChildren returns how many models (or trims) reference a catalog entry
+func (r *CatalogRepository) CountChildren(child interface{}, column string, id uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(child).Where(column+" = ?", id).Count(&count).Error
+	return count, err
+}
+
+// CountCars returns how many cars reference a catalog entry (column brand_id, model_id or trim_id)
+func (r *CatalogRepository) CountCars(column string, id uint) (int64, error) {
+	return r.CountChildren(&entities.Car{}, column, id)
+}
+
+// FindSpelling returns the catalog's spelling of a spec value (car_type of models,
+// fuel_type or transmission of trims) whose lower-cased form equals key
+func (r *CatalogRepository) FindSpelling(table interface{}, column, key string) (string, error) {
+	var spellings []string
+	err := r.DB.Model(table).Where("LOWER("+column+") = ?", key).
+		Distinct(column).Order(column).Limit(1).Pluck(column, &spellings).Error
+	if err != nil || len(spellings) == 0 {
+		return "", err
+	}
+	return spellings[0], nil
+}
+
+// aliasJSON is the jsonb array used to test alias membership
+func aliasJSON(key string) string {
+	raw, _ := json.Marshal([]string{key})
+	return string(raw)
+}
@@ -1,36 +1,49 @@
-package repositories
-
-import (
-	"Backend_Go/internal/entities"
-
-	"gorm.io/gorm"
-)
-
-type DealerRepository struct{ DB *gorm.DB }
-
-func (r *DealerRepository) Create(dealer interface{}) error {
-	return r.DB.Create(dealer).Error
-}
-func (r *DealerRepository) FindAll(dealers interface{}) error {
-	return r.DB.Find(dealers).Error
-}
-func (r *DealerRepository) FindByID(id uint, dealer *entities.Dealer) error {
-	return r.DB.
-		Preload("User").
-		First(dealer, id).
-		Error
-}
-func (r *DealerRepository) Update(dealer interface{}) error {
-	return r.DB.Save(dealer).Error
-}
-func (r *DealerRepository) Delete(id uint) error {
-	return r.DB.Delete(&map[string]interface{}{}, id).Error
-}
-
-func (r *DealerRepository) FindByUserID(userID uint, dealer interface{}) error {
-	return r.DB.Where("user_id = ?", userID).First(dealer).Error
-}
-
-func (r *DealerRepository) FindApproved(dealers interface{}) error {
-	return r.DB.Where("status = ? OR (status = '' AND is_approved = ?)", "approved", true).Find(dealers).Error
-}
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+)
+
+type DealerRepository struct{ DB *gorm.DB }
+
+func (r *DealerRepository) Create(dealer interface{}) error {
+	return r.DB.Create(dealer).Error
+}
+func (r *DealerRepository) FindAll(dealers interface{}) error {
+	return r.DB.Find(dealers).Error
+}
+func (r *DealerRepository) FindByID(id uint, dealer *entities.Dealer) error {
+	return r.DB.
+		Preload("User").
+		First(dealer, id).
+		Error
+}
+func (r *DealerRepository) Update(dealer interface{}) error {
+	return r.DB.Save(dealer).Error
+}
+func (r *DealerRepository) Delete(id uint) error {
+	return r.DB.Delete(&map[string]interface{}{}, id).Error
+}
+
+func (r *DealerRepository) FindByUserID(userID uint, dealer interface{}) error {
+	return r.DB.Where("user_id = ?", userID).First(dealer).Error
+}
+
+func (r *DealerRepository) FindApproved(dealers interface{}) error {
+	return r.DB.Where("status = ? OR (status = '' AND is_approved = ?)", "approved", true).Find(dealers).Error
+}
+
+// FindApprovedNear returns approved dealers with a location, nearest first
+func (r *DealerRepository) FindApprovedNear(near GeoPoint, dealers *[]*entities.Dealer) error {
+	distance := distanceExpr(near)
+	q := r.DB.Model(&entities.Dealer{}).
+		Select("dealers.*, ? AS distance_km", distance).
+		Where("status = ? OR (status = '' AND is_approved = ?)", "approved", true).
+		Where("dealers.latitude IS NOT NULL AND dealers.longitude IS NOT NULL")
+	if near.RadiusKm > 0 {
+		q = q.Where("? <= ?", distance, near.RadiusKm)
+	}
+	return q.Order("distance_km ASC").Find(dealers).Error
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+)
+
+type FinanceOfferRepository struct{ DB *gorm.DB }
+
+func (r *FinanceOfferRepository) Create(offer *entities.FinanceOffer) error {
+	return r.DB.Create(offer).Error
+}
+
+func (r *FinanceOfferRepository) Update(offer *entities.FinanceOffer) error {
+	return r.DB.Save(offer).Error
+}
+
+func (r *FinanceOfferRepository) Delete(id uint) error {
+	return r.DB.Delete(&entities.FinanceOffer{}, id).Error
+}
+
+func (r *FinanceOfferRepository) FindByID(id uint, offer *entities.FinanceOffer) error {
+	return r.DB.First(offer, id).Error
+}
+
+func (r *FinanceOfferRepository) FindByDealerID(dealerID uint, offers *[]*entities.FinanceOffer) error {
+	return r.DB.Where("dealer_id = ?", dealerID).Order("created_at DESC").Find(offers).Error
+}
+
+// FindActiveForCar returns the dealer's running offers that apply to the car
+// (dealer-wide offers and offers for that car)
+func (r *FinanceOfferRepository) FindActiveForCar(dealerID, carID uint, offers *[]*entities.FinanceOffer) error {
+	return r.DB.
+		Where("dealer_id = ? AND is_active = ?", dealerID, true).
+		Where("valid_until IS NULL OR valid_until > NOW()").
+		Where("car_id IS NULL OR car_id = ?", carID).
+		Order("car_id NULLS LAST, id").
+		Find(offers).Error
+}
+
+// DeactivateExpired switches off offers past their end date and returns them
+func (r *FinanceOfferRepository) DeactivateExpired() ([]*entities.FinanceOffer, error) {
+	var offers []*entities.FinanceOffer
+	if err := r.DB.Where("is_active = ? AND valid_until <= NOW()", true).Find(&offers).Error; err != nil {
+		return nil, err
+	}
+	for _, o := range offers {
+		if err := r.DB.Model(o).UpdateColumn("is_active", false).Error; err != nil {
+			return nil, err
+		}
+	}
+	return offers, nil
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"time"
+
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository struct{ DB *gorm.DB }
+
+func (r *ImportJobRepository) Create(job *entities.ImportJob) error {
+	return r.DB.Create(job).Error
+}
+
+func (r *ImportJobRepository) Update(job *entities.ImportJob) error {
+	return r.DB.Save(job).Error
+}
+
+func (r *ImportJobRepository) FindByID(id uint, job *entities.ImportJob) error {
+	return r.DB.First(job, id).Error
+}
+
+func (r *ImportJobRepository) FindByDealerID(dealerID uint, jobs *[]*entities.ImportJob) error {
+	return r.DB.Where("dealer_id = ?", dealerID).Order("created_at DESC").Limit(50).Find(jobs).Error
+}
+
+// FailUnfinished marks every queued or running job as failed; their file only
+// lived in memory, so they cannot resume after a restart
+func (r *ImportJobRepository) FailUnfinished(message string) (int64, error) {
+	res := r.DB.Model(&entities.ImportJob{}).
+		Where("status IN ?", []string{entities.ImportQueued, entities.ImportRunning}).
+		Updates(map[string]interface{}{
+			"status":      entities.ImportFailed,
+			"message":     message,
+			"finished_at": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
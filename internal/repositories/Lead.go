@@ -1,15 +1,49 @@
-package repositories
-
-import (
-	"gorm.io/gorm"
-)
-
-type LeadRepository struct{ DB *gorm.DB }
-
-func (r *LeadRepository) Create(lead interface{}) error {
-	return r.DB.Create(lead).Error
-}
-
-func (r *LeadRepository) FindByDealerID(dealerID uint, leads interface{}) error {
-	return r.DB.Where("dealer_id = ?", dealerID).Find(leads).Error
-}
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LeadRepository struct{ DB *gorm.DB }
+
+func (r *LeadRepository) Create(lead interface{}) error {
+	return r.DB.Create(lead).Error
+}
+
+func (r *LeadRepository) FindByDealerID(dealerID uint, leads interface{}) error {
+	return r.DB.Where("dealer_id = ?", dealerID).Find(leads).Error
+}
+
+// FindOrCreate loads the customer's latest lead for the car into lead, or
+// creates lead when there is none yet. It reports whether a lead was created.
+func (r *LeadRepository) FindOrCreate(lead *entities.Lead) (bool, error) {
+	err := r.DB.Where("car_id = ? AND customer_id = ?", lead.CarID, lead.CustomerID).Order("id DESC").First(lead).Error
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	return true, r.DB.Create(lead).Error
+}
+
+// FindLatest returns the customer's latest lead for a car
+func (r *LeadRepository) FindLatest(carID, customerID uint, lead *entities.Lead) error {
+	return r.DB.Where("car_id = ? AND customer_id = ?", carID, customerID).Order("id DESC").First(lead).Error
+}
+
+// CountBySource counts the dealer's leads created in [from, to) per contact method
+func (r *LeadRepository) CountBySource(dealerID uint, from, to time.Time) ([]SourceCount, error) {
+	var counts []SourceCount
+	err := r.DB.Model(&entities.Lead{}).
+		Select("COALESCE(NULLIF(contact_via, ''), 'unknown') AS source, COUNT(*) AS count").
+		Where("dealer_id = ? AND created_at >= ? AND created_at < ?", dealerID, from, to).
+		Group("source").
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct{ DB *gorm.DB }
+
+func (r *NotificationRepository) Create(n *entities.Notification) error {
+	return r.DB.Create(n).Error
+}
+
+func (r *NotificationRepository) FindByUserID(userID uint, unreadOnly bool, limit int, notifications *[]*entities.Notification) error {
+	q := r.DB.Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("is_read = ?", false)
+	}
+	return q.Order("created_at DESC").Limit(limit).Find(notifications).Error
+}
+
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&entities.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one notification (or all when id is 0) of the user as read
+func (r *NotificationRepository) MarkRead(userID, id uint) error {
+	q := r.DB.Model(&entities.Notification{}).Where("user_id = ?", userID)
+	if id != 0 {
+		q = q.Where("id = ?", id)
+	}
+	return q.Update("is_read", true).Error
+}
@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"errors"
+
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReservationRepository struct{ DB *gorm.DB }
+
+// ErrReservationChanged is returned when a reservation was acted on in the meantime
+var ErrReservationChanged = errors.New("the reservation was changed in the meantime, please reload")
+
+var acceptColumns = []string{"status", "deposit_amount", "accepted_at", "expires_at", "updated_at"}
+
+// Create saves a new reservation with its first audit event
+func (r *ReservationRepository) Create(reservation *entities.Reservation, event *entities.ReservationEvent) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(reservation).Error; err != nil {
+			return err
+		}
+		event.ReservationID = reservation.ID
+		return tx.Create(event).Error
+	})
+}
+
+// UpdateStatus saves a status change of a reservation still in event.FromStatus
+// and records it, in one transaction
+func (r *ReservationRepository) UpdateStatus(reservation *entities.Reservation, event *entities.ReservationEvent) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return updateReservationStatus(tx, reservation, event)
+	})
+}
+
+// EndHold closes an accepted reservation (cancelled, released or expired) and
+// puts its car back on sale in one transaction. The car row is locked first, as
+// in Accept; while the car is still reserved, release applies its status change
+// and returns the history entry saved with it. A car that has left the reserved
+// status in the meantime is left alone.
+func (r *ReservationRepository) EndHold(reservation *entities.Reservation, event *entities.ReservationEvent, release func(car *entities.Car) (*entities.CarStatusHistory, error)) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var car entities.Car
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&car, reservation.CarID).Error; err != nil {
+			return err
+		}
+		if err := updateReservationStatus(tx, reservation, event); err != nil {
+			return err
+		}
+		if car.Status != entities.CarStatusReserved {
+			return nil
+		}
+
+		carHistory, err := release(&car)
+		if err != nil {
+			return err
+		}
+		if err := (&CarRepository{DB: tx}).UpdateStatus(&car, carHistory); err != nil {
+			return err
+		}
+		car.Dealer = reservation.Car.Dealer
+		reservation.Car = car
+		return nil
+	})
+}
+
+func updateReservationStatus(tx *gorm.DB, reservation *entities.Reservation, event *entities.ReservationEvent) error {
+	res := tx.Model(reservation).Where("status = ?", event.FromStatus).Select("status", "updated_at").Updates(reservation)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrReservationChanged
+	}
+	event.ReservationID = reservation.ID
+	return tx.Create(event).Error
+}
+
+// Accept holds the car for an accepted reservation in one transaction. The car
+// row is locked and passed to check, which may refuse the hold for the car's
+// current status. The reservation and the car are then updated with their audit
+// entries (carHistory gets the from-status filled in), and the car's other
+// requests are rejected with rejectReason. The rejected requests are returned.
+func (r *ReservationRepository) Accept(reservation *entities.Reservation, event *entities.ReservationEvent, carHistory *entities.CarStatusHistory, check func(car *entities.Car) error, rejectReason string) ([]*entities.Reservation, error) {
+	var rejected []*entities.Reservation
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var car entities.Car
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&car, reservation.CarID).Error; err != nil {
+			return err
+		}
+		if err := check(&car); err != nil {
+			return err
+		}
+
+		res := tx.Model(reservation).Where("status = ?", entities.ReservationRequested).Select(acceptColumns).Updates(reservation)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrReservationChanged
+		}
+		event.ReservationID = reservation.ID
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+
+		// The listing must outlive the hold, or a reserved car would drop out of
+		// the listings when it expires (the expiry job only handles public cars)
+		carHistory.FromStatus = car.Status
+		car.Status, car.ReservedUntil = entities.CarStatusReserved, reservation.ExpiresAt
+		if car.ExpiresAt != nil && car.ExpiresAt.Before(*reservation.ExpiresAt) {
+			car.ExpiresAt, car.ExpiryWarnedAt = reservation.ExpiresAt, nil
+		}
+		if err := (&CarRepository{DB: tx}).UpdateStatus(&car, carHistory); err != nil {
+			return err
+		}
+
+		if err := tx.Where("car_id = ? AND status = ? AND id <> ?", car.ID, entities.ReservationRequested, reservation.ID).
+			Find(&rejected).Error; err != nil {
+			return err
+		}
+		for _, other := range rejected {
+			other.Status = entities.ReservationRejected
+			if err := tx.Model(other).Select("status", "updated_at").Updates(other).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&entities.ReservationEvent{
+				ReservationID: other.ID,
+				FromStatus:    entities.ReservationRequested,
+				ToStatus:      entities.ReservationRejected,
+				ActorID:       event.ActorID,
+				ActorRole:     event.ActorRole,
+				Reason:        rejectReason,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		car.Dealer = reservation.Car.Dealer
+		reservation.Car = car
+		return nil
+	})
+	return rejected, err
+}
+
+// FindByID loads a reservation with its car, customer and audit trail
+func (r *ReservationRepository) FindByID(id uint, reservation *entities.Reservation) error {
+	return r.DB.Preload("Car").Preload("Car.Dealer").Preload("Customer").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC, id ASC") }).
+		First(reservation, id).Error
+}
+
+func (r *ReservationRepository) FindByCustomerID(customerID uint, reservations *[]*entities.Reservation) error {
+	return r.DB.Preload("Car").
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Find(reservations).Error
+}
+
+// FindByDealerID returns the dealer's reservations, optionally in one status
+func (r *ReservationRepository) FindByDealerID(dealerID uint, status string, reservations *[]*entities.Reservation) error {
+	q := r.DB.Preload("Car").Preload("Customer").Where("dealer_id = ?", dealerID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	return q.Order("created_at DESC").Find(reservations).Error
+}
+
+// HasOpen reports whether the customer already has a requested or accepted reservation on the car
+func (r *ReservationRepository) HasOpen(carID, customerID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&entities.Reservation{}).
+		Where("car_id = ? AND customer_id = ? AND status IN ?", carID, customerID, entities.OpenReservationStatuses).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindAccepted returns the reservation currently holding the car
+func (r *ReservationRepository) FindAccepted(carID uint, reservation *entities.Reservation) error {
+	return r.DB.Where("car_id = ? AND status = ?", carID, entities.ReservationAccepted).
+		Order("id DESC").
+		First(reservation).Error
+}
+
+// FindExpired returns accepted reservations whose hold has ended
+func (r *ReservationRepository) FindExpired(reservations *[]*entities.Reservation) error {
+	return r.DB.Preload("Car").Preload("Car.Dealer").
+		Where("status = ? AND expires_at <= NOW()", entities.ReservationAccepted).
+		Find(reservations).Error
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SaleRepository struct{ DB *gorm.DB }
+
+// SaleTotals aggregates a dealer's sales over a period
+type SaleTotals struct {
+	Count           int64   `json:"count"`
+	Revenue         float64 `json:"revenue"`
+	AvgPrice        float64 `json:"avg_price"`
+	AvgDaysOnMarket float64 `json:"avg_days_on_market"`
+	// Average discount from the asking price, in percent
+	AvgDiscountPercent float64 `json:"avg_discount_percent"`
+}
+
+// SourceCount is the number of sales or leads from one lead source
+type SourceCount struct {
+	Source string `json:"source"`
+	Count  int64  `json:"count"`
+}
+
+func (r *SaleRepository) Create(sale *entities.Sale) error {
+	return r.DB.Omit(clause.Associations).Create(sale).Error
+}
+
+func (r *SaleRepository) Update(sale *entities.Sale) error {
+	return r.DB.Omit(clause.Associations).Save(sale).Error
+}
+
+// Delete soft-deletes a sale (undone sales stay in the table for auditing)
+func (r *SaleRepository) Delete(id uint) error {
+	return r.DB.Delete(&entities.Sale{}, id).Error
+}
+
+func (r *SaleRepository) FindByID(id uint, sale *entities.Sale) error {
+	return r.DB.Preload("Car").Preload("Car.Dealer").First(sale, id).Error
+}
+
+// FindActiveByCarID returns the sale of a car that is currently sold
+func (r *SaleRepository) FindActiveByCarID(carID uint, sale *entities.Sale) error {
+	return r.DB.Where("car_id = ?", carID).Order("id DESC").First(sale).Error
+}
+
+// FindByDealerID returns the dealer's sales made in [from, to), latest first
+func (r *SaleRepository) FindByDealerID(dealerID uint, from, to time.Time, sales *[]*entities.Sale) error {
+	return r.DB.Preload("Car").
+		Where("dealer_id = ? AND sold_at >= ? AND sold_at < ?", dealerID, from, to).
+		Order("sold_at DESC").
+		Find(sales).Error
+}
+
+// FindUnreviewed returns a sale of the dealer to the buyer that no review is linked to yet
+func (r *SaleRepository) FindUnreviewed(buyerID, dealerID uint, sale *entities.Sale) error {
+	reviewed := r.DB.Model(&entities.Review{}).Select("sale_id").Where("sale_id IS NOT NULL")
+	return r.DB.Where("buyer_id = ? AND dealer_id = ? AND id NOT IN (?)", buyerID, dealerID, reviewed).
+		Order("sold_at DESC").
+		First(sale).Error
+}
+
+// Totals aggregates the dealer's sales made in [from, to)
+func (r *SaleRepository) Totals(dealerID uint, from, to time.Time) (*SaleTotals, error) {
+	var totals SaleTotals
+	err := r.DB.Model(&entities.Sale{}).
+		Select(`COUNT(*) AS count,
+			COALESCE(SUM(final_price), 0) AS revenue,
+			COALESCE(AVG(final_price), 0) AS avg_price,
+			COALESCE(AVG(days_on_market), 0) AS avg_days_on_market,
+			COALESCE(AVG((list_price - final_price) / NULLIF(list_price, 0) * 100), 0) AS avg_discount_percent`).
+		Where("dealer_id = ? AND sold_at >= ? AND sold_at < ?", dealerID, from, to).
+		Scan(&totals).Error
+	return &totals, err
+}
+
+// CountBySource counts the dealer's sales made in [from, to) per lead source
+func (r *SaleRepository) CountBySource(dealerID uint, from, to time.Time) ([]SourceCount, error) {
+	var counts []SourceCount
+	err := r.DB.Model(&entities.Sale{}).
+		Select("COALESCE(NULLIF(lead_source, ''), 'unknown') AS source, COUNT(*) AS count").
+		Where("dealer_id = ? AND sold_at >= ? AND sold_at < ?", dealerID, from, to).
+		Group("source").
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"Backend_Go/internal/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SavedSearchRepository struct{ DB *gorm.DB }
+
+func (r *SavedSearchRepository) Create(s *entities.SavedSearch) error {
+	return r.DB.Create(s).Error
+}
+
+func (r *SavedSearchRepository) Update(s *entities.SavedSearch) error {
+	return r.DB.Save(s).Error
+}
+
+func (r *SavedSearchRepository) Delete(id uint) error {
+	return r.DB.Delete(&entities.SavedSearch{}, id).Error
+}
+
+func (r *SavedSearchRepository) FindByID(id uint, s *entities.SavedSearch) error {
+	return r.DB.First(s, id).Error
+}
+
+func (r *SavedSearchRepository) FindByUserID(userID uint, searches *[]*entities.SavedSearch) error {
+	return r.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(searches).Error
+}
+
+func (r *SavedSearchRepository) CountByUserID(userID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&entities.SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// candidates selects the active saved searches of other users whose brand, year,
+// price, mileage and province filters allow the car. The remaining filters are
+// checked in Go by the caller.
+func (r *SavedSearchRepository) candidates(car *entities.Car) *gorm.DB {
+	return r.DB.Model(&entities.SavedSearch{}).
+		Where("is_active = ? AND user_id <> ?", true, car.Dealer.UserID).
+		Where("filters->'brands' IS NULL OR EXISTS (SELECT 1 FROM jsonb_array_elements_text(filters->'brands') AS b WHERE LOWER(b) = LOWER(?))", car.Brand).
+		Where("filters->>'year_min' IS NULL OR (filters->>'year_min')::int <= ?", car.Year).
+		Where("filters->>'year_max' IS NULL OR (filters->>'year_max')::int >= ?", car.Year).
+		Where("filters->>'price_min' IS NULL OR (filters->>'price_min')::numeric <= ?", car.Price).
+		Where("filters->>'price_max' IS NULL OR (filters->>'price_max')::numeric >= ?", car.Price).
+		Where("filters->>'mileage_max' IS NULL OR (filters->>'mileage_max')::int >= ?", car.Mileage).
+		Where("filters->>'province' IS NULL OR filters->>'province' = ?", car.Dealer.Province)
+}
+
+// EachCandidate calls fn, in batches, for every active saved search that may match the car
+func (r *SavedSearchRepository) EachCandidate(car *entities.Car, fn func(s *entities.SavedSearch) error) error {
+	var batch []*entities.SavedSearch
+	return r.candidates(car).
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			for _, s := range batch {
+				if err := fn(s); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// CreateMatch records a match once; it reports false if the car already matched this search
+func (r *SavedSearchRepository) CreateMatch(m *entities.SavedSearchMatch) (bool, error) {
+	res := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(m)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *SavedSearchRepository) MarkMatchesNotified(ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DB.Model(&entities.SavedSearchMatch{}).Where("id IN ?", ids).Update("notified_at", at).Error
+}
+
+// FindPendingDigestMatches returns undelivered matches of active daily searches
+// that were last notified before the given time
+func (r *SavedSearchRepository) FindPendingDigestMatches(notifiedBefore time.Time, matches *[]*entities.SavedSearchMatch) error {
+	return r.DB.
+		Joins("JOIN saved_searches ON saved_searches.id = saved_search_matches.saved_search_id AND saved_searches.deleted_at IS NULL").
+		Where("saved_search_matches.notified_at IS NULL").
+		Where("saved_searches.is_active = ? AND saved_searches.frequency = ?", true, "daily").
+		Where("saved_searches.last_notified_at IS NULL OR saved_searches.last_notified_at < ?", notifiedBefore).
+		Order("saved_search_matches.created_at ASC").
+		Find(matches).Error
+}
+
+func (r *SavedSearchRepository) SetLastNotified(id uint, at time.Time) error {
+	return r.DB.Model(&entities.SavedSearch{}).Where("id = ?", id).Update("last_notified_at", at).Error
+}
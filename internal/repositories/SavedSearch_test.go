@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"Backend_Go/internal/entities"
+
+	"gorm.io/gorm"
+)
+
+func TestSavedSearchCandidatesFilterInSQL(t *testing.T) {
+	repo := &SavedSearchRepository{DB: dryRunDB(t)}
+	car := &entities.Car{Brand: "Honda", Year: 2020, Price: 650000, Mileage: 40000,
+		Dealer: entities.Dealer{UserID: 7, Province: "เชียงใหม่"}}
+
+	sql := repo.DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var searches []*entities.SavedSearch
+		return (&SavedSearchRepository{DB: tx}).candidates(car).Find(&searches)
+	})
+	for _, want := range []string{
+		"is_active = true AND user_id <> 7",
+		"LOWER(b) = LOWER('Honda')",
+		"(filters->>'year_min')::int <= 2020",
+		"(filters->>'year_max')::int >= 2020",
+		"(filters->>'price_min')::numeric <= 650000",
+		"(filters->>'price_max')::numeric >= 650000",
+		"(filters->>'mileage_max')::int >= 40000",
+		"filters->>'province' = 'เชียงใหม่'",
+		`"saved_searches"."deleted_at" IS NULL`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("query is missing %q:\n%s", want, sql)
+		}
+	}
+}
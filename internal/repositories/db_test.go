@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dryRunDB builds SQL without a database server
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// captureQueries collects the SQL of every select, update and insert run on the dry-run db
+func captureQueries(t *testing.T, db *gorm.DB) *[]string {
+	t.Helper()
+	var queries []string
+	capture := func(tx *gorm.DB) {
+		queries = append(queries, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}
+	for _, err := range []error{
+		db.Callback().Query().After("gorm:query").Register("test:capture", capture),
+		db.Callback().Update().After("gorm:update").Register("test:capture", capture),
+		db.Callback().Create().After("gorm:create").Register("test:capture", capture),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &queries
+}
+
+// mockDB returns a postgres gorm DB backed by sqlmock, for code whose result
+// depends on what the database returns. Every expected statement must run.
+func mockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	return db, mock
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import "gorm.io/gorm/clause"
+
+// GeoPoint is a "near" search origin with an optional radius (0 = unlimited)
+type GeoPoint struct {
+	Lat      float64
+	Lon      float64
+	RadiusKm float64
+}
+
+// distanceExpr is the great-circle distance in km from p to the dealers row (haversine)
+func distanceExpr(p GeoPoint) clause.Expr {
+	return clause.Expr{
+		SQL: "(6371 * 2 * ASIN(SQRT(POWER(SIN(RADIANS(dealers.latitude - ?) / 2), 2) + " +
+			"COS(RADIANS(?)) * COS(RADIANS(dealers.latitude)) * POWER(SIN(RADIANS(dealers.longitude - ?) / 2), 2))))",
+		Vars: []interface{}{p.Lat, p.Lat, p.Lon},
+	}
+}
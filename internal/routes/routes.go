@@ -1,126 +1,256 @@
-package routes
-
-import (
-	"Backend_Go/internal/controller/deliveries/http"
-	"Backend_Go/internal/middleware"
-	"Backend_Go/internal/repositories"
-
-	"github.com/gofiber/fiber/v2"
-	websocket "github.com/gofiber/websocket/v2"
-)
-
-// SetupRoutes รวม route ทั้งระบบ
-// Updated for Production Security & Role Separation
-// SetupRoutes รวม route ทั้งระบบ
-// Updated for Production Security & Role Separation
-func SetupRoutes(app *fiber.App,
-	carHandler *http.CarHandler,
-	leadHandler *http.LeadHandler,
-	favoriteHandler *http.FavoriteHandler,
-	reviewHandler *http.ReviewHandler,
-	userHandler *http.UserHandler,
-	dealerHandler *http.DealerHandler,
-	carImageHandler *http.CarImageHandler,
-	adminHandler *http.AdminHandler,
-	authHandler *http.AuthHandler,
-	chatHandler *http.ChatHandler, // New
-	dealerRepo *repositories.DealerRepository,
-) {
-	// ... (Previous middleware setup) ...
-
-	api := app.Group("/api")
-
-	// ... (Auth routes) ...
-	// ==================== PUBLIC ====================
-	auth := api.Group("/auth")
-	auth.Post("/register", authHandler.Register)
-	auth.Post("/register-dealer", authHandler.RegisterDealer)
-	auth.Post("/login", authHandler.Login)
-	auth.Post("/refresh", authHandler.Refresh)
-	auth.Post("/logout", authHandler.Logout)
-
-	// Public Resources (Read Only)
-	api.Get("/cars", carHandler.GetCars)
-	api.Get("/cars/:id", carHandler.GetCarDetail)
-	api.Get("/cars/:id/images", carImageHandler.GetImages)
-
-	api.Get("/dealers", dealerHandler.GetDealers)
-	api.Get("/dealers/:id", dealerHandler.GetDealer)
-	api.Get("/dealers/:id/stats", dealerHandler.GetDealerStats)
-	api.Get("/dealers/:id/reviews", reviewHandler.GetReviewsByDealer)
-
-	api.Post("/cars/:id/contact", middleware.RequireAuth(), carHandler.RecordContact)
-
-	// ==================== USER (Protected) ====================
-	// Users can access their own profile and favorites
-	users := api.Group("/users", middleware.RequireAuth())
-
-	users.Get("/me", userHandler.GetMe)
-	users.Put("/me", userHandler.UpdateMe)
-
-	users.Get("/:id", userHandler.GetUser)
-
-	// Favorites (Aligned with request)
-	favorites := api.Group("/favorites", middleware.RequireAuth())
-	favorites.Get("/", favoriteHandler.GetMyFavorites)
-	favorites.Post("/:car_id", favoriteHandler.AddFavoriteMe)
-	favorites.Delete("/:car_id", favoriteHandler.RemoveFavoriteMe)
-
-	// Reviews (User writes review)
-	api.Post("/reviews", middleware.RequireAuth(), reviewHandler.CreateReview)
-
-	// ==================== CHAT (Protected) ====================
-	chat := api.Group("/chat", middleware.RequireAuth())
-	chat.Post("/send", chatHandler.SendMessage)
-	chat.Post("/reply/:id", chatHandler.Reply)
-	chat.Get("/conversations", chatHandler.GetConversations)
-	chat.Get("/conversations/:id/messages", chatHandler.GetMessages)
-	chat.Get("/unread-count", chatHandler.GetUnreadCount)
-
-	// WebSocket Upgrade
-	app.Get("/ws", websocket.New(chatHandler.WebSocketUpgrade))
-
-	// ==================== DEALER (Protected) ====================
-	// Must be a dealer role AND have an approved dealer profile
-	dealer := api.Group("/dealer", middleware.RequireRole("dealer"), middleware.RequireActiveDealer(dealerRepo))
-
-	dealer.Get("/me", dealerHandler.GetMyDealer)
-	dealer.Get("/cars", dealerHandler.GetMyCars)
-	dealer.Get("/leads", dealerHandler.GetMyLeads)
-
-	// Secure Dealer Actions
-	api.Post("/cars", middleware.RequireRole("dealer"), middleware.RequireActiveDealer(dealerRepo), carHandler.CreateCar)
-
-	dealerCars := api.Group("/cars", middleware.RequireRole("dealer"), middleware.RequireActiveDealer(dealerRepo))
-	dealerCars.Put("/:id", carHandler.UpdateCar)
-	dealerCars.Delete("/:id", carHandler.DeleteCar)
-	dealerCars.Patch("/:id/status", carHandler.SetStatus)
-	dealerCars.Patch("/:id/sold", carHandler.SetSold)
-	dealerCars.Patch("/:id/unpublish", carHandler.SetUnpublish)
-	dealerCars.Post("/:id/promote", carHandler.PromoteCar)
-	dealerCars.Post("/:id/images", carImageHandler.AddImages)
-
-	adminMiddleware := middleware.AdminOnly(adminHandler.Usecase)
-	admin := api.Group("/admin", middleware.RequireAuth(), adminMiddleware)
-
-	admin.Get("/users", adminHandler.GetUsers)
-	admin.Get("/dealers", adminHandler.GetDealers)
-	admin.Get("/reports", adminHandler.GetReports)
-
-	admin.Post("/dealers/:id/approve", adminHandler.ApproveDealer)
-	admin.Patch("/dealers/:id/suspend", adminHandler.SuspendDealer)
-	admin.Post("/dealers/:id/reject", adminHandler.RejectDealer)
-
-	admin.Get("/cars", adminHandler.GetCars)
-
-	admin.Post("/cars/:id/approve", adminHandler.ApproveCar)
-	admin.Post("/cars/:id/reject", adminHandler.RejectCar)
-	admin.Post("/cars/:id/hide", adminHandler.HideCar)
-	admin.Post("/cars/:id/flag", adminHandler.FlagCar)
-	admin.Delete("/cars/:id", adminHandler.DeleteCar)
-
-	admin.Patch("/users/:id/ban", adminHandler.BanUser)
-	admin.Patch("/users/:id/unban", adminHandler.UnbanUser)
-
-	api.Delete("/users/:id/favorites/:car_id", middleware.RequireAuth(), favoriteHandler.RemoveFavorite)
-}
+package routes
+
+import (
+	"Backend_Go/internal/controller/deliveries/http"
+	"Backend_Go/internal/middleware"
+	"Backend_Go/internal/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	websocket "github.com/gofiber/websocket/v2"
+)
+
+// SetupRoutes รวม route ทั้งระบบ
+// Updated for Production Security & Role Separation
+// SetupRoutes รวม route ทั้งระบบ
+// Updated for Production Security & Role Separation
+func SetupRoutes(app *fiber.App,
+	carHandler *http.CarHandler,
+	leadHandler *http.LeadHandler,
+	favoriteHandler *http.FavoriteHandler,
+	reviewHandler *http.ReviewHandler,
+	userHandler *http.UserHandler,
+	dealerHandler *http.DealerHandler,
+	carImageHandler *http.CarImageHandler,
+	adminHandler *http.AdminHandler,
+	authHandler *http.AuthHandler,
+	chatHandler *http.ChatHandler, // New
+	savedSearchHandler *http.SavedSearchHandler,
+	notificationHandler *http.NotificationHandler,
+	inventoryHandler *http.InventoryHandler,
+	feedHandler *http.FeedHandler,
+	catalogHandler *http.CatalogHandler,
+	valuationHandler *http.ValuationHandler,
+	financeHandler *http.FinanceHandler,
+	appointmentHandler *http.AppointmentHandler,
+	reservationHandler *http.ReservationHandler,
+	saleHandler *http.SaleHandler,
+	dealerRepo *repositories.DealerRepository,
+	carRepo *repositories.CarRepository,
+	carImageRepo *repositories.CarImageRepository,
+) {
+	// ... (Previous middleware setup) ...
+
+	api := app.Group("/api")
+
+	// ... (Auth routes) ...
+	// ==================== PUBLIC ====================
+	auth := api.Group("/auth")
+	auth.Post("/register", authHandler.Register)
+	auth.Post("/register-dealer", authHandler.RegisterDealer)
+	auth.Post("/login", authHandler.Login)
+	auth.Post("/refresh", authHandler.Refresh)
+	auth.Post("/logout", authHandler.Logout)
+
+	// Public Resources (Read Only)
+	api.Get("/cars", carHandler.GetCars)
+	api.Get("/cars/compare", carHandler.CompareCars)
+	api.Get("/cars/:id", middleware.OptionalAuth(), carHandler.GetCarDetail)
+	api.Get("/cars/:id/images", middleware.OptionalAuth(), carImageHandler.GetImages)
+	api.Get("/cars/:id/price-history", middleware.OptionalAuth(), carHandler.GetPriceHistory)
+	api.Get("/cars/:id/similar", middleware.OptionalAuth(), carHandler.GetSimilarCars)
+	api.Get("/cars/:id/valuation", valuationHandler.EstimateCar)
+	api.Get("/cars/:id/finance", financeHandler.GetCarFinance)
+
+	// Market price estimate from comparable listings
+	api.Get("/valuation", valuationHandler.Estimate)
+
+	// Hire-purchase installment calculator
+	api.Get("/finance/calculate", financeHandler.Calculate)
+
+	api.Get("/dealers", dealerHandler.GetDealers)
+	api.Get("/dealers/:id", dealerHandler.GetDealer)
+	api.Get("/dealers/:id/stats", dealerHandler.GetDealerStats)
+	api.Get("/dealers/:id/reviews", reviewHandler.GetReviewsByDealer)
+	api.Get("/dealers/:id/slots", appointmentHandler.GetSlots)
+
+	// Syndication feeds for partner sites (cacheable, ?updated_since= for increments)
+	api.Get("/feeds/cars.json", feedHandler.GetCarsJSON)
+	api.Get("/feeds/cars.xml", feedHandler.GetCarsXML)
+	api.Get("/dealers/:id/feed.json", feedHandler.GetDealerJSON)
+	api.Get("/dealers/:id/feed.xml", feedHandler.GetDealerXML)
+
+	// Vehicle catalog lookup for brand/model/trim dropdowns
+	api.Get("/catalog/brands", catalogHandler.GetBrands)
+	api.Get("/catalog/brands/:id/models", catalogHandler.GetModels)
+	api.Get("/catalog/models/:id/trims", catalogHandler.GetTrims)
+
+	api.Post("/cars/:id/contact", middleware.RequireAuth(), carHandler.RecordContact)
+
+	// ==================== USER (Protected) ====================
+	// Users can access their own profile and favorites
+	users := api.Group("/users", middleware.RequireAuth())
+
+	users.Get("/me", userHandler.GetMe)
+	users.Put("/me", userHandler.UpdateMe)
+
+	// Saved searches with new-listing alerts
+	users.Get("/me/saved-searches", savedSearchHandler.GetMySavedSearches)
+	users.Post("/me/saved-searches", savedSearchHandler.CreateSavedSearch)
+	users.Put("/me/saved-searches/:id", savedSearchHandler.UpdateSavedSearch)
+	users.Delete("/me/saved-searches/:id", savedSearchHandler.DeleteSavedSearch)
+
+	// In-app notifications
+	users.Get("/me/notifications", notificationHandler.GetMyNotifications)
+	users.Get("/me/notifications/unread-count", notificationHandler.GetUnreadCount)
+	users.Patch("/me/notifications/read-all", notificationHandler.MarkAllRead)
+	users.Patch("/me/notifications/:id/read", notificationHandler.MarkRead)
+
+	users.Get("/:id", userHandler.GetUser)
+
+	// Favorites (Aligned with request)
+	favorites := api.Group("/favorites", middleware.RequireAuth())
+	favorites.Get("/", favoriteHandler.GetMyFavorites)
+	favorites.Post("/:car_id", favoriteHandler.AddFavoriteMe)
+	favorites.Delete("/:car_id", favoriteHandler.RemoveFavoriteMe)
+
+	// Test-drive / showroom appointments
+	appointments := api.Group("/appointments", middleware.RequireAuth())
+	appointments.Post("/", appointmentHandler.Book)
+	appointments.Get("/", appointmentHandler.GetMyAppointments)
+	appointments.Post("/:id/reschedule", appointmentHandler.RescheduleMine)
+	appointments.Post("/:id/cancel", appointmentHandler.CancelMine)
+
+	// Reservations with a deposit hold
+	reservations := api.Group("/reservations", middleware.RequireAuth())
+	reservations.Post("/", reservationHandler.Request)
+	reservations.Get("/", reservationHandler.GetMyReservations)
+	reservations.Get("/:id", reservationHandler.GetMine)
+	reservations.Post("/:id/cancel", reservationHandler.Cancel)
+
+	// Reviews (User writes review)
+	api.Post("/reviews", middleware.RequireAuth(), reviewHandler.CreateReview)
+
+	// ==================== CHAT (Protected) ====================
+	chat := api.Group("/chat", middleware.RequireAuth())
+	chat.Post("/send", chatHandler.SendMessage)
+	chat.Post("/reply/:id", chatHandler.Reply)
+	chat.Get("/conversations", chatHandler.GetConversations)
+	chat.Get("/conversations/:id/messages", chatHandler.GetMessages)
+	chat.Get("/unread-count", chatHandler.GetUnreadCount)
+
+	// WebSocket Upgrade
+	app.Get("/ws", websocket.New(chatHandler.WebSocketUpgrade))
+
+	// ==================== DEALER (Protected) ====================
+	// Every dealer car/image route must target the caller's own car
+	carOwner := middleware.RequireCarOwner(carRepo)
+	imageOwner := middleware.RequireCarImageOwner(carImageRepo, carRepo)
+
+	// Must be a dealer role AND have an approved dealer profile
+	dealer := api.Group("/dealer", middleware.RequireRole("dealer"), middleware.RequireActiveDealer(dealerRepo))
+
+	dealer.Get("/me", dealerHandler.GetMyDealer)
+	dealer.Get("/cars", dealerHandler.GetMyCars)
+	dealer.Get("/leads", dealerHandler.GetMyLeads)
+	dealer.Get("/cars/expired", carHandler.GetExpiredCars)
+	dealer.Get("/cars/:id/stats", carOwner, carHandler.GetCarStats)
+
+	// Bulk inventory import (background job, poll by id)
+	dealer.Post("/inventory/import", inventoryHandler.ImportInventory)
+	dealer.Get("/inventory/imports", inventoryHandler.GetImportJobs)
+	dealer.Get("/inventory/import/:id", inventoryHandler.GetImportJob)
+	dealer.Get("/inventory/export", inventoryHandler.ExportInventory)
+
+	// Appointment availability and bookings
+	dealer.Get("/availability", appointmentHandler.GetAvailability)
+	dealer.Put("/availability", appointmentHandler.SetAvailability)
+	dealer.Get("/blackouts", appointmentHandler.GetBlackouts)
+	dealer.Post("/blackouts", appointmentHandler.AddBlackout)
+	dealer.Delete("/blackouts/:id", appointmentHandler.DeleteBlackout)
+	dealer.Get("/appointments", appointmentHandler.GetDealerAppointments)
+	dealer.Post("/appointments/:id/confirm", appointmentHandler.Confirm)
+	dealer.Post("/appointments/:id/reschedule", appointmentHandler.RescheduleDealer)
+	dealer.Post("/appointments/:id/cancel", appointmentHandler.CancelDealer)
+	dealer.Post("/appointments/:id/complete", appointmentHandler.Complete)
+
+	// Reservations
+	dealer.Get("/reservations", reservationHandler.GetDealerReservations)
+	dealer.Get("/reservations/:id", reservationHandler.GetDealer)
+	dealer.Post("/reservations/:id/accept", reservationHandler.Accept)
+	dealer.Post("/reservations/:id/reject", reservationHandler.Reject)
+	dealer.Post("/reservations/:id/release", reservationHandler.Release)
+	dealer.Post("/reservations/:id/complete", reservationHandler.Complete)
+
+	// Sales records and conversion stats
+	dealer.Get("/sales", saleHandler.GetSales)
+	dealer.Get("/sales/stats", saleHandler.GetSalesStats)
+	dealer.Put("/sales/:id", saleHandler.CorrectSale)
+	dealer.Delete("/sales/:id", saleHandler.UndoSale)
+
+	// Finance offers (dealer-wide or per car, shown as "from ฿X/month")
+	dealer.Get("/finance-offers", financeHandler.GetMyOffers)
+	dealer.Post("/finance-offers", financeHandler.CreateOffer)
+	dealer.Put("/finance-offers/:id", financeHandler.UpdateOffer)
+	dealer.Delete("/finance-offers/:id", financeHandler.DeleteOffer)
+
+	// Secure Dealer Actions
+	api.Post("/cars", middleware.RequireRole("dealer"), middleware.RequireActiveDealer(dealerRepo), carHandler.CreateCar)
+
+	dealerCars := api.Group("/cars", middleware.RequireRole("dealer"), middleware.RequireActiveDealer(dealerRepo))
+	dealerCars.Put("/:id", carOwner, carHandler.UpdateCar)
+	dealerCars.Patch("/:id", carOwner, carHandler.UpdateCar)
+	dealerCars.Delete("/:id", carOwner, carHandler.DeleteCar)
+	dealerCars.Patch("/:id/status", carOwner, carHandler.SetStatus)
+	dealerCars.Get("/:id/status-history", carOwner, carHandler.GetStatusHistory)
+	dealerCars.Patch("/:id/sold", carOwner, carHandler.SetSold)
+	dealerCars.Patch("/:id/unpublish", carOwner, carHandler.SetUnpublish)
+	dealerCars.Post("/:id/promote", carOwner, carHandler.PromoteCar)
+	dealerCars.Post("/:id/renew", carOwner, carHandler.RenewCar)
+	dealerCars.Post("/:id/schedule", carOwner, carHandler.SchedulePublish)
+	dealerCars.Post("/:id/images", carOwner, carImageHandler.AddImages)
+	dealerCars.Delete("/:id/images", carOwner, carImageHandler.DeleteImages)
+
+	dealerImages := api.Group("/images", middleware.RequireRole("dealer"), middleware.RequireActiveDealer(dealerRepo))
+	dealerImages.Put("/:id", imageOwner, carImageHandler.UpdateImage)
+	dealerImages.Delete("/:id", imageOwner, carImageHandler.DeleteImage)
+
+	adminMiddleware := middleware.AdminOnly(adminHandler.Usecase)
+	admin := api.Group("/admin", middleware.RequireAuth(), adminMiddleware)
+
+	admin.Get("/users", adminHandler.GetUsers)
+	admin.Get("/dealers", adminHandler.GetDealers)
+	admin.Get("/reports", adminHandler.GetReports)
+
+	admin.Post("/dealers/:id/approve", adminHandler.ApproveDealer)
+	admin.Patch("/dealers/:id/suspend", adminHandler.SuspendDealer)
+	admin.Post("/dealers/:id/reject", adminHandler.RejectDealer)
+
+	admin.Get("/cars", adminHandler.GetCars)
+
+	admin.Post("/cars/:id/approve", adminHandler.ApproveCar)
+	admin.Post("/cars/:id/reject", adminHandler.RejectCar)
+	admin.Post("/cars/:id/unpublish", adminHandler.UnpublishCar)
+	admin.Get("/cars/:id/status-history", adminHandler.GetCarStatusHistory)
+	admin.Get("/cars/:id/revisions", adminHandler.GetCarRevisions)
+	admin.Post("/cars/:id/revisions/:revisionId/approve", adminHandler.ApproveCarRevision)
+	admin.Post("/cars/:id/revisions/:revisionId/reject", adminHandler.RejectCarRevision)
+	admin.Post("/cars/:id/hide", adminHandler.HideCar)
+	admin.Post("/cars/:id/flag", adminHandler.FlagCar)
+	admin.Delete("/cars/:id", adminHandler.DeleteCar)
+
+	// Vehicle catalog
+	admin.Post("/catalog/brands", catalogHandler.CreateBrand)
+	admin.Put("/catalog/brands/:id", catalogHandler.UpdateBrand)
+	admin.Delete("/catalog/brands/:id", catalogHandler.DeleteBrand)
+	admin.Post("/catalog/brands/:id/models", catalogHandler.CreateModel)
+	admin.Put("/catalog/models/:id", catalogHandler.UpdateModel)
+	admin.Delete("/catalog/models/:id", catalogHandler.DeleteModel)
+	admin.Post("/catalog/models/:id/trims", catalogHandler.CreateTrim)
+	admin.Put("/catalog/trims/:id", catalogHandler.UpdateTrim)
+	admin.Delete("/catalog/trims/:id", catalogHandler.DeleteTrim)
+
+	admin.Patch("/users/:id/ban", adminHandler.BanUser)
+	admin.Patch("/users/:id/unban", adminHandler.UnbanUser)
+
+	api.Delete("/users/:id/favorites/:car_id", middleware.RequireAuth(), favoriteHandler.RemoveFavorite)
+}
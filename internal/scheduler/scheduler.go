@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+)
+
+// Every runs fn once at start and then every interval, in its own goroutine.
+// Jobs keep their state in the database, so a restart simply picks up where they left off.
+func Every(name string, interval time.Duration, fn func() error) {
+	go func() {
+		run(name, fn)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			run(name, fn)
+		}
+	}()
+}
+
+func run(name string, fn func() error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s panicked: %v", name, r)
+		}
+	}()
+	if err := fn(); err != nil {
+		log.Printf("job %s failed: %v", name, err)
+	}
+}
@@ -0,0 +1,202 @@
+package search
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+//go:embed dict_th.txt
+var thaiDictionary string
+
+// trieNode is one rune step of the dictionary trie
+type trieNode struct {
+	children  map[rune]*trieNode
+	word      bool
+	canonical string
+}
+
+var dictionary = buildTrie(thaiDictionary)
+
+// buildTrie loads "word" or "word=canonical" lines into a trie
+func buildTrie(src string) *trieNode {
+	root := &trieNode{children: map[rune]*trieNode{}}
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		word, canonical, _ := strings.Cut(line, "=")
+		word = strings.TrimSpace(word)
+		canonical = strings.TrimSpace(canonical)
+
+		node := root
+		for _, r := range word {
+			next, ok := node.children[r]
+			if !ok {
+				next = &trieNode{children: map[rune]*trieNode{}}
+				node.children[r] = next
+			}
+			node = next
+		}
+		node.word = true
+		node.canonical = canonical
+	}
+	return root
+}
+
+// Tokenize splits text into lowercase search tokens.
+// Latin letters and digits are split on anything else (hyphenated model names
+// like "CR-V" are joined); runs of Thai script,
+// which has no spaces between words, are segmented against the dictionary.
+// Dictionary words with a canonical form (e.g. ฮอนด้า -> honda) are replaced by it.
+func Tokenize(text string) []string {
+	var tokens []string
+	runes := []rune(strings.ToLower(text))
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isThai(r):
+			j := i
+			for j < len(runes) && isThai(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, segmentThai(runes[i:j])...)
+			i = j
+		case isWordRune(r):
+			var word []rune
+			j := i
+			for j < len(runes) {
+				if isWordRune(runes[j]) {
+					word = append(word, runes[j])
+					j++
+					continue
+				}
+				// join "cr-v", "d-max" and keep decimals like "2.4" together
+				if j+1 < len(runes) && j > i && isWordRune(runes[j+1]) {
+					if runes[j] == '-' {
+						j++
+						continue
+					}
+					if runes[j] == '.' && unicode.IsDigit(runes[j-1]) && unicode.IsDigit(runes[j+1]) {
+						word = append(word, '.')
+						j++
+						continue
+					}
+				}
+				break
+			}
+			tokens = append(tokens, string(word))
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// Document joins the tokens of all fields into the text stored in the search index
+func Document(fields ...string) string {
+	var tokens []string
+	for _, f := range fields {
+		tokens = append(tokens, Tokenize(f)...)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// Query normalizes a user query the same way documents are indexed
+func Query(q string) string {
+	return strings.Join(Tokenize(q), " ")
+}
+
+// segmentThai splits a run of Thai characters into words.
+// It picks the segmentation with the fewest characters outside the dictionary,
+// then the fewest words, so "ฮอนด้าซีวิค" becomes [honda civic].
+func segmentThai(run []rune) []string {
+	type step struct {
+		unknown, words int
+		prev           int
+		canonical      string
+		known          bool
+	}
+
+	n := len(run)
+	best := make([]step, n+1)
+	for i := 1; i <= n; i++ {
+		best[i] = step{unknown: n + 1, prev: -1}
+	}
+
+	better := func(a, b step) bool {
+		if a.unknown != b.unknown {
+			return a.unknown < b.unknown
+		}
+		return a.words < b.words
+	}
+
+	for i := 0; i < n; i++ {
+		if best[i].prev < 0 && i > 0 {
+			continue
+		}
+		cur := best[i]
+
+		// dictionary words starting at i
+		node := dictionary
+		for j := i; j < n; j++ {
+			next, ok := node.children[run[j]]
+			if !ok {
+				break
+			}
+			node = next
+			if node.word {
+				cand := step{unknown: cur.unknown, words: cur.words + 1, prev: i, canonical: node.canonical, known: true}
+				if better(cand, best[j+1]) {
+					best[j+1] = cand
+				}
+			}
+		}
+
+		// a single unknown character, merged with neighbouring unknowns later
+		cand := step{unknown: cur.unknown + 1, words: cur.words + 1, prev: i}
+		if better(cand, best[i+1]) {
+			best[i+1] = cand
+		}
+	}
+
+	// walk back and merge consecutive unknown characters into one token
+	var parts []string
+	unknownEnd := -1
+	for end := n; end > 0; {
+		s := best[end]
+		if s.known {
+			if unknownEnd >= 0 {
+				parts = append(parts, string(run[end:unknownEnd]))
+				unknownEnd = -1
+			}
+			word := s.canonical
+			if word == "" {
+				word = string(run[s.prev:end])
+			}
+			parts = append(parts, word)
+		} else if unknownEnd < 0 {
+			unknownEnd = end
+		}
+		end = s.prev
+	}
+	if unknownEnd >= 0 {
+		parts = append(parts, string(run[:unknownEnd]))
+	}
+
+	for l, r := 0, len(parts)-1; l < r; l, r = l+1, r-1 {
+		parts[l], parts[r] = parts[r], parts[l]
+	}
+	return parts
+}
+
+func isWordRune(r rune) bool {
+	return !isThai(r) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func isThai(r rune) bool {
+	return r >= 0x0E00 && r <= 0x0E7F
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"latin words are lowercased", "Toyota Yaris ATIV", []string{"toyota", "yaris", "ativ"}},
+		{"hyphenated model names are joined", "Honda CR-V, Isuzu D-Max", []string{"honda", "crv", "isuzu", "dmax"}},
+		{"engine sizes keep the decimal point", "Fortuner 2.4 V", []string{"fortuner", "2.4", "v"}},
+		{"thai brand maps to its latin name", "ฮอนด้า", []string{"honda"}},
+		{"thai words without spaces are segmented", "ฮอนด้าซีวิค", []string{"honda", "civic"}},
+		{"thai and latin text mixed", "ขาย Honda ซีวิค 1.8", []string{"ขาย", "honda", "civic", "1.8"}},
+		{"thai next to latin without a space", "โตโยต้าYaris", []string{"toyota", "yaris"}},
+		{"short brand spelling", "มิตซู", []string{"mitsubishi"}},
+		{"long brand spelling", "มิตซูบิชิ", []string{"mitsubishi"}},
+		{"thai model spelling matches latin", "ซีอาร์วี", []string{"crv"}},
+		{"unknown thai characters stay one token", "กขคฮอนด้า", []string{"กขค", "honda"}},
+		{"unknown thai word alone", "ฌฌฌ", []string{"ฌฌฌ"}},
+		{"punctuation only", " - / ", nil},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Tokenize(%q) = %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSegmentThai(t *testing.T) {
+	tests := []struct {
+		run  string
+		want []string
+	}{
+		{"เบนซ์", []string{"mercedes"}},
+		{"บีเอ็มดับเบิลยู", []string{"bmw"}},
+		{"โฟล์คสวาเกน", []string{"volkswagen"}},
+		{"ฟอร์ดกขค", []string{"ford", "กขค"}},
+		{"กขคฟอร์ดงจฉ", []string{"กขค", "ford", "งจฉ"}},
+	}
+	for _, tt := range tests {
+		if got := segmentThai([]rune(tt.run)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("segmentThai(%q) = %q, want %q", tt.run, got, tt.want)
+		}
+	}
+}
+
+func TestQueryMatchesDocument(t *testing.T) {
+	doc := Document("Honda", "Civic", "ฮอนด้า ซีวิค ปี 2020")
+	for _, q := range []string{"ฮอนด้าซีวิค", "HONDA civic", "ซีวิค"} {
+		for _, token := range Tokenize(Query(q)) {
+			found := false
+			for _, d := range Tokenize(doc) {
+				found = found || d == token
+			}
+			if !found {
+				t.Errorf("query %q: token %q is not in document %q", q, token, doc)
+			}
+		}
+	}
+}
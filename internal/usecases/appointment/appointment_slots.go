@@ -0,0 +1,271 @@
+package appointment
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// minNotice is APPOINTMENT_MIN_NOTICE_HOURS (default 2): how soon a slot can be booked
+func minNotice() time.Duration {
+	return time.Duration(envInt("APPOINTMENT_MIN_NOTICE_HOURS", 2)) * time.Hour
+}
+
+// bookingDays is APPOINTMENT_BOOKING_DAYS (default 30): how far ahead slots can be booked
+func bookingDays() int {
+	return envInt("APPOINTMENT_BOOKING_DAYS", 30)
+}
+
+func envInt(key string, fallback int) int {
+	n, err := strconv.Atoi(utils.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+// ---------- Weekly availability & blackout days ----------
+
+func (u *AppointmentUsecase) GetAvailability(dealerID uint) ([]*entities.DealerAvailability, error) {
+	windows := []*entities.DealerAvailability{}
+	if err := u.AppointmentRepo.FindAvailability(dealerID, &windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// SetAvailability replaces the dealer's weekly opening windows
+func (u *AppointmentUsecase) SetAvailability(dealerID uint, windows []*entities.DealerAvailability) ([]*entities.DealerAvailability, error) {
+	for i, w := range windows {
+		w.ID, w.DealerID = 0, dealerID
+		if w.SlotMinutes == 0 {
+			w.SlotMinutes = 60
+		}
+		if w.Capacity == 0 {
+			w.Capacity = 1
+		}
+		if err := validateWindow(w); err != nil {
+			return nil, err
+		}
+		// Windows of the same day must not overlap
+		for _, other := range windows[:i] {
+			if other.Weekday == w.Weekday && w.OpenTime < other.CloseTime && other.OpenTime < w.CloseTime {
+				return nil, fmt.Errorf("windows %s-%s and %s-%s overlap", other.OpenTime, other.CloseTime, w.OpenTime, w.CloseTime)
+			}
+		}
+	}
+	if err := u.AppointmentRepo.ReplaceAvailability(dealerID, windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func validateWindow(w *entities.DealerAvailability) error {
+	if w.Weekday < 0 || w.Weekday > 6 {
+		return errors.New("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	open, err := clockMinutes(w.OpenTime)
+	if err != nil {
+		return err
+	}
+	closeAt, err := clockMinutes(w.CloseTime)
+	if err != nil {
+		return err
+	}
+	if w.SlotMinutes < 15 || w.SlotMinutes > 240 {
+		return errors.New("slot_minutes must be between 15 and 240")
+	}
+	if closeAt-open < w.SlotMinutes {
+		return errors.New("close_time must be at least one slot after open_time")
+	}
+	if w.Capacity < 1 || w.Capacity > 20 {
+		return errors.New("capacity must be between 1 and 20")
+	}
+	return nil
+}
+
+// clockMinutes parses "HH:MM" into minutes after midnight
+func clockMinutes(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// GetBlackouts returns the dealer's upcoming blackout days
+func (u *AppointmentUsecase) GetBlackouts(dealerID uint) ([]*entities.DealerBlackout, error) {
+	today := utils.StartOfDay(time.Now())
+	blackouts := []*entities.DealerBlackout{}
+	if err := u.AppointmentRepo.FindBlackouts(dealerID, today.Format("2006-01-02"), today.AddDate(1, 0, 0).Format("2006-01-02"), &blackouts); err != nil {
+		return nil, err
+	}
+	for _, b := range blackouts {
+		b.Date = b.Date[:10] // the driver may return a full timestamp
+	}
+	return blackouts, nil
+}
+
+func (u *AppointmentUsecase) AddBlackout(dealerID uint, date, reason string) (*entities.DealerBlackout, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, utils.LocalTimezone())
+	if err != nil {
+		return nil, errors.New("date must be YYYY-MM-DD")
+	}
+	if day.Before(utils.StartOfDay(time.Now())) {
+		return nil, errors.New("date must not be in the past")
+	}
+	blackout := &entities.DealerBlackout{DealerID: dealerID, Date: date, Reason: reason}
+	if err := u.AppointmentRepo.CreateBlackout(blackout); err != nil {
+		return nil, err
+	}
+	return blackout, nil
+}
+
+func (u *AppointmentUsecase) DeleteBlackout(dealerID, id uint) error {
+	if err := u.AppointmentRepo.DeleteBlackout(dealerID, id); err != nil {
+		return errors.New("blackout not found")
+	}
+	return nil
+}
+
+// ---------- Bookable slots ----------
+
+// Slot is a bookable time with the number of appointments it can still take
+type Slot struct {
+	StartAt   time.Time `json:"start_at"`
+	EndAt     time.Time `json:"end_at"`
+	Available int       `json:"available"`
+}
+
+// slotSource holds what is needed to lay out the slots of a dealer over a date range
+type slotSource struct {
+	windows   []*entities.DealerAvailability
+	blackouts map[string]bool
+	booked    []*entities.Appointment
+}
+
+func (u *AppointmentUsecase) loadSlotSource(dealerID uint, from, to time.Time) (*slotSource, error) {
+	src := &slotSource{blackouts: map[string]bool{}}
+	if err := u.AppointmentRepo.FindAvailability(dealerID, &src.windows); err != nil {
+		return nil, err
+	}
+	var blackouts []*entities.DealerBlackout
+	if err := u.AppointmentRepo.FindBlackouts(dealerID, from.Format("2006-01-02"), to.Format("2006-01-02"), &blackouts); err != nil {
+		return nil, err
+	}
+	for _, b := range blackouts {
+		src.blackouts[b.Date[:10]] = true // the driver may return a full timestamp
+	}
+	if err := u.AppointmentRepo.FindActive(dealerID, from, to.AddDate(0, 0, 1), &src.booked); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// GetSlots lists the free slots of a dealer for days days from a date
+// (YYYY-MM-DD, default today). With carID, slots in which that car is already
+// booked are left out too.
+func (u *AppointmentUsecase) GetSlots(dealerID, carID uint, fromDate string, days int) ([]*Slot, error) {
+	from := utils.StartOfDay(time.Now())
+	if fromDate != "" {
+		day, err := time.ParseInLocation("2006-01-02", fromDate, utils.LocalTimezone())
+		if err != nil {
+			return nil, errors.New("from must be YYYY-MM-DD")
+		}
+		if day.After(from) {
+			from = day
+		}
+	}
+	if days <= 0 || days > 14 {
+		days = 7
+	}
+	to := from.AddDate(0, 0, days-1)
+
+	src, err := u.loadSlotSource(dealerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []*Slot{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if src.blackouts[day.Format("2006-01-02")] {
+			continue
+		}
+		for _, w := range src.windows {
+			if w.Weekday != int(day.Weekday()) {
+				continue
+			}
+			for _, start := range windowStarts(day, w) {
+				end := start.Add(time.Duration(w.SlotMinutes) * time.Minute)
+				if !bookable(start) {
+					continue
+				}
+				free := w.Capacity
+				for _, a := range src.booked {
+					if a.StartAt.Before(end) && a.EndAt.After(start) {
+						free--
+						if carID != 0 && a.CarID == carID {
+							free = 0
+							break
+						}
+					}
+				}
+				if free > 0 {
+					slots = append(slots, &Slot{StartAt: start, EndAt: end, Available: free})
+				}
+			}
+		}
+	}
+	return slots, nil
+}
+
+// matchSlot finds the availability window a requested start time is a slot of
+// and returns it with the slot end
+func (u *AppointmentUsecase) matchSlot(dealerID uint, start time.Time) (*entities.DealerAvailability, time.Time, error) {
+	if !bookable(start) {
+		return nil, time.Time{}, fmt.Errorf("appointments must be booked between %d hours and %d days ahead",
+			int(minNotice().Hours()), bookingDays())
+	}
+	day := utils.StartOfDay(start)
+	src, err := u.loadSlotSource(dealerID, day, day)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if src.blackouts[day.Format("2006-01-02")] {
+		return nil, time.Time{}, errors.New("the dealer is closed on this day")
+	}
+	for _, w := range src.windows {
+		if w.Weekday != int(day.Weekday()) {
+			continue
+		}
+		for _, s := range windowStarts(day, w) {
+			if s.Equal(start) {
+				return w, s.Add(time.Duration(w.SlotMinutes) * time.Minute), nil
+			}
+		}
+	}
+	return nil, time.Time{}, errors.New("start_at is not one of the dealer's slots")
+}
+
+// windowStarts returns the slot start times of a window on a day
+func windowStarts(day time.Time, w *entities.DealerAvailability) []time.Time {
+	open, err1 := clockMinutes(w.OpenTime)
+	closeAt, err2 := clockMinutes(w.CloseTime)
+	if err1 != nil || err2 != nil || w.SlotMinutes <= 0 {
+		return nil
+	}
+	var starts []time.Time
+	for m := open; m+w.SlotMinutes <= closeAt; m += w.SlotMinutes {
+		starts = append(starts, time.Date(day.Year(), day.Month(), day.Day(), m/60, m%60, 0, 0, day.Location()))
+	}
+	return starts
+}
+
+// bookable reports whether start is within the booking notice and horizon
+func bookable(start time.Time) bool {
+	now := time.Now()
+	return !start.Before(now.Add(minNotice())) && !start.After(now.AddDate(0, 0, bookingDays()))
+}
@@ -0,0 +1,313 @@
+package appointment
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/notification"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// นัดทดลองขับ / เข้าชมโชว์รูม
+type AppointmentUsecase struct {
+	AppointmentRepo     *repositories.AppointmentRepository
+	CarRepo             *repositories.CarRepository
+	DealerRepo          *repositories.DealerRepository
+	LeadRepo            *repositories.LeadRepository
+	NotificationUsecase *notification.NotificationUsecase
+}
+
+// Parties of an appointment
+const (
+	PartyCustomer = "customer"
+	PartyDealer   = "dealer"
+)
+
+// Party identifies who acts on an appointment: the customer's user id or the dealer id
+type Party struct {
+	Role string
+	ID   uint
+}
+
+// BookingRequest is a customer's request for a slot
+type BookingRequest struct {
+	CarID   uint      `json:"car_id"`
+	StartAt time.Time `json:"start_at"`
+	Type    string    `json:"type"`
+	Note    string    `json:"note"`
+}
+
+// Book reserves a slot for a customer. The appointment waits for the dealer to
+// confirm and is linked to the customer's lead for the car.
+func (u *AppointmentUsecase) Book(customerID uint, req BookingRequest) (*entities.Appointment, error) {
+	var car entities.Car
+	// Reserved cars are listed but not open for new viewings
+	if err := u.CarRepo.FindByID(req.CarID, &car); err != nil || !car.IsPublic(time.Now()) || !entities.IsPublicStatus(car.Status) {
+		return nil, errors.New("car not found")
+	}
+	if car.Dealer.UserID == customerID {
+		return nil, errors.New("you cannot book your own car")
+	}
+	if req.Type == "" {
+		req.Type = entities.AppointmentTestDrive
+	}
+	if req.Type != entities.AppointmentTestDrive && req.Type != entities.AppointmentShowroom {
+		return nil, errors.New("type must be test_drive or showroom_visit")
+	}
+
+	window, end, err := u.matchSlot(car.DealerID, req.StartAt)
+	if err != nil {
+		return nil, err
+	}
+	a := &entities.Appointment{
+		CarID:      car.ID,
+		DealerID:   car.DealerID,
+		CustomerID: customerID,
+		Type:       req.Type,
+		StartAt:    req.StartAt,
+		EndAt:      end,
+		Status:     entities.AppointmentPending,
+		Note:       strings.TrimSpace(req.Note),
+	}
+	if err := u.AppointmentRepo.SaveIfFree(a, window.Capacity); err != nil {
+		return nil, err
+	}
+
+	if leadID, err := u.linkLead(&car, customerID); err != nil {
+		log.Printf("lead link failed for appointment %d: %v", a.ID, err)
+	} else if err := u.AppointmentRepo.SetLead(a.ID, leadID); err != nil {
+		log.Printf("lead link failed for appointment %d: %v", a.ID, err)
+	} else {
+		a.LeadID = &leadID
+	}
+
+	a.Car = car
+	u.notify(car.Dealer.UserID, a, "appointment_requested", "มีคำขอนัดหมายใหม่",
+		fmt.Sprintf("ลูกค้าขอนัด%sรถ %s %s วันที่ %s", typeLabel(a.Type), car.Brand, car.ModelName, formatTime(a.StartAt)))
+	return a, nil
+}
+
+// linkLead returns the customer's lead for the car, creating one for a first contact
+func (u *AppointmentUsecase) linkLead(car *entities.Car, customerID uint) (uint, error) {
+	lead := entities.Lead{CarID: car.ID, DealerID: car.DealerID, CustomerID: &customerID, ContactVia: "appointment"}
+	created, err := u.LeadRepo.FindOrCreate(&lead)
+	if err != nil {
+		return 0, err
+	}
+	if created {
+		if err := u.CarRepo.IncrementLeadCount(car.ID); err != nil {
+			return 0, err
+		}
+	}
+	return lead.ID, nil
+}
+
+// GetMyAppointments lists a customer's appointments, latest first
+func (u *AppointmentUsecase) GetMyAppointments(customerID uint) ([]*entities.Appointment, error) {
+	appointments := []*entities.Appointment{}
+	if err := u.AppointmentRepo.FindByCustomerID(customerID, &appointments); err != nil {
+		return nil, err
+	}
+	return appointments, nil
+}
+
+// GetDealerAppointments lists the dealer's appointments starting between two
+// dates (YYYY-MM-DD, default the next 30 days), optionally in one status
+func (u *AppointmentUsecase) GetDealerAppointments(dealerID uint, status, fromDate, toDate string) ([]*entities.Appointment, error) {
+	from := utils.StartOfDay(time.Now())
+	to := from.AddDate(0, 0, 30)
+	for _, d := range []struct {
+		raw    string
+		target *time.Time
+	}{{fromDate, &from}, {toDate, &to}} {
+		if d.raw == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02", d.raw, utils.LocalTimezone())
+		if err != nil {
+			return nil, errors.New("dates must be YYYY-MM-DD")
+		}
+		*d.target = t
+	}
+
+	appointments := []*entities.Appointment{}
+	if err := u.AppointmentRepo.FindByDealerID(dealerID, status, from, to.AddDate(0, 0, 1), &appointments); err != nil {
+		return nil, err
+	}
+	return appointments, nil
+}
+
+// Confirm accepts a pending appointment (dealer)
+func (u *AppointmentUsecase) Confirm(dealerID, id uint) (*entities.Appointment, error) {
+	a, err := u.load(id, Party{Role: PartyDealer, ID: dealerID})
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != entities.AppointmentPending {
+		return nil, fmt.Errorf("a %s appointment cannot be confirmed", a.Status)
+	}
+
+	a.Status = entities.AppointmentConfirmed
+	if err := u.AppointmentRepo.UpdateStatus(a, entities.AppointmentPending); err != nil {
+		return nil, err
+	}
+	u.notify(a.CustomerID, a, "appointment_confirmed", "ร้านยืนยันนัดหมายแล้ว",
+		fmt.Sprintf("นัด%sรถ %s %s วันที่ %s ได้รับการยืนยันแล้ว", typeLabel(a.Type), a.Car.Brand, a.Car.ModelName, formatTime(a.StartAt)))
+	return a, nil
+}
+
+// Reschedule moves an appointment to another free slot. A dealer's new time
+// is confirmed right away; a customer's needs the dealer to confirm again.
+func (u *AppointmentUsecase) Reschedule(party Party, id uint, startAt time.Time) (*entities.Appointment, error) {
+	a, err := u.load(id, party)
+	if err != nil {
+		return nil, err
+	}
+	if !isActive(a.Status) {
+		return nil, fmt.Errorf("a %s appointment cannot be rescheduled", a.Status)
+	}
+
+	window, end, err := u.matchSlot(a.DealerID, startAt)
+	if err != nil {
+		return nil, err
+	}
+	a.StartAt, a.EndAt, a.RemindedAt = startAt, end, nil
+	if party.Role == PartyDealer {
+		a.Status = entities.AppointmentConfirmed
+	} else {
+		a.Status = entities.AppointmentPending
+	}
+	if err := u.AppointmentRepo.SaveIfFree(a, window.Capacity); err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("นัด%sรถ %s %s ถูกเลื่อนเป็นวันที่ %s", typeLabel(a.Type), a.Car.Brand, a.Car.ModelName, formatTime(a.StartAt))
+	u.notifyOtherParty(party, a, "appointment_rescheduled", "นัดหมายถูกเลื่อน", body)
+	return a, nil
+}
+
+// Cancel frees the slot of an appointment
+func (u *AppointmentUsecase) Cancel(party Party, id uint, reason string) (*entities.Appointment, error) {
+	a, err := u.load(id, party)
+	if err != nil {
+		return nil, err
+	}
+	if !isActive(a.Status) {
+		return nil, fmt.Errorf("a %s appointment cannot be cancelled", a.Status)
+	}
+
+	from := a.Status
+	a.Status = entities.AppointmentCancelled
+	a.CancelReason, a.CancelledBy = strings.TrimSpace(reason), party.Role
+	if err := u.AppointmentRepo.UpdateStatus(a, from); err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("นัด%sรถ %s %s วันที่ %s ถูกยกเลิก", typeLabel(a.Type), a.Car.Brand, a.Car.ModelName, formatTime(a.StartAt))
+	if a.CancelReason != "" {
+		body += " เหตุผล: " + a.CancelReason
+	}
+	u.notifyOtherParty(party, a, "appointment_cancelled", "นัดหมายถูกยกเลิก", body)
+	return a, nil
+}
+
+// Complete marks a confirmed appointment as held (dealer)
+func (u *AppointmentUsecase) Complete(dealerID, id uint) (*entities.Appointment, error) {
+	a, err := u.load(id, Party{Role: PartyDealer, ID: dealerID})
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != entities.AppointmentConfirmed {
+		return nil, fmt.Errorf("a %s appointment cannot be completed", a.Status)
+	}
+	if time.Now().Before(a.StartAt) {
+		return nil, errors.New("the appointment has not started yet")
+	}
+
+	a.Status = entities.AppointmentCompleted
+	if err := u.AppointmentRepo.UpdateStatus(a, entities.AppointmentConfirmed); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SendReminders reminds both parties of confirmed appointments starting within
+// APPOINTMENT_REMINDER_HOURS (default 24) hours (background job)
+func (u *AppointmentUsecase) SendReminders() error {
+	hours := envInt("APPOINTMENT_REMINDER_HOURS", 24)
+	var due []*entities.Appointment
+	if err := u.AppointmentRepo.FindDueReminders(time.Now().Add(time.Duration(hours)*time.Hour), &due); err != nil {
+		return err
+	}
+
+	for _, a := range due {
+		body := fmt.Sprintf("นัด%sรถ %s %s วันที่ %s", typeLabel(a.Type), a.Car.Brand, a.Car.ModelName, formatTime(a.StartAt))
+		u.notify(a.CustomerID, a, "appointment_reminder", "เตือนนัดหมาย", body)
+		u.notify(a.Car.Dealer.UserID, a, "appointment_reminder", "เตือนนัดหมาย", body)
+		if err := u.AppointmentRepo.MarkReminded(a.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load returns an appointment of the customer or dealer acting on it
+func (u *AppointmentUsecase) load(id uint, party Party) (*entities.Appointment, error) {
+	var a entities.Appointment
+	if err := u.AppointmentRepo.FindByID(id, &a); err != nil {
+		return nil, errors.New("appointment not found")
+	}
+	if (party.Role == PartyCustomer && a.CustomerID != party.ID) || (party.Role == PartyDealer && a.DealerID != party.ID) {
+		return nil, errors.New("appointment not found")
+	}
+	return &a, nil
+}
+
+// notifyOtherParty tells the customer about a dealer's change and the other way round
+func (u *AppointmentUsecase) notifyOtherParty(party Party, a *entities.Appointment, kind, title, body string) {
+	if party.Role == PartyDealer {
+		u.notify(a.CustomerID, a, kind, title, body)
+		return
+	}
+	var dealer entities.Dealer
+	if err := u.DealerRepo.FindByID(a.DealerID, &dealer); err != nil {
+		log.Printf("%s notification failed for appointment %d: %v", kind, a.ID, err)
+		return
+	}
+	u.notify(dealer.UserID, a, kind, title, body)
+}
+
+func (u *AppointmentUsecase) notify(userID uint, a *entities.Appointment, kind, title, body string) {
+	if u.NotificationUsecase == nil || userID == 0 {
+		return
+	}
+	carID := a.CarID
+	if err := u.NotificationUsecase.Notify(&entities.Notification{
+		UserID: userID,
+		Type:   kind,
+		Title:  title,
+		Body:   body,
+		CarID:  &carID,
+	}); err != nil {
+		log.Printf("%s notification failed for appointment %d: %v", kind, a.ID, err)
+	}
+}
+
+func isActive(status string) bool {
+	return status == entities.AppointmentPending || status == entities.AppointmentConfirmed
+}
+
+func typeLabel(kind string) string {
+	if kind == entities.AppointmentShowroom {
+		return "เข้าชมโชว์รูม"
+	}
+	return "ทดลองขับ"
+}
+
+func formatTime(t time.Time) string {
+	return t.In(utils.LocalTimezone()).Format("02/01/2006 15:04")
+}
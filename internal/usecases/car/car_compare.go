@@ -0,0 +1,163 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const maxCompareCars = 4
+
+// CarComparison is a side-by-side spec matrix; Values[i] belongs to Cars[i]
+type CarComparison struct {
+	Cars   []*entities.Car   `json:"cars"`
+	Fields []ComparisonField `json:"fields"`
+}
+
+// ComparisonField is one row of the matrix.
+// Differs is true when the cars don't all share the same value;
+// BestIndex points at the best car for rows where lower/higher is better.
+type ComparisonField struct {
+	Key       string        `json:"key"`
+	Label     string        `json:"label"`
+	Values    []interface{} `json:"values"`
+	Differs   bool          `json:"differs"`
+	BestIndex *int          `json:"best_index,omitempty"`
+}
+
+// CompareCars builds the comparison matrix for 2-4 cars.
+// near is optional and adds the distance to each dealer.
+func (u *CarUsecase) CompareCars(ids []uint, near *repositories.GeoPoint) (*CarComparison, error) {
+	if len(ids) < 2 || len(ids) > maxCompareCars {
+		return nil, fmt.Errorf("compare needs between 2 and %d cars", maxCompareCars)
+	}
+
+	seen := map[uint]bool{}
+	cars := make([]*entities.Car, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return nil, errors.New("duplicate car id")
+		}
+		seen[id] = true
+
+		var car entities.Car
+		if err := u.CarRepo.FindByID(id, &car); err != nil || !car.IsPublic(time.Now()) {
+			return nil, fmt.Errorf("car %d not found", id)
+		}
+		cars = append(cars, &car)
+	}
+
+	n := len(cars)
+	price := make([]interface{}, n)
+	year := make([]interface{}, n)
+	mileage := make([]interface{}, n)
+	fuel := make([]interface{}, n)
+	transmission := make([]interface{}, n)
+	carType := make([]interface{}, n)
+	color := make([]interface{}, n)
+	rating := make([]interface{}, n)
+	reviews := make([]interface{}, n)
+	distance := make([]interface{}, n)
+
+	stats := map[uint]map[string]interface{}{}
+	for i, car := range cars {
+		price[i] = car.Price
+		year[i] = car.Year
+		mileage[i] = car.Mileage
+		fuel[i] = car.FuelType
+		transmission[i] = car.Transmission
+		carType[i] = car.CarType
+		color[i] = car.Color
+
+		s, ok := stats[car.DealerID]
+		if !ok {
+			var err error
+			if s, err = u.DealerUsecase.GetDealerStats(car.DealerID); err != nil {
+				return nil, err
+			}
+			stats[car.DealerID] = s
+		}
+		rating[i] = s["total_rating"]
+		reviews[i] = s["review_count"]
+
+		if near != nil && car.Dealer.Latitude != nil && car.Dealer.Longitude != nil {
+			km := utils.HaversineKm(near.Lat, near.Lon, *car.Dealer.Latitude, *car.Dealer.Longitude)
+			distance[i] = math.Round(km*10) / 10
+		}
+	}
+
+	fields := []ComparisonField{
+		compareField("price", "ราคา", price, -1),
+		compareField("year", "ปี", year, 1),
+		compareField("mileage", "เลขไมล์", mileage, -1),
+		compareField("fuel_type", "เชื้อเพลิง", fuel, 0),
+		compareField("transmission", "เกียร์", transmission, 0),
+		compareField("car_type", "ประเภทรถ", carType, 0),
+		compareField("color", "สี", color, 0),
+		compareField("dealer_rating", "คะแนนร้าน", rating, 1),
+		compareField("dealer_review_count", "จำนวนรีวิวร้าน", reviews, 1),
+	}
+	if near != nil {
+		fields = append(fields, compareField("distance_km", "ระยะทาง (กม.)", distance, -1))
+	}
+
+	return &CarComparison{Cars: cars, Fields: fields}, nil
+}
+
+// compareField builds a matrix row. better is -1 when lower is better,
+// 1 when higher is better and 0 when the row has no ranking.
+func compareField(key, label string, values []interface{}, better int) ComparisonField {
+	f := ComparisonField{Key: key, Label: label, Values: values}
+
+	first := normalizeCompareValue(values[0])
+	for _, v := range values[1:] {
+		if normalizeCompareValue(v) != first {
+			f.Differs = true
+			break
+		}
+	}
+	if !f.Differs || better == 0 {
+		return f
+	}
+
+	best := -1
+	var bestValue float64
+	for i, v := range values {
+		x, ok := toFloat(v)
+		if !ok {
+			continue
+		}
+		if best < 0 || (better < 0 && x < bestValue) || (better > 0 && x > bestValue) {
+			best, bestValue = i, x
+		}
+	}
+	if best >= 0 {
+		f.BestIndex = &best
+	}
+	return f
+}
+
+func normalizeCompareValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return strings.ToLower(strings.TrimSpace(s))
+	}
+	return fmt.Sprint(v)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	}
+	return 0, false
+}
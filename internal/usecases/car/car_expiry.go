@@ -0,0 +1,126 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// listingLifetime is LISTING_LIFETIME_DAYS (default 60); 0 disables expiry
+func listingLifetime() time.Duration {
+	days, err := strconv.Atoi(utils.GetEnv("LISTING_LIFETIME_DAYS", "60"))
+	if err != nil || days < 0 {
+		days = 60
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// listingExpiry returns when a listing approved or renewed at t expires, or nil without a lifetime
+func listingExpiry(t time.Time) *time.Time {
+	lifetime := listingLifetime()
+	if lifetime == 0 {
+		return nil
+	}
+	expiresAt := t.Add(lifetime)
+	return &expiresAt
+}
+
+// RenewCar restarts the lifetime of a live or expired listing
+func (u *CarUsecase) RenewCar(carID uint, actor Actor) (*entities.Car, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return nil, errors.New("car not found")
+	}
+
+	if car.Status == entities.CarStatusExpired {
+		// Transition to approved sets the new expiry date
+		if err := u.Transition(&car, entities.CarStatusApproved, actor, "renewed"); err != nil {
+			return nil, err
+		}
+		return &car, nil
+	}
+	if !entities.IsPublicStatus(car.Status) {
+		return nil, fmt.Errorf("a %s listing cannot be renewed", car.Status)
+	}
+
+	car.ExpiresAt = listingExpiry(time.Now())
+	car.ExpiryWarnedAt = nil
+	if err := u.CarRepo.UpdateFields(&car, map[string]interface{}{
+		"expires_at":       car.ExpiresAt,
+		"expiry_warned_at": nil,
+	}); err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// GetExpiredCars lists the dealer's expired inventory
+func (u *CarUsecase) GetExpiredCars(dealerID uint) ([]*entities.Car, error) {
+	cars := []*entities.Car{}
+	if err := u.CarRepo.FindByDealerIDAndStatus(dealerID, entities.CarStatusExpired, &cars); err != nil {
+		return nil, err
+	}
+	return cars, nil
+}
+
+// ProcessListingExpiry warns dealers LISTING_EXPIRY_WARNING_DAYS (default 7) days
+// before a listing expires and moves overdue listings to "expired" (background job)
+func (u *CarUsecase) ProcessListingExpiry() error {
+	if listingLifetime() == 0 {
+		return nil
+	}
+	if _, err := u.CarRepo.AssignMissingExpiry(*listingExpiry(time.Now())); err != nil {
+		return err
+	}
+
+	warnDays, err := strconv.Atoi(utils.GetEnv("LISTING_EXPIRY_WARNING_DAYS", "7"))
+	if err != nil || warnDays < 0 {
+		warnDays = 7
+	}
+	var expiring []*entities.Car
+	if err := u.CarRepo.FindExpiringListings(time.Now().AddDate(0, 0, warnDays), &expiring); err != nil {
+		return err
+	}
+	for _, c := range expiring {
+		u.notifyDealer(c, "listing_expiring", "ประกาศใกล้หมดอายุ",
+			fmt.Sprintf("%s %s ปี %d จะหมดอายุเมื่อ %s ต่ออายุได้ในหน้าจัดการรถ",
+				c.Brand, c.ModelName, c.Year, c.ExpiresAt.In(utils.LocalTimezone()).Format("02/01/2006")))
+		if err := u.CarRepo.MarkExpiryWarned(c.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	var expired []*entities.Car
+	if err := u.CarRepo.FindExpiredListings(&expired); err != nil {
+		return err
+	}
+	for _, c := range expired {
+		if err := u.Transition(c, entities.CarStatusExpired, Actor{Role: ActorSystem}, "listing lifetime ended"); err != nil {
+			log.Printf("listing expiry failed for car %d: %v", c.ID, err)
+			continue
+		}
+		u.notifyDealer(c, "listing_expired", "ประกาศหมดอายุแล้ว",
+			fmt.Sprintf("%s %s ปี %d ถูกนำออกจากหน้าประกาศ กดต่ออายุเพื่อแสดงอีกครั้ง", c.Brand, c.ModelName, c.Year))
+	}
+	return nil
+}
+
+func (u *CarUsecase) notifyDealer(c *entities.Car, kind, title, body string) {
+	if u.NotificationUsecase == nil || c.Dealer.UserID == 0 {
+		return
+	}
+	carID := c.ID
+	if err := u.NotificationUsecase.Notify(&entities.Notification{
+		UserID: c.Dealer.UserID,
+		Type:   kind,
+		Title:  title,
+		Body:   body,
+		CarID:  &carID,
+	}); err != nil {
+		log.Printf("%s notification failed for car %d: %v", kind, c.ID, err)
+	}
+}
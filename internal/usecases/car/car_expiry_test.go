@@ -0,0 +1,77 @@
+package car
+
+import (
+	"testing"
+	"time"
+
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/notification"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestListingExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Setenv("LISTING_LIFETIME_DAYS", "30")
+	if got := listingExpiry(now); got == nil || !got.Equal(now.AddDate(0, 0, 30)) {
+		t.Errorf("30 day lifetime: expiry = %v", got)
+	}
+	t.Setenv("LISTING_LIFETIME_DAYS", "0")
+	if got := listingExpiry(now); got != nil {
+		t.Errorf("disabled lifetime: expiry = %v, want nil", got)
+	}
+	t.Setenv("LISTING_LIFETIME_DAYS", "-5")
+	if got := listingLifetime(); got != 60*24*time.Hour {
+		t.Errorf("invalid lifetime = %v, want the 60 day default", got)
+	}
+}
+
+func TestProcessListingExpiry(t *testing.T) {
+	t.Setenv("LISTING_LIFETIME_DAYS", "60")
+	db, mock := mockDB(t)
+	u := &CarUsecase{
+		CarRepo:             &repositories.CarRepository{DB: db},
+		NotificationUsecase: &notification.NotificationUsecase{NotificationRepo: &repositories.NotificationRepository{DB: db}},
+	}
+	carColumns := []string{"id", "dealer_id", "brand", "model_name", "year", "status", "expires_at"}
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE "cars" SET "expires_at"=\$1 WHERE \(status IN \(\$2,\$3\) AND expires_at IS NULL\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// A listing about to expire: the dealer is warned once
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(status IN \(\$1,\$2\) AND expires_at > NOW\(\) AND expires_at <= \$3\) AND expiry_warned_at IS NULL`).
+		WillReturnRows(sqlmock.NewRows(carColumns).AddRow(1, 10, "Honda", "Jazz", 2018, entities.CarStatusSelling, now.Add(72*time.Hour)))
+	mock.ExpectQuery(`SELECT \* FROM "dealers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 100))
+	expectNotification(mock, 100, "listing_expiring")
+	mock.ExpectExec(`UPDATE "cars" SET "expiry_warned_at"=\$1 WHERE id = \$2`).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Overdue listings move to expired; one changed status meanwhile and is skipped
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(status IN \(\$1,\$2\) AND expires_at <= NOW\(\)\)`).
+		WillReturnRows(sqlmock.NewRows(carColumns).
+			AddRow(2, 10, "Toyota", "Vios", 2017, entities.CarStatusSelling, now.Add(-time.Hour)).
+			AddRow(3, 10, "Toyota", "Altis", 2016, entities.CarStatusSelling, now.Add(-time.Hour)))
+	mock.ExpectQuery(`SELECT \* FROM "dealers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 100))
+	expectTransition(mock, 2, entities.CarStatusSelling, entities.CarStatusExpired, 1)
+	expectNotification(mock, 100, "listing_expired")
+	expectTransition(mock, 3, entities.CarStatusSelling, entities.CarStatusExpired, 0)
+
+	if err := u.ProcessListingExpiry(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessListingExpiryDisabled(t *testing.T) {
+	t.Setenv("LISTING_LIFETIME_DAYS", "0")
+	db, _ := mockDB(t)
+	u := &CarUsecase{CarRepo: &repositories.CarRepository{DB: db}}
+	if err := u.ProcessListingExpiry(); err != nil {
+		t.Fatal(err)
+	}
+}
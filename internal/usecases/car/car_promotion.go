@@ -0,0 +1,55 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/utils"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// ExpirePromotions un-promotes cars whose PromotedUntil has passed (background job)
+func (u *CarUsecase) ExpirePromotions() error {
+	n, err := u.CarRepo.ExpirePromotions(time.Now())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("promotion expiry: %d car(s) un-promoted", n)
+	}
+	return nil
+}
+
+// SendPromotionReminders tells dealers their promotion ends within
+// PROMOTION_REMINDER_HOURS (default 24) hours (background job)
+func (u *CarUsecase) SendPromotionReminders() error {
+	hours, err := strconv.Atoi(utils.GetEnv("PROMOTION_REMINDER_HOURS", "24"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+
+	var cars []*entities.Car
+	if err := u.CarRepo.FindPromotionsEndingBefore(time.Now().Add(time.Duration(hours)*time.Hour), &cars); err != nil {
+		return err
+	}
+
+	for _, c := range cars {
+		carID := c.ID
+		if err := u.NotificationUsecase.Notify(&entities.Notification{
+			UserID: c.Dealer.UserID,
+			Type:   "promotion_ending",
+			Title:  "โปรโมทประกาศใกล้หมดอายุ",
+			Body: fmt.Sprintf("%s %s ปี %d จะหมดการโปรโมทเมื่อ %s",
+				c.Brand, c.ModelName, c.Year, c.PromotedUntil.Format("02/01/2006 15:04")),
+			CarID: &carID,
+		}); err != nil {
+			log.Printf("promotion reminder failed for car %d: %v", c.ID, err)
+			continue
+		}
+		if err := u.CarRepo.MarkPromotionReminded(c.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package car
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/notification"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestExpirePromotions(t *testing.T) {
+	db, mock := mockDB(t)
+	u := &CarUsecase{CarRepo: &repositories.CarRepository{DB: db}}
+
+	mock.ExpectExec(`UPDATE "cars" SET "is_promoted"=\$1 WHERE \(is_promoted = \$2 AND \(promoted_until IS NULL OR promoted_until <= \$3\)\)`).
+		WithArgs(false, true, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := u.ExpirePromotions(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSendPromotionReminders(t *testing.T) {
+	t.Setenv("PROMOTION_REMINDER_HOURS", "12")
+	db, mock := mockDB(t)
+	u := &CarUsecase{
+		CarRepo:             &repositories.CarRepository{DB: db},
+		NotificationUsecase: &notification.NotificationUsecase{NotificationRepo: &repositories.NotificationRepository{DB: db}},
+	}
+	ends := time.Now().Add(6 * time.Hour)
+
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(is_promoted = \$1 AND promoted_until > NOW\(\) AND promoted_until <= \$2\) AND promotion_reminded_at IS NULL`).
+		WithArgs(true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dealer_id", "brand", "model_name", "year", "is_promoted", "promoted_until"}).
+			AddRow(1, 10, "Honda", "Civic", 2021, true, ends).
+			AddRow(2, 20, "Mazda", "2", 2019, true, ends))
+	mock.ExpectQuery(`SELECT \* FROM "dealers" WHERE "dealers"."id" IN \(\$1,\$2\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 100).AddRow(20, 200))
+
+	// The first dealer is reminded once; a failed notification is retried next run
+	mock.ExpectQuery(`INSERT INTO "notifications"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`UPDATE "cars" SET "promotion_reminded_at"=\$1 WHERE id = \$2`).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "notifications"`).
+		WillReturnError(errors.New("connection reset"))
+
+	if err := u.SendPromotionReminders(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,252 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Re-moderation modes for edits of material fields on a live car (CAR_REMODERATION_MODE)
+const (
+	RemoderationPending  = "pending"  // the car goes back to pending and leaves the listing
+	RemoderationRevision = "revision" // edits wait in a CarRevision; the approved version stays public
+)
+
+// ImagesMaterialField marks new photos as a material change in CAR_MATERIAL_FIELDS
+const ImagesMaterialField = "images"
+
+// materialFields returns the car columns (and "images") whose edits need review,
+// from CAR_MATERIAL_FIELDS (comma-separated)
+func materialFields() map[string]bool {
+	fields := map[string]bool{}
+	for _, f := range strings.Split(utils.GetEnv("CAR_MATERIAL_FIELDS", "brand,model_name,year,price,description,images"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields[f] = true
+		}
+	}
+	// Catalog links follow their text columns
+	fields["brand_id"] = fields["brand"]
+	fields["model_id"] = fields["model_name"]
+	fields["trim_id"] = fields["model_name"]
+	return fields
+}
+
+func remoderationMode() string {
+	if utils.GetEnv("CAR_REMODERATION_MODE", RemoderationRevision) == RemoderationPending {
+		return RemoderationPending
+	}
+	return RemoderationRevision
+}
+
+// needsReview reports whether material edits to the car must be moderated again
+func needsReview(car *entities.Car) bool {
+	switch car.Status {
+	case entities.CarStatusApproved, entities.CarStatusSelling, entities.CarStatusReserved:
+		return true
+	}
+	return false
+}
+
+// queueRevision merges held changes into the car's pending revision, creating it if needed
+func (u *CarUsecase) queueRevision(car *entities.Car, held CarUpdate, actor Actor) error {
+	rev, err := u.pendingRevision(car, actor)
+	if err != nil {
+		return err
+	}
+	for column, value := range held.toMap() {
+		rev.Changes[column] = value
+	}
+	rev.SubmittedBy = actor.UserID
+	return u.RevisionRepo.Update(rev)
+}
+
+func (u *CarUsecase) pendingRevision(car *entities.Car, actor Actor) (*entities.CarRevision, error) {
+	var rev entities.CarRevision
+	if err := u.RevisionRepo.FindPending(car.ID, &rev); err == nil {
+		if rev.Changes == nil {
+			rev.Changes = map[string]interface{}{}
+		}
+		return &rev, nil
+	}
+
+	rev = entities.CarRevision{
+		CarID:       car.ID,
+		SubmittedBy: actor.UserID,
+		Changes:     map[string]interface{}{},
+		Status:      entities.RevisionPending,
+	}
+	if err := u.RevisionRepo.Create(&rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// PrepareNewImage decides how a photo added by a dealer goes live. It returns the
+// revision the image must wait in, or nil when the image can be published directly.
+// In "pending" mode the car itself is sent back to moderation.
+func (u *CarUsecase) PrepareNewImage(carID uint, actor Actor) (*uint, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return nil, errors.New("car not found")
+	}
+	if !needsReview(&car) || !materialFields()[ImagesMaterialField] {
+		return nil, nil
+	}
+
+	if remoderationMode() == RemoderationPending {
+		return nil, u.Transition(&car, entities.CarStatusPending, Actor{Role: ActorSystem}, "new images added")
+	}
+
+	rev, err := u.pendingRevision(&car, actor)
+	if err != nil {
+		return nil, err
+	}
+	return &rev.ID, nil
+}
+
+// RevisionFieldDiff compares the live value of a field with the proposed one
+type RevisionFieldDiff struct {
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Proposed interface{} `json:"proposed"`
+}
+
+// CarRevisionView is a revision with its diff against the live car
+type CarRevisionView struct {
+	*entities.CarRevision
+	Diff []RevisionFieldDiff `json:"diff"`
+}
+
+// GetRevisions lists the revisions of a car, newest first, with a field diff
+func (u *CarUsecase) GetRevisions(carID uint) ([]CarRevisionView, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return nil, errors.New("car not found")
+	}
+
+	var revisions []*entities.CarRevision
+	if err := u.RevisionRepo.FindByCarID(carID, &revisions); err != nil {
+		return nil, err
+	}
+
+	current := carValues(&car)
+	views := make([]CarRevisionView, 0, len(revisions))
+	for _, rev := range revisions {
+		view := CarRevisionView{CarRevision: rev, Diff: []RevisionFieldDiff{}}
+		for _, field := range editableFields {
+			if proposed, ok := rev.Changes[field]; ok {
+				view.Diff = append(view.Diff, RevisionFieldDiff{Field: field, Current: current[field], Proposed: proposed})
+			}
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// ApproveRevision applies a pending revision to the live car and publishes its photos
+func (u *CarUsecase) ApproveRevision(carID, revisionID uint, actor Actor) error {
+	rev, err := u.findPendingRevision(carID, revisionID)
+	if err != nil {
+		return err
+	}
+
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return errors.New("car not found")
+	}
+
+	in, err := carUpdateFromMap(rev.Changes)
+	if err != nil {
+		return err
+	}
+	oldPrice := car.Price
+	var fields map[string]interface{}
+	if !in.isEmpty() {
+		if fields, err = u.prepareUpdate(&car, in); err != nil {
+			return err
+		}
+	}
+	history := priceChange(&car, oldPrice)
+
+	// Car fields, price history, photos and the review itself are written together
+	u.closeRevision(rev, entities.RevisionApproved, actor, "")
+	if err := u.RevisionRepo.Approve(rev, &car, fields, history); err != nil {
+		return err
+	}
+
+	if history != nil {
+		u.refreshPricing(&car)
+		u.notifyPriceDrop(&car, oldPrice)
+	}
+	u.notifyRevisionOwner(&car, "revision_approved", "การแก้ไขประกาศได้รับการอนุมัติ", "")
+	return nil
+}
+
+// RejectRevision discards a pending revision; the live car is left unchanged
+func (u *CarUsecase) RejectRevision(carID, revisionID uint, reason string, actor Actor) error {
+	rev, err := u.findPendingRevision(carID, revisionID)
+	if err != nil {
+		return err
+	}
+
+	// The review and the removal of its photos are written together
+	u.closeRevision(rev, entities.RevisionRejected, actor, reason)
+	if err := u.RevisionRepo.Reject(rev); err != nil {
+		return err
+	}
+
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err == nil {
+		u.notifyRevisionOwner(&car, "revision_rejected", "การแก้ไขประกาศไม่ได้รับการอนุมัติ", reason)
+	}
+	return nil
+}
+
+func (u *CarUsecase) findPendingRevision(carID, revisionID uint) (*entities.CarRevision, error) {
+	var rev entities.CarRevision
+	if err := u.RevisionRepo.FindByID(revisionID, &rev); err != nil || rev.CarID != carID {
+		return nil, errors.New("revision not found")
+	}
+	if rev.Status != entities.RevisionPending {
+		return nil, fmt.Errorf("revision is already %s", rev.Status)
+	}
+	return &rev, nil
+}
+
+func (u *CarUsecase) closeRevision(rev *entities.CarRevision, status string, actor Actor, reason string) {
+	now := time.Now()
+	reviewer := actor.UserID
+	rev.Status = status
+	rev.ReviewedBy = &reviewer
+	rev.ReviewedAt = &now
+	rev.ReviewReason = reason
+}
+
+func (u *CarUsecase) notifyRevisionOwner(car *entities.Car, kind, title, body string) {
+	if body == "" {
+		body = fmt.Sprintf("%s %s ปี %d", car.Brand, car.ModelName, car.Year)
+	}
+	u.notifyDealer(car, kind, title, body)
+}
+
+// editableFields is the display order of CarUpdate fields in a diff
+var editableFields = []string{"brand", "model_name", "year", "mileage", "price", "car_type", "fuel_type", "transmission", "color", "description"}
+
+// carValues returns the live values of the editable fields of a car
+func carValues(car *entities.Car) map[string]interface{} {
+	return map[string]interface{}{
+		"brand":        car.Brand,
+		"model_name":   car.ModelName,
+		"year":         car.Year,
+		"mileage":      car.Mileage,
+		"price":        car.Price,
+		"car_type":     car.CarType,
+		"fuel_type":    car.FuelType,
+		"transmission": car.Transmission,
+		"color":        car.Color,
+		"description":  car.Description,
+	}
+}
@@ -0,0 +1,59 @@
+package car
+
+import (
+	"errors"
+	"testing"
+
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectPendingRevision(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`SELECT \* FROM "car_revisions" WHERE "car_revisions"."id" = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "car_id", "status"}).AddRow(3, 1, entities.RevisionPending))
+	mock.ExpectQuery(`SELECT \* FROM "car_images" WHERE "car_images"."revision_id" = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "car_id", "revision_id"}).AddRow(9, 1, 3))
+}
+
+func TestRejectRevision(t *testing.T) {
+	db, mock := mockDB(t)
+	u := &CarUsecase{
+		CarRepo:      &repositories.CarRepository{DB: db},
+		RevisionRepo: &repositories.CarRevisionRepository{DB: db},
+	}
+	anyArg := sqlmock.AnyArg()
+
+	expectPendingRevision(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "car_revisions" SET "updated_at"=\$1,"status"=\$2,"reviewed_by"=\$3,"reviewed_at"=\$4,"review_reason"=\$5 WHERE status = \$6`).
+		WithArgs(anyArg, entities.RevisionRejected, 7, anyArg, "blurry photos", entities.RevisionPending, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "car_images" SET "deleted_at"=\$1 WHERE revision_id = \$2`).
+		WithArgs(anyArg, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT \* FROM "cars"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if err := u.RejectRevision(1, 3, "blurry photos", Actor{UserID: 7, Role: ActorAdmin}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRejectRevisionReviewedMeanwhile(t *testing.T) {
+	db, mock := mockDB(t)
+	u := &CarUsecase{RevisionRepo: &repositories.CarRevisionRepository{DB: db}}
+
+	// Approved by another admin after it was loaded: nothing is deleted
+	expectPendingRevision(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "car_revisions" SET .* WHERE status = \$6`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := u.RejectRevision(1, 3, "", Actor{UserID: 7, Role: ActorAdmin})
+	if !errors.Is(err, repositories.ErrRevisionClosed) {
+		t.Fatalf("err = %v, want ErrRevisionClosed", err)
+	}
+}
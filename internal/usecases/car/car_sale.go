@@ -0,0 +1,331 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// SaleInput holds the details a dealer records when marking a car sold or
+// correcting a sale. Zero values fall back to the asking price, the current
+// time and the source of the buyer's lead.
+type SaleInput struct {
+	FinalPrice float64    `json:"final_price"`
+	SoldAt     *time.Time `json:"sold_at"`
+	BuyerID    *uint      `json:"buyer_id"`
+	LeadSource string     `json:"lead_source"`
+	Note       string     `json:"note"`
+	// Set when the sale closes a reservation
+	ReservationID *uint `json:"-"`
+}
+
+// MarkSold moves a car to sold and records the sale
+func (u *CarUsecase) MarkSold(carID uint, actor Actor, in SaleInput) (*entities.Sale, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return nil, errors.New("car not found")
+	}
+	if err := CanTransition(car.Status, entities.CarStatusSold, actor.Role); err != nil {
+		return nil, err
+	}
+
+	sale := &entities.Sale{ReservationID: in.ReservationID}
+	if err := u.applySale(&car, sale, in); err != nil {
+		return nil, err
+	}
+	if err := u.transition(&car, entities.CarStatusSold, actor, "", sale); err != nil {
+		return nil, err
+	}
+	sale.Car = car
+	return sale, nil
+}
+
+// prepareSale completes the sale of a car about to be sold; it is saved
+// together with the status change. Cars sold through the status endpoint get
+// a sale at the asking price.
+func (u *CarUsecase) prepareSale(car *entities.Car, actor Actor, sale *entities.Sale) (*entities.Sale, error) {
+	if u.SaleRepo == nil {
+		return nil, nil
+	}
+	if sale == nil {
+		sale = &entities.Sale{}
+		if err := u.applySale(car, sale, SaleInput{}); err != nil {
+			return nil, err
+		}
+	}
+	sale.CarID, sale.DealerID, sale.ListPrice = car.ID, car.DealerID, car.Price
+	sale.RecordedBy = actor.UserID
+	return sale, nil
+}
+
+// undoSale drops the sale of a car that is back on sale
+func (u *CarUsecase) undoSale(car *entities.Car) {
+	if u.SaleRepo == nil {
+		return
+	}
+	var sale entities.Sale
+	if err := u.SaleRepo.FindActiveByCarID(car.ID, &sale); err != nil {
+		return
+	}
+	if err := u.SaleRepo.Delete(sale.ID); err != nil {
+		log.Printf("failed to undo sale %d of car %d: %v", sale.ID, car.ID, err)
+	}
+}
+
+// applySale validates the input and fills in the sale details
+func (u *CarUsecase) applySale(car *entities.Car, sale *entities.Sale, in SaleInput) error {
+	sale.FinalPrice = in.FinalPrice
+	if sale.FinalPrice == 0 {
+		sale.FinalPrice = car.Price
+	}
+	if sale.FinalPrice < 0 {
+		return errors.New("final_price must not be negative")
+	}
+
+	now := time.Now()
+	sale.SoldAt = now
+	if in.SoldAt != nil {
+		sale.SoldAt = *in.SoldAt
+	}
+	if sale.SoldAt.After(now.Add(5 * time.Minute)) {
+		return errors.New("sold_at must not be in the future")
+	}
+	if sale.SoldAt.Before(car.CreatedAt) {
+		return errors.New("sold_at must not be before the car was listed")
+	}
+
+	sale.BuyerID, sale.LeadID = nil, nil
+	if in.BuyerID != nil && *in.BuyerID != 0 {
+		if *in.BuyerID == car.Dealer.UserID {
+			return errors.New("buyer_id must not be the dealer")
+		}
+		if _, err := u.UserRepo.FindByID(*in.BuyerID); err != nil {
+			return errors.New("buyer not found")
+		}
+		buyerID := *in.BuyerID
+		sale.BuyerID = &buyerID
+	}
+
+	source := strings.TrimSpace(in.LeadSource)
+	if source != "" && !isSaleSource(source) {
+		return fmt.Errorf("lead_source must be one of %s", strings.Join(entities.SaleSources, ", "))
+	}
+	// The buyer's lead for the car tells where the sale came from
+	if sale.BuyerID != nil && u.LeadRepo != nil {
+		var lead entities.Lead
+		if err := u.LeadRepo.FindLatest(car.ID, *sale.BuyerID, &lead); err == nil {
+			sale.LeadID = &lead.ID
+			if source == "" && isSaleSource(lead.ContactVia) {
+				source = lead.ContactVia
+			}
+		}
+	}
+	if source == "" && sale.ReservationID != nil {
+		source = entities.SaleSourceReservation
+	}
+	sale.LeadSource = source
+	sale.Note = strings.TrimSpace(in.Note)
+
+	// Days on market count from the first approval
+	sale.ListedAt = car.CreatedAt
+	if u.StatusHistoryRepo != nil {
+		if at, err := u.StatusHistoryRepo.FirstApprovedAt(car.ID); err == nil && at != nil {
+			sale.ListedAt = *at
+		}
+	}
+	sale.DaysOnMarket = int(math.Max(0, sale.SoldAt.Sub(sale.ListedAt).Hours()/24))
+	return nil
+}
+
+func isSaleSource(source string) bool {
+	for _, s := range entities.SaleSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------- Dealer sales ----------
+
+// loadSale returns a sale of the dealer
+func (u *CarUsecase) loadSale(dealerID, saleID uint) (*entities.Sale, error) {
+	var sale entities.Sale
+	if err := u.SaleRepo.FindByID(saleID, &sale); err != nil || sale.DealerID != dealerID {
+		return nil, errors.New("sale not found")
+	}
+	return &sale, nil
+}
+
+// GetSales lists the dealer's sales between two days (YYYY-MM-DD, default the last 30 days)
+func (u *CarUsecase) GetSales(dealerID uint, fromStr, toStr string) ([]*entities.Sale, error) {
+	from, to, err := parseDateRange(fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+	sales := []*entities.Sale{}
+	if err := u.SaleRepo.FindByDealerID(dealerID, from, to.AddDate(0, 0, 1), &sales); err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
+
+// SaleCorrection holds the sale details a dealer corrects; fields left out
+// keep their recorded value. A buyer_id of 0 removes the buyer.
+type SaleCorrection struct {
+	FinalPrice *float64   `json:"final_price"`
+	SoldAt     *time.Time `json:"sold_at"`
+	BuyerID    *uint      `json:"buyer_id"`
+	LeadSource *string    `json:"lead_source"`
+	Note       *string    `json:"note"`
+}
+
+// merge returns the sale details with the corrected fields applied
+func (c SaleCorrection) merge(sale *entities.Sale) SaleInput {
+	soldAt := sale.SoldAt
+	in := SaleInput{
+		FinalPrice:    sale.FinalPrice,
+		SoldAt:        &soldAt,
+		BuyerID:       sale.BuyerID,
+		LeadSource:    sale.LeadSource,
+		Note:          sale.Note,
+		ReservationID: sale.ReservationID,
+	}
+	if c.FinalPrice != nil {
+		in.FinalPrice = *c.FinalPrice
+	}
+	if c.SoldAt != nil {
+		in.SoldAt = c.SoldAt
+	}
+	if c.BuyerID != nil {
+		in.BuyerID = c.BuyerID
+	}
+	if c.LeadSource != nil {
+		in.LeadSource = *c.LeadSource
+	}
+	if c.Note != nil {
+		in.Note = *c.Note
+	}
+	return in
+}
+
+// CorrectSale updates the details of a sale sent by the dealer
+func (u *CarUsecase) CorrectSale(dealerID, saleID uint, c SaleCorrection) (*entities.Sale, error) {
+	sale, err := u.loadSale(dealerID, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if c.FinalPrice != nil && *c.FinalPrice <= 0 {
+		return nil, errors.New("final_price must be greater than 0")
+	}
+	in := c.merge(sale)
+	if err := u.applySale(&sale.Car, sale, in); err != nil {
+		return nil, err
+	}
+	if err := u.SaleRepo.Update(sale); err != nil {
+		return nil, err
+	}
+	return sale, nil
+}
+
+// UndoSale removes a sale recorded by mistake. A car that is still sold goes back on sale.
+func (u *CarUsecase) UndoSale(dealerID, saleID uint, actor Actor) error {
+	sale, err := u.loadSale(dealerID, saleID)
+	if err != nil {
+		return err
+	}
+	if sale.Car.Status == entities.CarStatusSold {
+		var current entities.Sale
+		if err := u.SaleRepo.FindActiveByCarID(sale.CarID, &current); err == nil && current.ID == sale.ID {
+			// undoSale drops the record once the car is back on sale
+			return u.Transition(&sale.Car, entities.CarStatusSelling, actor, "sale undone")
+		}
+	}
+	return u.SaleRepo.Delete(sale.ID)
+}
+
+// SourceConversion compares the leads and sales of one lead source
+type SourceConversion struct {
+	Source      string  `json:"source"`
+	Leads       int64   `json:"leads"`
+	Sales       int64   `json:"sales"`
+	RatePercent float64 `json:"rate_percent"`
+}
+
+// SalesStats summarizes a dealer's sales and lead conversion over a period
+type SalesStats struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	repositories.SaleTotals
+	Leads          int64              `json:"leads"`
+	ConversionRate float64            `json:"conversion_rate"` // sales per lead, in percent
+	BySource       []SourceConversion `json:"by_source"`
+}
+
+// GetSalesStats returns the dealer's sales totals, days on market and lead
+// conversion per source between two days (YYYY-MM-DD, default the last 30 days)
+func (u *CarUsecase) GetSalesStats(dealerID uint, fromStr, toStr string) (*SalesStats, error) {
+	from, to, err := parseDateRange(fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+	end := to.AddDate(0, 0, 1)
+
+	totals, err := u.SaleRepo.Totals(dealerID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	sales, err := u.SaleRepo.CountBySource(dealerID, from, end)
+	if err != nil {
+		return nil, err
+	}
+	leads, err := u.LeadRepo.CountBySource(dealerID, from, end)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &SalesStats{
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+		SaleTotals: *totals,
+		BySource:   []SourceConversion{},
+	}
+	index := map[string]int{}
+	row := func(source string) *SourceConversion {
+		i, ok := index[source]
+		if !ok {
+			i = len(stats.BySource)
+			index[source] = i
+			stats.BySource = append(stats.BySource, SourceConversion{Source: source})
+		}
+		return &stats.BySource[i]
+	}
+	for _, l := range leads {
+		row(l.Source).Leads = l.Count
+		stats.Leads += l.Count
+	}
+	for _, s := range sales {
+		row(s.Source).Sales = s.Count
+	}
+	for i := range stats.BySource {
+		stats.BySource[i].RatePercent = percent(stats.BySource[i].Sales, stats.BySource[i].Leads)
+	}
+	stats.ConversionRate = percent(stats.Count, stats.Leads)
+	stats.Revenue = math.Round(stats.Revenue)
+	stats.AvgPrice = math.Round(stats.AvgPrice)
+	stats.AvgDaysOnMarket = math.Round(stats.AvgDaysOnMarket*10) / 10
+	stats.AvgDiscountPercent = math.Round(stats.AvgDiscountPercent*10) / 10
+	return stats, nil
+}
+
+func percent(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*1000) / 10
+}
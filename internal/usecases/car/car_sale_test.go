@@ -0,0 +1,40 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"testing"
+	"time"
+)
+
+func TestSaleCorrectionKeepsOmittedFields(t *testing.T) {
+	soldAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	buyerID, reservationID := uint(9), uint(4)
+	sale := &entities.Sale{
+		FinalPrice:    450000,
+		SoldAt:        soldAt,
+		BuyerID:       &buyerID,
+		LeadSource:    "chat",
+		Note:          "cash",
+		ReservationID: &reservationID,
+	}
+
+	in := SaleCorrection{}.merge(sale)
+	if in.FinalPrice != 450000 || !in.SoldAt.Equal(soldAt) || in.BuyerID == nil || *in.BuyerID != 9 ||
+		in.LeadSource != "chat" || in.Note != "cash" || in.ReservationID != &reservationID {
+		t.Errorf("empty correction changed the sale: %+v", in)
+	}
+
+	price, note := 430000.0, ""
+	in = SaleCorrection{FinalPrice: &price, Note: &note}.merge(sale)
+	if in.FinalPrice != 430000 || in.Note != "" {
+		t.Errorf("sent fields were not applied: %+v", in)
+	}
+	if !in.SoldAt.Equal(soldAt) || *in.BuyerID != 9 || in.LeadSource != "chat" {
+		t.Errorf("omitted fields were overwritten: %+v", in)
+	}
+
+	noBuyer := uint(0)
+	if in = (SaleCorrection{BuyerID: &noBuyer}).merge(sale); *in.BuyerID != 0 {
+		t.Errorf("buyer_id 0 did not remove the buyer: %v", *in.BuyerID)
+	}
+}
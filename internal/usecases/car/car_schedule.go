@@ -0,0 +1,118 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// maxPublishDelay bounds how far ahead a listing can be scheduled
+const maxPublishDelay = 90 * 24 * time.Hour
+
+// isScheduled reports whether the car has a publish time still in the future
+func isScheduled(car *entities.Car, now time.Time) bool {
+	return car.PublishAt != nil && car.PublishAt.After(now)
+}
+
+func validatePublishAt(publishAt *time.Time) error {
+	if publishAt == nil {
+		return nil
+	}
+	now := time.Now()
+	if !publishAt.After(now) {
+		return errors.New("publish_at must be in the future")
+	}
+	if publishAt.After(now.Add(maxPublishDelay)) {
+		return errors.New("publish_at must be within 90 days")
+	}
+	return nil
+}
+
+// SchedulePublish sets (or clears, with nil) the publish time of a car that is
+// not live yet: a draft is submitted for review at that time, a pending or
+// approved car becomes visible at that time once approved
+func (u *CarUsecase) SchedulePublish(carID uint, publishAt *time.Time) (*entities.Car, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return nil, errors.New("car not found")
+	}
+
+	now := time.Now()
+	live := entities.IsPublicStatus(car.Status)
+	if car.Status != entities.CarStatusDraft && car.Status != entities.CarStatusPending && !(live && isScheduled(&car, now)) {
+		return nil, fmt.Errorf("a %s listing cannot be scheduled", car.Status)
+	}
+	if err := validatePublishAt(publishAt); err != nil {
+		return nil, err
+	}
+
+	car.PublishAt = publishAt
+	fields := map[string]interface{}{"publish_at": publishAt}
+	if live {
+		// The listing lifetime starts when the car is published
+		start := now
+		if publishAt != nil {
+			start = *publishAt
+		}
+		car.ExpiresAt = listingExpiry(start)
+		fields["expires_at"] = car.ExpiresAt
+	}
+	if err := u.CarRepo.UpdateFields(&car, fields); err != nil {
+		return nil, err
+	}
+
+	if live && publishAt == nil {
+		u.announce(car.ID)
+	}
+	return &car, nil
+}
+
+// PublishScheduledCars submits drafts whose publish time has come for review and
+// announces approved cars that just became visible (background job). The
+// schedule lives on the car row, so nothing is lost when the server restarts.
+func (u *CarUsecase) PublishScheduledCars() error {
+	var drafts []*entities.Car
+	if err := u.CarRepo.FindDueDrafts(&drafts); err != nil {
+		return err
+	}
+	for _, c := range drafts {
+		// The schedule is used up; the car goes live as soon as it is approved.
+		// publish_at is cleared in the status update, so the draft is not
+		// submitted again if the dealer takes it back.
+		c.PublishAt = nil
+		if err := u.transition(c, entities.CarStatusPending, Actor{Role: ActorSystem}, "scheduled publish", nil, "publish_at"); err != nil {
+			log.Printf("scheduled submission failed for car %d: %v", c.ID, err)
+			continue
+		}
+		u.notifyDealer(c, "listing_submitted", "ส่งประกาศให้ตรวจสอบแล้ว",
+			fmt.Sprintf("%s %s ปี %d ถึงเวลาเผยแพร่ตามที่ตั้งไว้และถูกส่งให้ผู้ดูแลตรวจสอบแล้ว", c.Brand, c.ModelName, c.Year))
+	}
+
+	var due []*entities.Car
+	if err := u.CarRepo.FindDuePublications(&due); err != nil {
+		return err
+	}
+	for _, c := range due {
+		if err := u.CarRepo.ClearPublishAt(c.ID); err != nil {
+			return err
+		}
+		u.announce(c.ID)
+		u.notifyDealer(c, "listing_published", "ประกาศเผยแพร่แล้ว",
+			fmt.Sprintf("%s %s ปี %d แสดงในหน้าประกาศแล้วตามเวลาที่ตั้งไว้", c.Brand, c.ModelName, c.Year))
+	}
+	return nil
+}
+
+// announce alerts users whose saved searches match a car that just became public
+func (u *CarUsecase) announce(carID uint) {
+	if u.SavedSearchUsecase == nil {
+		return
+	}
+	go func() {
+		if err := u.SavedSearchUsecase.MatchNewCar(carID); err != nil {
+			log.Printf("saved search matching failed for car %d: %v", carID, err)
+		}
+	}()
+}
@@ -0,0 +1,95 @@
+package car
+
+import (
+	"testing"
+	"time"
+
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestValidatePublishAt(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	soon := time.Now().Add(time.Hour)
+	late := time.Now().Add(maxPublishDelay + time.Hour)
+
+	if err := validatePublishAt(nil); err != nil {
+		t.Errorf("nil publish_at: %v", err)
+	}
+	if err := validatePublishAt(&soon); err != nil {
+		t.Errorf("publish_at in an hour: %v", err)
+	}
+	if err := validatePublishAt(&past); err == nil {
+		t.Error("publish_at in the past was accepted")
+	}
+	if err := validatePublishAt(&late); err == nil {
+		t.Error("publish_at beyond 90 days was accepted")
+	}
+}
+
+// A scheduled draft is submitted once. When the dealer takes it back to draft,
+// the used-up schedule must not submit it again.
+func TestPublishScheduledDraftThenWithdraw(t *testing.T) {
+	db, mock := mockDB(t)
+	u := &CarUsecase{CarRepo: &repositories.CarRepository{DB: db}}
+	anyArg := sqlmock.AnyArg()
+	due := time.Now().Add(-time.Minute)
+
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(status = \$1 AND publish_at <= NOW\(\)\)`).
+		WithArgs(entities.CarStatusDraft).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dealer_id", "status", "publish_at"}).
+			AddRow(1, 10, entities.CarStatusDraft, due))
+	mock.ExpectQuery(`SELECT \* FROM "dealers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 100))
+	// draft -> pending clears publish_at in the same statement as the status
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "cars" SET "updated_at"=\$1,"status"=\$2,"publish_at"=\$3,.* WHERE status = \$9`).
+		WithArgs(anyArg, entities.CarStatusPending, nil, anyArg, anyArg, anyArg, anyArg, anyArg, entities.CarStatusDraft, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "car_status_histories"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(status IN \(\$1,\$2\) AND publish_at <= NOW\(\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if err := u.PublishScheduledCars(); err != nil {
+		t.Fatal(err)
+	}
+
+	// pending -> draft by the dealer; the next run finds no due draft
+	car := &entities.Car{ID: 1, DealerID: 10, Status: entities.CarStatusPending}
+	expectTransition(mock, 1, entities.CarStatusPending, entities.CarStatusDraft, 1)
+	if err := u.Transition(car, entities.CarStatusDraft, Actor{UserID: 100, Role: ActorDealer}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(status = \$1 AND publish_at <= NOW\(\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(status IN \(\$1,\$2\) AND publish_at <= NOW\(\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if err := u.PublishScheduledCars(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishScheduledCarsAnnouncesDueCars(t *testing.T) {
+	db, mock := mockDB(t)
+	u := &CarUsecase{CarRepo: &repositories.CarRepository{DB: db}}
+
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(status = \$1 AND publish_at <= NOW\(\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE \(status IN \(\$1,\$2\) AND publish_at <= NOW\(\)\)`).
+		WithArgs(entities.CarStatusApproved, entities.CarStatusSelling).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dealer_id", "status"}).AddRow(4, 10, entities.CarStatusApproved))
+	mock.ExpectQuery(`SELECT \* FROM "dealers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 100))
+	mock.ExpectExec(`UPDATE "cars" SET "publish_at"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs(nil, sqlmock.AnyArg(), 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := u.PublishScheduledCars(); err != nil {
+		t.Fatal(err)
+	}
+}
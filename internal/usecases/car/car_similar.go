@@ -0,0 +1,103 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+// SimilarCar is a recommended car with its similarity score
+type SimilarCar struct {
+	Car   *entities.Car `json:"car"`
+	Score float64       `json:"score"`
+}
+
+const similarCandidateLimit = 200
+
+// coFavoriteWeight is added on top of repositories.SimilarWeights for cars that
+// three or more users favorited together with the given car
+const coFavoriteWeight = 1.5
+
+// GetSimilarCars scores public cars against the given car by brand/model,
+// car type, price band and year, plus co-favorite signals, best first.
+// The given car must be visible to the user, like on the detail page.
+func (u *CarUsecase) GetSimilarCars(carID uint, limit int, userID uint, role string) ([]SimilarCar, error) {
+	if limit <= 0 || limit > 20 {
+		limit = 8
+	}
+
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil || !CanView(&car, userID, role) {
+		return nil, errors.New("car not found")
+	}
+
+	var candidates []*entities.Car
+	if err := u.CarRepo.FindSimilarCandidates(&car, similarCandidateLimit, &candidates); err != nil {
+		return nil, err
+	}
+
+	coFavorites, err := u.FavoriteRepo.CoFavoriteCounts(car.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []SimilarCar{}
+	for _, c := range candidates {
+		score := similarityScore(&car, c, coFavorites[c.ID])
+		if score > 0 {
+			results = append(results, SimilarCar{Car: c, Score: math.Round(score*100) / 100})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// similarityScore weighs how close b is to a; higher is more similar.
+// It must rank like FindSimilarCandidates, which uses the same weights in SQL.
+func similarityScore(a, b *entities.Car, coFavorites int) float64 {
+	w := repositories.SimilarWeights
+	score := 0.0
+
+	if strings.EqualFold(a.Brand, b.Brand) {
+		score += w.Brand
+		if strings.EqualFold(strings.TrimSpace(a.ModelName), strings.TrimSpace(b.ModelName)) {
+			score += w.Model
+		}
+	}
+	if a.CarType != "" && strings.EqualFold(a.CarType, b.CarType) {
+		score += w.CarType
+	}
+
+	// within ±PriceBand of the price scores up to w.Price
+	if a.Price > 0 {
+		diff := math.Abs(a.Price-b.Price) / a.Price
+		score += w.Price * math.Max(0, 1-diff/w.PriceBand)
+	}
+
+	// within ±YearBand years scores up to w.Year
+	if a.Year > 0 && b.Year > 0 {
+		diff := math.Abs(float64(a.Year - b.Year))
+		score += w.Year * math.Max(0, 1-diff/w.YearBand)
+	}
+
+	if a.FuelType != "" && strings.EqualFold(a.FuelType, b.FuelType) {
+		score += w.Fuel
+	}
+	if a.Transmission != "" && strings.EqualFold(a.Transmission, b.Transmission) {
+		score += w.Transmission
+	}
+
+	// users who liked a also liked b
+	score += coFavoriteWeight * math.Min(1, float64(coFavorites)/3)
+
+	return score
+}
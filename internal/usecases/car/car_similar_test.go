@@ -0,0 +1,25 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"testing"
+)
+
+func TestSimilarityScoreRanksSameModelFirst(t *testing.T) {
+	base := &entities.Car{Brand: "Toyota", ModelName: "Camry", CarType: "sedan", Price: 800000, Year: 2020}
+
+	sameModel := &entities.Car{Brand: "toyota", ModelName: "Camry ", CarType: "sedan", Price: 820000, Year: 2019}
+	sameType := &entities.Car{Brand: "Honda", ModelName: "Accord", CarType: "sedan", Price: 800000, Year: 2020}
+	unrelated := &entities.Car{Brand: "Isuzu", ModelName: "D-Max", CarType: "pickup", Price: 2000000, Year: 2010}
+
+	a, b, c := similarityScore(base, sameModel, 0), similarityScore(base, sameType, 0), similarityScore(base, unrelated, 0)
+	if !(a > b && b > c) {
+		t.Errorf("scores = %.2f, %.2f, %.2f; want same model > same type > unrelated", a, b, c)
+	}
+	if c != 0 {
+		t.Errorf("unrelated car scored %.2f, want 0", c)
+	}
+	if withFavorites := similarityScore(base, sameType, 3); withFavorites <= b {
+		t.Errorf("co-favorites did not raise the score: %.2f <= %.2f", withFavorites, b)
+	}
+}
@@ -0,0 +1,255 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Actor roles allowed to change a car status
+const (
+	ActorDealer = "dealer"
+	ActorAdmin  = "admin"
+	ActorSystem = "system"
+)
+
+// Actor identifies who performs a status transition
+type Actor struct {
+	UserID uint
+	Role   string
+}
+
+// carStatusTransitions lists, per current status, the next statuses and the roles allowed to move there
+var carStatusTransitions = map[string]map[string][]string{
+	entities.CarStatusDraft: {
+		// Submitting for review, by hand or at the scheduled publish time
+		entities.CarStatusPending: {ActorDealer, ActorSystem},
+	},
+	entities.CarStatusPending: {
+		entities.CarStatusDraft:           {ActorDealer},
+		entities.CarStatusApproved:        {ActorAdmin},
+		entities.CarStatusRejected:        {ActorAdmin},
+		entities.CarStatusUnpublished:     {ActorDealer},
+		entities.CarStatusDeleteRequested: {ActorDealer},
+	},
+	entities.CarStatusApproved: {
+		entities.CarStatusPending:         {ActorSystem}, // re-moderation of material edits
+		entities.CarStatusExpired:         {ActorSystem},
+		entities.CarStatusSelling:         {ActorDealer},
+		entities.CarStatusReserved:        {ActorDealer},
+		entities.CarStatusSold:            {ActorDealer},
+		entities.CarStatusRejected:        {ActorAdmin},
+		entities.CarStatusUnpublished:     {ActorDealer, ActorAdmin},
+		entities.CarStatusDeleteRequested: {ActorDealer},
+	},
+	entities.CarStatusSelling: {
+		entities.CarStatusPending:         {ActorSystem},
+		entities.CarStatusExpired:         {ActorSystem},
+		entities.CarStatusReserved:        {ActorDealer},
+		entities.CarStatusSold:            {ActorDealer},
+		entities.CarStatusRejected:        {ActorAdmin},
+		entities.CarStatusUnpublished:     {ActorDealer, ActorAdmin},
+		entities.CarStatusDeleteRequested: {ActorDealer},
+	},
+	entities.CarStatusReserved: {
+		entities.CarStatusPending:     {ActorSystem},
+		entities.CarStatusSelling:     {ActorDealer, ActorSystem}, // released, or the reservation ran out
+		entities.CarStatusSold:        {ActorDealer},
+		entities.CarStatusUnpublished: {ActorDealer, ActorAdmin},
+	},
+	entities.CarStatusSold: {
+		entities.CarStatusSelling:         {ActorDealer},
+		entities.CarStatusUnpublished:     {ActorDealer, ActorAdmin},
+		entities.CarStatusDeleteRequested: {ActorDealer},
+	},
+	entities.CarStatusRejected: {
+		entities.CarStatusDraft:           {ActorDealer},
+		entities.CarStatusPending:         {ActorDealer},
+		entities.CarStatusApproved:        {ActorAdmin},
+		entities.CarStatusDeleteRequested: {ActorDealer},
+	},
+	entities.CarStatusUnpublished: {
+		// Republishing goes back through moderation
+		entities.CarStatusDraft:           {ActorDealer},
+		entities.CarStatusPending:         {ActorDealer},
+		entities.CarStatusDeleteRequested: {ActorDealer},
+	},
+	entities.CarStatusExpired: {
+		// Renewing restores the moderated listing
+		entities.CarStatusApproved:        {ActorDealer},
+		entities.CarStatusSold:            {ActorDealer},
+		entities.CarStatusUnpublished:     {ActorDealer, ActorAdmin},
+		entities.CarStatusDeleteRequested: {ActorDealer},
+	},
+	entities.CarStatusDeleteRequested: {
+		// The dealer may withdraw the request; admins delete the car outright
+		entities.CarStatusUnpublished: {ActorDealer},
+	},
+}
+
+// CanTransition reports whether role may move a car from one status to another
+func CanTransition(from, to, role string) error {
+	next, ok := carStatusTransitions[from]
+	if !ok {
+		return fmt.Errorf("unknown current status %q", from)
+	}
+	if _, ok := carStatusTransitions[to]; !ok {
+		return fmt.Errorf("unknown status %q", to)
+	}
+	if from == to {
+		return fmt.Errorf("car is already %s", to)
+	}
+
+	roles, ok := next[to]
+	if !ok {
+		return fmt.Errorf("cannot change status from %s to %s", from, to)
+	}
+	for _, r := range roles {
+		if r == role {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not allowed to change status from %s to %s", role, from, to)
+}
+
+// ChangeStatus loads a car and moves it to a new status
+func (u *CarUsecase) ChangeStatus(carID uint, to string, actor Actor, reason string) (*entities.Car, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return nil, errors.New("car not found")
+	}
+	if err := u.Transition(&car, to, actor, reason); err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// Transition validates and applies a status change to a loaded car. Only the
+// status columns (statusColumns in repositories/Car.go) are written, together
+// with the status history entry; other field changes must be saved separately.
+func (u *CarUsecase) Transition(car *entities.Car, to string, actor Actor, reason string) error {
+	return u.transition(car, to, actor, reason, nil)
+}
+
+// transition is Transition with the sale to record when the car is sold; a
+// sale with default details is recorded when sale is nil. Columns in extra are
+// saved together with the status.
+func (u *CarUsecase) transition(car *entities.Car, to string, actor Actor, reason string, sale *entities.Sale, extra ...string) error {
+	if to == "" {
+		return errors.New("status is required")
+	}
+	from := car.Status
+	if err := CanTransition(from, to, actor.Role); err != nil {
+		return err
+	}
+	if to == entities.CarStatusSold {
+		var err error
+		if sale, err = u.prepareSale(car, actor, sale); err != nil {
+			return err
+		}
+	} else {
+		sale = nil
+	}
+
+	history := applyTransition(car, to, actor, reason)
+	if err := u.CarRepo.UpdateStatusWithSale(car, history, sale, extra...); err != nil {
+		return err
+	}
+
+	if from == entities.CarStatusReserved {
+		u.settleReservation(car, to, actor, reason)
+	}
+	if from == entities.CarStatusSold && to == entities.CarStatusSelling {
+		u.undoSale(car)
+	}
+	return nil
+}
+
+// PrepareTransition validates a status change and applies it to a loaded car in
+// memory without saving it. The returned history entry must be saved with the
+// status columns in the caller's transaction (see ReservationRepository.EndHold).
+// Selling a car goes through MarkSold, which records the sale.
+func PrepareTransition(car *entities.Car, to string, actor Actor, reason string) (*entities.CarStatusHistory, error) {
+	if to == "" {
+		return nil, errors.New("status is required")
+	}
+	if to == entities.CarStatusSold {
+		return nil, errors.New("use MarkSold to sell a car")
+	}
+	if err := CanTransition(car.Status, to, actor.Role); err != nil {
+		return nil, err
+	}
+	return applyTransition(car, to, actor, reason), nil
+}
+
+// applyTransition sets the new status and the columns that go with it, and
+// returns the history entry of the change
+func applyTransition(car *entities.Car, to string, actor Actor, reason string) *entities.CarStatusHistory {
+	from := car.Status
+	car.Status = to
+	// Each approval (or renewal) starts a new listing lifetime, as does going
+	// live again after the old one ran out. A scheduled car's lifetime starts
+	// when it is published.
+	now := time.Now()
+	if to == entities.CarStatusApproved || (entities.IsPublicStatus(to) && (car.ExpiresAt == nil || !car.ExpiresAt.After(now))) {
+		start := now
+		if isScheduled(car, now) {
+			start = *car.PublishAt
+		}
+		car.ExpiresAt = listingExpiry(start)
+		car.ExpiryWarnedAt = nil
+	}
+	if from == entities.CarStatusReserved {
+		car.ReservedUntil = nil
+	}
+	return &entities.CarStatusHistory{
+		CarID:      car.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actor.UserID,
+		ActorRole:  actor.Role,
+		Reason:     reason,
+	}
+}
+
+// settleReservation closes the reservation holding a car that leaves the
+// reserved status by another way than the reservation endpoints: it is
+// completed when the car is sold and released otherwise.
+func (u *CarUsecase) settleReservation(car *entities.Car, to string, actor Actor, reason string) {
+	if u.ReservationRepo == nil {
+		return
+	}
+	var reservation entities.Reservation
+	if err := u.ReservationRepo.FindAccepted(car.ID, &reservation); err != nil {
+		return // reserved by hand, without a reservation
+	}
+
+	from := reservation.Status
+	reservation.Status = entities.ReservationReleased
+	if to == entities.CarStatusSold {
+		reservation.Status = entities.ReservationCompleted
+	}
+	if reason == "" {
+		reason = "car status changed to " + to
+	}
+	if err := u.ReservationRepo.UpdateStatus(&reservation, &entities.ReservationEvent{
+		FromStatus: from,
+		ToStatus:   reservation.Status,
+		ActorID:    actor.UserID,
+		ActorRole:  actor.Role,
+		Reason:     reason,
+	}); err != nil {
+		log.Printf("failed to settle reservation %d of car %d: %v", reservation.ID, car.ID, err)
+	}
+}
+
+// GetStatusHistory returns the status transitions of a car, oldest first
+func (u *CarUsecase) GetStatusHistory(carID uint) ([]*entities.CarStatusHistory, error) {
+	var history []*entities.CarStatusHistory
+	if err := u.StatusHistoryRepo.FindByCarID(carID, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,43 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to, role string
+		ok             bool
+	}{
+		{entities.CarStatusDraft, entities.CarStatusPending, ActorDealer, true},
+		{entities.CarStatusPending, entities.CarStatusApproved, ActorAdmin, true},
+		{entities.CarStatusPending, entities.CarStatusApproved, ActorDealer, false},
+		{entities.CarStatusApproved, entities.CarStatusExpired, ActorSystem, true},
+		{entities.CarStatusApproved, entities.CarStatusExpired, ActorDealer, false},
+		{entities.CarStatusReserved, entities.CarStatusSelling, ActorSystem, true},
+		{entities.CarStatusDraft, entities.CarStatusSold, ActorDealer, false},
+		{entities.CarStatusSelling, entities.CarStatusSelling, ActorDealer, false},
+		{entities.CarStatusSelling, "flying", ActorDealer, false},
+		{"", entities.CarStatusSelling, ActorDealer, false},
+	}
+	for _, tt := range tests {
+		err := CanTransition(tt.from, tt.to, tt.role)
+		if (err == nil) != tt.ok {
+			t.Errorf("CanTransition(%q, %q, %q) = %v, want ok=%v", tt.from, tt.to, tt.role, err, tt.ok)
+		}
+	}
+}
+
+func TestEveryTransitionTargetIsKnown(t *testing.T) {
+	for from, next := range carStatusTransitions {
+		for to, roles := range next {
+			if _, ok := carStatusTransitions[to]; !ok {
+				t.Errorf("%s -> %s: target has no transitions entry", from, to)
+			}
+			if len(roles) == 0 {
+				t.Errorf("%s -> %s: no role may make this transition", from, to)
+			}
+		}
+	}
+}
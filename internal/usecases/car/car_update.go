@@ -0,0 +1,256 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/usecases/catalog"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// CarUpdate holds the dealer-editable fields of a car. Only non-nil fields are
+// changed; counters, promotion, moderation and status are server-owned.
+type CarUpdate struct {
+	Brand        *string  `json:"brand,omitempty"`
+	ModelName    *string  `json:"model_name,omitempty"`
+	Year         *int     `json:"year,omitempty"`
+	Mileage      *int     `json:"mileage,omitempty"`
+	Price        *float64 `json:"price,omitempty"`
+	CarType      *string  `json:"car_type,omitempty"`
+	FuelType     *string  `json:"fuel_type,omitempty"`
+	Transmission *string  `json:"transmission,omitempty"`
+	Color        *string  `json:"color,omitempty"`
+	Description  *string  `json:"description,omitempty"`
+	BrandID      *uint    `json:"brand_id,omitempty"`
+	ModelID      *uint    `json:"model_id,omitempty"`
+	TrimID       *uint    `json:"trim_id,omitempty"`
+}
+
+// apply copies the present fields onto car and returns the changed columns
+func (in *CarUpdate) apply(car *entities.Car) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+
+	for _, f := range []struct {
+		column string
+		value  *string
+		target *string
+	}{
+		{"brand", in.Brand, &car.Brand},
+		{"model_name", in.ModelName, &car.ModelName},
+		{"car_type", in.CarType, &car.CarType},
+		{"fuel_type", in.FuelType, &car.FuelType},
+		{"transmission", in.Transmission, &car.Transmission},
+		{"color", in.Color, &car.Color},
+		{"description", in.Description, &car.Description},
+	} {
+		if f.value != nil {
+			*f.target = strings.TrimSpace(*f.value)
+			fields[f.column] = *f.target
+		}
+	}
+	if in.Year != nil {
+		car.Year = *in.Year
+		fields["year"] = car.Year
+	}
+	if in.Mileage != nil {
+		car.Mileage = *in.Mileage
+		fields["mileage"] = car.Mileage
+	}
+	if in.Price != nil {
+		car.Price = *in.Price
+		fields["price"] = car.Price
+	}
+	for _, f := range []struct {
+		column string
+		value  *uint
+		target **uint
+	}{
+		{"brand_id", in.BrandID, &car.BrandID},
+		{"model_id", in.ModelID, &car.ModelID},
+		{"trim_id", in.TrimID, &car.TrimID},
+	} {
+		if f.value != nil {
+			id := *f.value
+			*f.target = &id
+			fields[f.column] = id
+		}
+	}
+	// Text edits without an ID drop the old catalog link so the text is matched again
+	if in.Brand != nil && in.BrandID == nil {
+		car.BrandID = nil
+	}
+	if (in.Brand != nil || in.BrandID != nil || in.ModelName != nil) && in.ModelID == nil {
+		car.ModelID = nil
+	}
+	if (in.Brand != nil || in.BrandID != nil || in.ModelName != nil || in.ModelID != nil) && in.TrimID == nil {
+		car.TrimID = nil
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("no fields to update")
+	}
+	// Only the fields being changed are checked, so legacy data doesn't block edits
+	for _, fe := range ValidateCar(car) {
+		if _, ok := fields[fe.Field]; ok {
+			return nil, fe
+		}
+	}
+	return fields, nil
+}
+
+// UpdateCar applies a partial update of the dealer-editable fields. On a live
+// car, edits to material fields go through moderation again (see car_revision.go).
+func (u *CarUsecase) UpdateCar(carID uint, in CarUpdate, actor Actor) (*entities.Car, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return nil, errors.New("car not found")
+	}
+
+	// Validate the whole request before anything is stored
+	check := car
+	checkFields, err := in.apply(&check)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.normalizeUpdate(&check, checkFields); err != nil {
+		return nil, err
+	}
+
+	sendBack := false
+	if needsReview(&car) {
+		live, held := in.split(materialFields())
+		if !held.isEmpty() {
+			if remoderationMode() == RemoderationRevision {
+				if err := u.queueRevision(&car, held, actor); err != nil {
+					return nil, err
+				}
+				in = live
+			} else {
+				sendBack = true
+			}
+		}
+	}
+
+	if !in.isEmpty() {
+		if err := u.saveUpdate(&car, in); err != nil {
+			return nil, err
+		}
+	}
+
+	if sendBack {
+		if err := u.Transition(&car, entities.CarStatusPending, Actor{Role: ActorSystem}, "material fields changed"); err != nil {
+			return nil, err
+		}
+	}
+	return &car, nil
+}
+
+// saveUpdate writes the fields of an update to the car and tracks price changes
+func (u *CarUsecase) saveUpdate(car *entities.Car, in CarUpdate) error {
+	oldPrice := car.Price
+	fields, err := u.prepareUpdate(car, in)
+	if err != nil {
+		return err
+	}
+
+	history := priceChange(car, oldPrice)
+	if err := u.CarRepo.UpdateFieldsWithPrice(car, fields, history); err != nil {
+		return err
+	}
+
+	if history != nil {
+		u.refreshPricing(car)
+		u.notifyPriceDrop(car, oldPrice)
+	}
+	return nil
+}
+
+// prepareUpdate applies an update to the car in memory and returns the columns to write
+func (u *CarUsecase) prepareUpdate(car *entities.Car, in CarUpdate) (map[string]interface{}, error) {
+	oldPrice := car.Price
+	fields, err := in.apply(car)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.normalizeUpdate(car, fields); err != nil {
+		return nil, err
+	}
+
+	// Price tracking fields are server-owned
+	if car.Price != oldPrice {
+		now := time.Now()
+		car.PreviousPrice = &oldPrice
+		car.PriceChangedAt = &now
+		fields["previous_price"] = car.PreviousPrice
+		fields["price_changed_at"] = car.PriceChangedAt
+	}
+	return fields, nil
+}
+
+// normalizeCatalog maps the car to the vehicle catalog (see usecases/catalog)
+func (u *CarUsecase) normalizeCatalog(car *entities.Car) error {
+	if u.CatalogUsecase == nil {
+		return nil
+	}
+	return u.CatalogUsecase.Normalize(car)
+}
+
+// normalizeUpdate maps the car to the catalog again when an update touches one
+// of its catalog fields or the year, and adds the resulting columns to fields
+func (u *CarUsecase) normalizeUpdate(car *entities.Car, fields map[string]interface{}) error {
+	touched := false
+	for _, column := range []string{"brand", "model_name", "year", "brand_id", "model_id", "trim_id", "car_type", "fuel_type", "transmission"} {
+		if _, ok := fields[column]; ok {
+			touched = true
+		}
+	}
+	if !touched || u.CatalogUsecase == nil {
+		return nil
+	}
+	if err := u.CatalogUsecase.Normalize(car); err != nil {
+		return err
+	}
+	for column, value := range catalog.Columns(car) {
+		fields[column] = value
+	}
+	return nil
+}
+
+// toMap returns the present fields keyed by column name
+func (in CarUpdate) toMap() map[string]interface{} {
+	values := map[string]interface{}{}
+	raw, _ := json.Marshal(in)
+	_ = json.Unmarshal(raw, &values)
+	return values
+}
+
+// carUpdateFromMap is the inverse of toMap
+func carUpdateFromMap(values map[string]interface{}) (CarUpdate, error) {
+	var in CarUpdate
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return in, err
+	}
+	err = json.Unmarshal(raw, &in)
+	return in, err
+}
+
+// split separates the fields listed in material from the rest of the update
+func (in CarUpdate) split(material map[string]bool) (live, held CarUpdate) {
+	liveValues, heldValues := map[string]interface{}{}, map[string]interface{}{}
+	for column, value := range in.toMap() {
+		if material[column] {
+			heldValues[column] = value
+		} else {
+			liveValues[column] = value
+		}
+	}
+	live, _ = carUpdateFromMap(liveValues)
+	held, _ = carUpdateFromMap(heldValues)
+	return live, held
+}
+
+func (in CarUpdate) isEmpty() bool {
+	return len(in.toMap()) == 0
+}
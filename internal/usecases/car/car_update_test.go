@@ -0,0 +1,77 @@
+package car
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"Backend_Go/internal/entities"
+)
+
+// serverOwnedBody tries to set every server-owned field next to a real edit
+const serverOwnedBody = `{
+	"price": 450000,
+	"id": 99, "dealer_id": 99, "status": "approved",
+	"views": 100000, "call_count": 500, "line_count": 500, "lead_count": 1000,
+	"is_promoted": true, "promoted_until": "2099-01-01T00:00:00Z",
+	"is_hidden": false, "flagged": false, "violation_reason": "",
+	"expires_at": "2099-01-01T00:00:00Z", "publish_at": "2020-01-01T00:00:00Z",
+	"reserved_until": "2099-01-01T00:00:00Z", "previous_price": 1
+}`
+
+func TestCarUpdateIgnoresServerOwnedFields(t *testing.T) {
+	var in CarUpdate
+	if err := json.Unmarshal([]byte(serverOwnedBody), &in); err != nil {
+		t.Fatal(err)
+	}
+	if got := in.toMap(); !reflect.DeepEqual(got, map[string]interface{}{"price": 450000.0}) {
+		t.Fatalf("decoded update = %v, want only the price", got)
+	}
+
+	expires := time.Now().Add(24 * time.Hour)
+	car := &entities.Car{
+		ID: 1, DealerID: 2, Status: entities.CarStatusPending, Price: 500000,
+		Brand: "Toyota", ModelName: "Yaris", Year: 2020, Mileage: 30000,
+		Views: 3, CallCount: 1, LeadCount: 2, IsHidden: true, Flagged: true,
+		ViolationReason: "fake photos", ExpiresAt: &expires,
+	}
+	before := *car
+
+	u := &CarUsecase{}
+	fields, err := u.prepareUpdate(car, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := map[string]bool{"price": true, "previous_price": true, "price_changed_at": true}
+	for column := range fields {
+		if !allowed[column] {
+			t.Errorf("update writes server-owned column %q", column)
+		}
+	}
+
+	after := *car
+	after.Price, after.PreviousPrice, after.PriceChangedAt = before.Price, nil, nil
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("server-owned fields changed:\nbefore %+v\nafter  %+v", before, *car)
+	}
+	if car.Price != 450000 || car.PreviousPrice == nil || *car.PreviousPrice != 500000 {
+		t.Errorf("price = %v, previous = %v; want 450000 and 500000", car.Price, car.PreviousPrice)
+	}
+}
+
+// Revisions store the changes as a map, so they are checked on the way back too
+func TestCarUpdateFromMapIgnoresServerOwnedFields(t *testing.T) {
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(serverOwnedBody), &values); err != nil {
+		t.Fatal(err)
+	}
+	in, err := carUpdateFromMap(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := in.toMap(); !reflect.DeepEqual(got, map[string]interface{}{"price": 450000.0}) {
+		t.Errorf("update from map = %v, want only the price", got)
+	}
+}
@@ -1,183 +1,419 @@
-package car
-
-import (
-	"Backend_Go/internal/entities"
-	"Backend_Go/internal/repositories"
-	"errors"
-	"time"
-)
-
-type CarUsecase struct {
-	CarRepo      *repositories.CarRepository
-	DealerRepo   *repositories.DealerRepository
-	LeadRepo     *repositories.LeadRepository
-	FavoriteRepo *repositories.FavoriteRepository
-}
-
-// ---------- Core ----------
-
-// ---------- Core ----------
-
-func (u *CarUsecase) CreateCar(car *entities.Car) error {
-	if car.DealerID == 0 {
-		return errors.New("dealer_id is required")
-	}
-
-	var dealer entities.Dealer
-	if err := u.DealerRepo.FindByID(car.DealerID, &dealer); err != nil {
-		return errors.New("dealer not found")
-	}
-
-	// Enforce default status
-	car.Status = "pending"
-	car.IsHidden = false
-
-	return u.CarRepo.Create(car)
-}
-
-// GetPublicCars returns only approved cars
-func (u *CarUsecase) GetPublicCars(cars *[]*entities.Car) error {
-	return u.CarRepo.FindPublic(cars)
-}
-
-// GetAdminCars returns all cars for admin dashboard
-func (u *CarUsecase) GetAdminCars(cars *[]*entities.Car) error {
-	return u.CarRepo.FindAll(cars)
-}
-
-func (u *CarUsecase) GetCarDetail(id uint) (*entities.Car, error) {
-	var car entities.Car
-	if err := u.CarRepo.FindByID(id, &car); err != nil {
-		return nil, err
-	}
-	return &car, nil
-}
-
-func (u *CarUsecase) UpdateCar(car *entities.Car) error {
-	if car.ID == 0 {
-		return errors.New("car_id is required")
-	}
-	// When updating, status might need to be reset to pending if critical info changes?
-	// For now, keep existing status logic or require separate flow.
-	// We will trust the passed entity, but the handler should be careful.
-	return u.CarRepo.Update(car)
-}
-
-// DeleteCarByUser requests deletion instead of immediate delete
-func (u *CarUsecase) DeleteCarByUser(carID uint, userID uint) error {
-	var dealer entities.Dealer
-	if err := u.DealerRepo.FindByUserID(userID, &dealer); err != nil {
-		return errors.New("forbidden")
-	}
-
-	var car entities.Car
-	if err := u.CarRepo.FindByID(carID, &car); err != nil {
-		return err
-	}
-
-	if car.DealerID != dealer.ID {
-		return errors.New("forbidden")
-	}
-
-	// Request delete
-	car.Status = "delete_requested"
-	return u.CarRepo.Update(&car)
-}
-
-// ---------- Business ----------
-
-// Admin: Set Status Directly
-func (u *CarUsecase) SetStatus(carID uint, status string) error {
-	if status == "" {
-		return errors.New("status is required")
-	}
-
-	var car entities.Car
-	if err := u.CarRepo.FindByID(carID, &car); err != nil {
-		return err
-	}
-
-	car.Status = status
-	return u.CarRepo.Update(&car)
-}
-
-// Admin: Approve
-func (u *CarUsecase) ApproveCar(carID uint) error {
-	return u.SetStatus(carID, "approved")
-}
-
-// Admin: Reject
-func (u *CarUsecase) RejectCar(carID uint, reason string) error {
-	var car entities.Car
-	if err := u.CarRepo.FindByID(carID, &car); err != nil {
-		return err
-	}
-	car.Status = "rejected"
-	car.ViolationReason = reason
-	return u.CarRepo.Update(&car)
-}
-
-// Admin: Confirm Delete
-func (u *CarUsecase) ConfirmDeleteCar(carID uint) error {
-	return u.CarRepo.Delete(carID)
-}
-
-func (u *CarUsecase) RecordContact(carID uint, dealerID uint, via string) error {
-	if dealerID == 0 {
-		return errors.New("dealer_id is required")
-	}
-
-	if via != "call" && via != "line" {
-		return errors.New("invalid contact method")
-	}
-
-	var car entities.Car
-	if err := u.CarRepo.FindByID(carID, &car); err != nil {
-		return err
-	}
-
-	if via == "call" {
-		car.CallCount++
-	} else {
-		car.LineCount++
-	}
-	car.LeadCount++
-
-	if err := u.CarRepo.Update(&car); err != nil {
-		return err
-	}
-
-	return u.LeadRepo.Create(&entities.Lead{
-		CarID:      carID,
-		DealerID:   dealerID,
-		ContactVia: via,
-	})
-}
-
-func (u *CarUsecase) GetStats(carID uint) (*entities.Car, error) {
-	return u.GetCarDetail(carID)
-}
-
-func (u *CarUsecase) PromoteCar(carID uint, days int) error {
-	if days <= 0 {
-		days = 7
-	}
-
-	var car entities.Car
-	if err := u.CarRepo.FindByID(carID, &car); err != nil {
-		return err
-	}
-
-	until := time.Now().Add(time.Duration(days) * 24 * time.Hour)
-	car.IsPromoted = true
-	car.PromotedUntil = &until
-
-	return u.CarRepo.Update(&car)
-}
-
-func (u *CarUsecase) GetCarsByDealer(dealerID uint, cars *[]*entities.Car) error {
-	if dealerID == 0 {
-		return errors.New("dealer_id is required")
-	}
-	return u.CarRepo.FindByDealerID(dealerID, cars)
-}
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/search"
+	"Backend_Go/internal/usecases/catalog"
+	"Backend_Go/internal/usecases/dealer"
+	"Backend_Go/internal/usecases/finance"
+	"Backend_Go/internal/usecases/notification"
+	savedsearch "Backend_Go/internal/usecases/saved_search"
+	"Backend_Go/internal/usecases/valuation"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type CarUsecase struct {
+	CarRepo             *repositories.CarRepository
+	DealerRepo          *repositories.DealerRepository
+	LeadRepo            *repositories.LeadRepository
+	FavoriteRepo        *repositories.FavoriteRepository
+	PriceHistoryRepo    *repositories.CarPriceHistoryRepository
+	StatusHistoryRepo   *repositories.CarStatusHistoryRepository
+	RevisionRepo        *repositories.CarRevisionRepository
+	ReservationRepo     *repositories.ReservationRepository
+	SaleRepo            *repositories.SaleRepository
+	UserRepo            repositories.UserRepository
+	StatRepo            *repositories.CarStatRepository
+	NotificationUsecase *notification.NotificationUsecase
+	DealerUsecase       *dealer.DealerUsecase
+	SavedSearchUsecase  *savedsearch.SavedSearchUsecase
+	CatalogUsecase      *catalog.CatalogUsecase
+	FinanceUsecase      *finance.FinanceUsecase
+	ValuationUsecase    *valuation.ValuationUsecase
+
+	viewQueue     chan queuedView
+	viewQueueOnce sync.Once
+}
+
+// ---------- Core ----------
+
+// ---------- Core ----------
+
+func (u *CarUsecase) CreateCar(car *entities.Car) error {
+	if err := u.PrepareCar(car); err != nil {
+		return err
+	}
+	if err := u.CarRepo.Create(car); err != nil {
+		return err
+	}
+	u.refreshPricing(car)
+	return nil
+}
+
+// PrepareCar normalizes and validates a new car and resets its server-owned
+// fields, without saving it. Inventory dry runs use it to check rows exactly
+// like CreateCar does.
+func (u *CarUsecase) PrepareCar(car *entities.Car) error {
+	if car.DealerID == 0 {
+		return errors.New("dealer_id is required")
+	}
+	if err := u.normalizeCatalog(car); err != nil {
+		return err
+	}
+	if errs := ValidateCar(car); len(errs) > 0 {
+		return errs[0]
+	}
+
+	var dealer entities.Dealer
+	if err := u.DealerRepo.FindByID(car.DealerID, &dealer); err != nil {
+		return errors.New("dealer not found")
+	}
+
+	// New cars go to moderation unless the dealer saves a draft
+	if car.Status != entities.CarStatusDraft {
+		car.Status = entities.CarStatusPending
+	}
+	car.IsHidden = false
+	if err := validatePublishAt(car.PublishAt); err != nil {
+		return err
+	}
+
+	// Counters, promotion and moderation fields are server-owned
+	car.Views, car.CallCount, car.LineCount, car.LeadCount = 0, 0, 0, 0
+	car.IsFeatured, car.IsPromoted, car.PromotedUntil = false, false, nil
+	car.Flagged, car.ViolationReason = false, ""
+	car.PreviousPrice, car.PriceChangedAt = nil, nil
+	car.ExpiresAt, car.ExpiryWarnedAt = nil, nil
+	car.MonthlyFrom, car.DealRating = nil, ""
+	return nil
+}
+
+// refreshPricing updates the "from ฿X/month" installment and the market deal
+// rating after a price change
+func (u *CarUsecase) refreshPricing(car *entities.Car) {
+	if u.FinanceUsecase != nil {
+		if err := u.FinanceUsecase.RefreshCar(car); err != nil {
+			log.Printf("finance refresh failed for car %d: %v", car.ID, err)
+		}
+	}
+	if u.ValuationUsecase != nil {
+		if err := u.ValuationUsecase.RefreshCar(car); err != nil {
+			log.Printf("deal rating refresh failed for car %d: %v", car.ID, err)
+		}
+	}
+}
+
+// GetPublicCars returns only approved cars
+func (u *CarUsecase) GetPublicCars(cars *[]*entities.Car) error {
+	return u.CarRepo.FindPublic(cars)
+}
+
+// CarSearchResult is one page of the public car search with facet counts
+type CarSearchResult struct {
+	Data       []*entities.Car                      `json:"data"`
+	Total      int64                                `json:"total"`
+	Page       int                                  `json:"page"`
+	Limit      int                                  `json:"limit"`
+	TotalPages int                                  `json:"total_pages"`
+	Facets     map[string][]repositories.FacetCount `json:"facets"`
+}
+
+// maxSearchPage caps page so the offset (page-1)*limit stays small and cannot overflow
+const maxSearchPage = 500
+
+// normalizePaging fills in the default page and limit and clamps both to their bounds
+func normalizePaging(filter *repositories.CarFilter) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Page > maxSearchPage {
+		filter.Page = maxSearchPage
+	}
+	if filter.Limit < 1 {
+		filter.Limit = 20
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+}
+
+// SearchPublicCars returns a filtered, sorted page of approved cars
+func (u *CarUsecase) SearchPublicCars(filter repositories.CarFilter) (*CarSearchResult, error) {
+	filter.Query = search.Query(filter.Query)
+	if filter.Sort == "" || (filter.Sort == "relevance" && filter.Query == "") {
+		switch {
+		case filter.Query != "":
+			filter.Sort = "relevance"
+		case filter.Near != nil:
+			filter.Sort = "distance"
+		default:
+			filter.Sort = "promoted"
+		}
+	}
+	if filter.Sort == "distance" && filter.Near == nil {
+		return nil, errors.New("sort=distance requires near")
+	}
+	if _, ok := repositories.CarSortOrders[filter.Sort]; !ok && filter.Sort != "relevance" && filter.Sort != "distance" {
+		return nil, errors.New("invalid sort")
+	}
+	normalizePaging(&filter)
+	if filter.YearMin > 0 && filter.YearMax > 0 && filter.YearMin > filter.YearMax {
+		return nil, errors.New("year_min must not be greater than year_max")
+	}
+	if filter.PriceMin > 0 && filter.PriceMax > 0 && filter.PriceMin > filter.PriceMax {
+		return nil, errors.New("price_min must not be greater than price_max")
+	}
+
+	cars := []*entities.Car{}
+	total, err := u.CarRepo.FindPublicPage(filter, &cars)
+	if err != nil {
+		return nil, err
+	}
+
+	facets := make(map[string][]repositories.FacetCount, len(repositories.CarFacetColumns))
+	for facet := range repositories.CarFacetColumns {
+		counts, err := u.CarRepo.CountPublicFacet(filter, facet)
+		if err != nil {
+			return nil, err
+		}
+		if counts == nil {
+			counts = []repositories.FacetCount{}
+		}
+		facets[facet] = counts
+	}
+
+	return &CarSearchResult{
+		Data:       cars,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+		Facets:     facets,
+	}, nil
+}
+
+// GetAdminCars returns all cars for admin dashboard
+func (u *CarUsecase) GetAdminCars(cars *[]*entities.Car) error {
+	return u.CarRepo.FindAll(cars)
+}
+
+func (u *CarUsecase) GetCarDetail(id uint) (*entities.Car, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(id, &car); err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// GetVisibleCar returns a car the viewer may open: a public listing, or any
+// car of the viewer's own dealership, or any car for admins
+func (u *CarUsecase) GetVisibleCar(id uint, userID uint, role string) (*entities.Car, error) {
+	car, err := u.GetCarDetail(id)
+	if err != nil {
+		return nil, errors.New("car not found")
+	}
+	if !CanView(car, userID, role) {
+		return nil, errors.New("car not found")
+	}
+	return car, nil
+}
+
+// CanView reports whether a car outside the public listing may be shown to the user
+func CanView(car *entities.Car, userID uint, role string) bool {
+	return car.IsPublic(time.Now()) || role == ActorAdmin || (userID != 0 && userID == car.Dealer.UserID)
+}
+
+// GetPriceHistory returns the price changes of a car, oldest first
+func (u *CarUsecase) GetPriceHistory(carID uint) ([]*entities.CarPriceHistory, error) {
+	history := []*entities.CarPriceHistory{}
+	if err := u.PriceHistoryRepo.FindByCarID(carID, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// priceChange returns the history row for a price change, or nil if the price is unchanged
+func priceChange(car *entities.Car, oldPrice float64) *entities.CarPriceHistory {
+	if car.Price == oldPrice {
+		return nil
+	}
+	return &entities.CarPriceHistory{CarID: car.ID, OldPrice: oldPrice, NewPrice: car.Price}
+}
+
+// notifyPriceDrop alerts users who favorited a public car when the price drops by
+// at least PRICE_DROP_NOTIFY_PERCENT (default 5%). Drafts, hidden and expired
+// cars are repriced silently.
+func (u *CarUsecase) notifyPriceDrop(car *entities.Car, oldPrice float64) {
+	if oldPrice <= 0 || car.Price >= oldPrice || !car.IsPublic(time.Now()) {
+		return
+	}
+	threshold, err := strconv.ParseFloat(utils.GetEnv("PRICE_DROP_NOTIFY_PERCENT", "5"), 64)
+	if err != nil {
+		threshold = 5
+	}
+	dropPercent := (oldPrice - car.Price) / oldPrice * 100
+	if dropPercent < threshold {
+		return
+	}
+
+	carID := car.ID
+	title := fmt.Sprintf("ลดราคา! %s %s", car.Brand, car.ModelName)
+	body := fmt.Sprintf("ราคาลดลง %.0f%% จาก %.0f เหลือ %.0f บาท", dropPercent, oldPrice, car.Price)
+	go func() {
+		userIDs, err := u.FavoriteRepo.FindUserIDsByCarID(carID)
+		if err != nil {
+			log.Printf("price drop: cannot load favorites of car %d: %v", carID, err)
+			return
+		}
+		for _, userID := range userIDs {
+			if err := u.NotificationUsecase.Notify(&entities.Notification{
+				UserID: userID,
+				Type:   "price_drop",
+				Title:  title,
+				Body:   body,
+				CarID:  &carID,
+			}); err != nil {
+				log.Printf("price drop: notify user %d failed: %v", userID, err)
+			}
+		}
+	}()
+}
+
+// DeleteCarByUser requests deletion instead of immediate delete
+func (u *CarUsecase) DeleteCarByUser(carID uint, userID uint) error {
+	var dealer entities.Dealer
+	if err := u.DealerRepo.FindByUserID(userID, &dealer); err != nil {
+		return errors.New("forbidden")
+	}
+
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return err
+	}
+
+	if car.DealerID != dealer.ID {
+		return errors.New("forbidden")
+	}
+
+	// Drafts were never listed, so they are removed right away
+	if car.Status == entities.CarStatusDraft {
+		return u.CarRepo.Delete(carID)
+	}
+
+	// Request delete
+	return u.Transition(&car, entities.CarStatusDeleteRequested, Actor{UserID: userID, Role: ActorDealer}, "")
+}
+
+// ---------- Business ----------
+
+// Admin: Approve
+func (u *CarUsecase) ApproveCar(carID uint, actor Actor) error {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return err
+	}
+	car.IsHidden = false
+	if err := u.Transition(&car, entities.CarStatusApproved, actor, ""); err != nil {
+		return err
+	}
+
+	// A scheduled car is announced by PublishScheduledCars once it goes live
+	if !isScheduled(&car, time.Now()) {
+		u.announce(car.ID)
+	}
+	return nil
+}
+
+// Admin: Reject
+func (u *CarUsecase) RejectCar(carID uint, reason string, actor Actor) error {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return err
+	}
+	car.ViolationReason = reason
+	car.IsHidden = true
+	return u.Transition(&car, entities.CarStatusRejected, actor, reason)
+}
+
+// Admin: Confirm Delete
+func (u *CarUsecase) ConfirmDeleteCar(carID uint) error {
+	return u.CarRepo.Delete(carID)
+}
+
+func (u *CarUsecase) RecordContact(carID uint, dealerID uint, via string) error {
+	if dealerID == 0 {
+		return errors.New("dealer_id is required")
+	}
+
+	if via != "call" && via != "line" {
+		return errors.New("invalid contact method")
+	}
+
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return err
+	}
+	if car.Status == entities.CarStatusExpired {
+		return errors.New("listing has expired")
+	}
+
+	// Counters are incremented in SQL so concurrent contacts and edits are not lost
+	if err := u.CarRepo.RecordContact(car.ID, via); err != nil {
+		return err
+	}
+
+	return u.LeadRepo.Create(&entities.Lead{
+		CarID:      carID,
+		DealerID:   dealerID,
+		ContactVia: via,
+	})
+}
+
+func (u *CarUsecase) GetStats(carID uint) (*entities.Car, error) {
+	return u.GetCarDetail(carID)
+}
+
+func (u *CarUsecase) PromoteCar(carID uint, days int) error {
+	if days <= 0 {
+		days = 7
+	}
+
+	if days > 90 {
+		return errors.New("days must be at most 90")
+	}
+
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return err
+	}
+
+	// Extend a running promotion instead of restarting it
+	start := time.Now()
+	if car.IsPromoted && car.PromotedUntil != nil && car.PromotedUntil.After(start) {
+		start = *car.PromotedUntil
+	}
+	until := start.Add(time.Duration(days) * 24 * time.Hour)
+	car.IsPromoted = true
+	car.PromotedUntil = &until
+	car.PromotionRemindedAt = nil
+
+	return u.CarRepo.UpdateFields(&car, map[string]interface{}{
+		"is_promoted":           true,
+		"promoted_until":        until,
+		"promotion_reminded_at": nil,
+	})
+}
+
+func (u *CarUsecase) GetCarsByDealer(dealerID uint, cars *[]*entities.Car) error {
+	if dealerID == 0 {
+		return errors.New("dealer_id is required")
+	}
+	return u.CarRepo.FindByDealerID(dealerID, cars)
+}
@@ -0,0 +1,64 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"math"
+	"testing"
+)
+
+func TestCanView(t *testing.T) {
+	draft := &entities.Car{Status: entities.CarStatusDraft, Dealer: entities.Dealer{UserID: 7}}
+	public := &entities.Car{Status: entities.CarStatusSelling, Dealer: entities.Dealer{UserID: 7}}
+
+	tests := []struct {
+		name   string
+		car    *entities.Car
+		userID uint
+		role   string
+		want   bool
+	}{
+		{"anonymous sees public car", public, 0, "", true},
+		{"anonymous cannot see draft", draft, 0, "", false},
+		{"other dealer cannot see draft", draft, 8, "dealer", false},
+		{"owner sees draft", draft, 7, "dealer", true},
+		{"admin sees draft", draft, 1, ActorAdmin, true},
+	}
+	for _, tt := range tests {
+		if got := CanView(tt.car, tt.userID, tt.role); got != tt.want {
+			t.Errorf("%s: CanView = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePaging(t *testing.T) {
+	tests := []struct {
+		page, limit         int
+		wantPage, wantLimit int
+	}{
+		{0, 0, 1, 20},
+		{-3, -1, 1, 20},
+		{2, 50, 2, 50},
+		{maxSearchPage + 1, 500, maxSearchPage, 100},
+		{math.MaxInt, math.MaxInt, maxSearchPage, 100},
+	}
+	for _, tt := range tests {
+		filter := repositories.CarFilter{Page: tt.page, Limit: tt.limit}
+		normalizePaging(&filter)
+		if filter.Page != tt.wantPage || filter.Limit != tt.wantLimit {
+			t.Errorf("page=%d limit=%d -> page=%d limit=%d, want page=%d limit=%d",
+				tt.page, tt.limit, filter.Page, filter.Limit, tt.wantPage, tt.wantLimit)
+		}
+	}
+}
+
+func TestPriceChange(t *testing.T) {
+	car := &entities.Car{ID: 3, Price: 450000}
+	if h := priceChange(car, 450000); h != nil {
+		t.Errorf("unchanged price produced history %+v", h)
+	}
+	h := priceChange(car, 500000)
+	if h == nil || h.CarID != 3 || h.OldPrice != 500000 || h.NewPrice != 450000 {
+		t.Errorf("price change history = %+v", h)
+	}
+}
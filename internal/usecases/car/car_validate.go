@@ -0,0 +1,38 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"strings"
+	"time"
+)
+
+// CarFieldError describes one invalid car field
+type CarFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e CarFieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidateCar checks the listing rules shared by create, update and bulk import
+func ValidateCar(car *entities.Car) []CarFieldError {
+	var errs []CarFieldError
+	if strings.TrimSpace(car.Brand) == "" {
+		errs = append(errs, CarFieldError{"brand", "is required"})
+	}
+	if strings.TrimSpace(car.ModelName) == "" {
+		errs = append(errs, CarFieldError{"model_name", "is required"})
+	}
+	if car.Year < 1900 || car.Year > time.Now().Year()+1 {
+		errs = append(errs, CarFieldError{"year", "must be between 1900 and next year"})
+	}
+	if car.Mileage < 0 {
+		errs = append(errs, CarFieldError{"mileage", "cannot be negative"})
+	}
+	if car.Price <= 0 {
+		errs = append(errs, CarFieldError{"price", "must be greater than 0"})
+	}
+	return errs
+}
@@ -0,0 +1,208 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/utils"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Viewer describes who opened a listing
+type Viewer struct {
+	UserID    uint
+	Role      string
+	IP        string
+	UserAgent string
+}
+
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|curl|wget|python|java/|go-http-client|okhttp|headless|preview|facebookexternalhit|lighthouse|monitor`)
+
+// viewDedupeWindow is VIEW_DEDUPE_MINUTES (default 30)
+func viewDedupeWindow() time.Duration {
+	minutes, err := strconv.Atoi(utils.GetEnv("VIEW_DEDUPE_MINUTES", "30"))
+	if err != nil || minutes <= 0 {
+		minutes = 30
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// RecordView counts a view of a public car once per viewer and dedupe window.
+// Bots, admins and the owning dealer are not counted.
+func (u *CarUsecase) RecordView(car *entities.Car, v Viewer) error {
+	if !car.IsPublic(time.Now()) {
+		return nil
+	}
+	if v.UserAgent == "" || botUserAgent.MatchString(v.UserAgent) {
+		return nil
+	}
+	if v.Role == "admin" || (v.UserID != 0 && v.UserID == car.Dealer.UserID) {
+		return nil
+	}
+
+	view := &entities.CarView{CarID: car.ID, CreatedAt: time.Now(), ViewerKey: viewerKey(v)}
+	if v.UserID != 0 {
+		userID := v.UserID
+		view.UserID = &userID
+	}
+
+	_, err := u.StatRepo.RecordView(view, utils.StartOfDay(view.CreatedAt), viewDedupeWindow())
+	return err
+}
+
+// viewerKey identifies a viewer for deduplication. Anonymous viewers are keyed on
+// what the server sees (IP and user agent), never on an id the client picks.
+func viewerKey(v Viewer) string {
+	if v.UserID != 0 {
+		return fmt.Sprintf("u:%d", v.UserID)
+	}
+	return "a:" + shortHash(v.IP+"|"+v.UserAgent)
+}
+
+type queuedView struct {
+	car    *entities.Car
+	viewer Viewer
+}
+
+// QueueView hands a view to a fixed pool of workers (VIEW_RECORD_WORKERS, default 4)
+// so a burst of traffic cannot start unbounded goroutines. When the queue
+// (VIEW_QUEUE_SIZE, default 1000) is full the view is dropped.
+func (u *CarUsecase) QueueView(car *entities.Car, v Viewer) bool {
+	u.viewQueueOnce.Do(u.startViewWorkers)
+	select {
+	case u.viewQueue <- queuedView{car: car, viewer: v}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (u *CarUsecase) startViewWorkers() {
+	workers, err := strconv.Atoi(utils.GetEnv("VIEW_RECORD_WORKERS", "4"))
+	if err != nil || workers < 1 {
+		workers = 4
+	}
+	size, err := strconv.Atoi(utils.GetEnv("VIEW_QUEUE_SIZE", "1000"))
+	if err != nil || size < 1 {
+		size = 1000
+	}
+	u.viewQueue = make(chan queuedView, size)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for q := range u.viewQueue {
+				if err := u.RecordView(q.car, q.viewer); err != nil {
+					log.Printf("record view failed for car %d: %v", q.car.ID, err)
+				}
+			}
+		}()
+	}
+}
+
+// PruneViews drops raw view rows that are no longer needed for deduplication (background job)
+func (u *CarUsecase) PruneViews() error {
+	keep := viewDedupeWindow()
+	if keep < 24*time.Hour {
+		keep = 24 * time.Hour
+	}
+	_, err := u.StatRepo.PruneViews(time.Now().Add(-keep))
+	return err
+}
+
+// DailyViews is one point of the views time series
+type DailyViews struct {
+	Date  string `json:"date"`
+	Views int    `json:"views"`
+}
+
+// CarStats is the view counter of a car with a daily series for a date range
+type CarStats struct {
+	CarID       uint         `json:"car_id"`
+	TotalViews  int          `json:"total_views"`
+	CallCount   int          `json:"call_count"`
+	LineCount   int          `json:"line_count"`
+	LeadCount   int          `json:"lead_count"`
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	PeriodViews int          `json:"period_views"`
+	Days        []DailyViews `json:"days"`
+}
+
+// parseDateRange reads a from/to day range (YYYY-MM-DD, inclusive, default the
+// last 30 days, at most 366 days)
+func parseDateRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	loc := utils.LocalTimezone()
+	to := utils.StartOfDay(time.Now())
+	if toStr != "" {
+		t, err := time.ParseInLocation("2006-01-02", toStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be YYYY-MM-DD")
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -29)
+	if fromStr != "" {
+		f, err := time.ParseInLocation("2006-01-02", fromStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be YYYY-MM-DD")
+		}
+		from = f
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.New("date range must be at most 366 days")
+	}
+	return from, to, nil
+}
+
+// GetCarStats returns the view series of a car between from and to (YYYY-MM-DD,
+// default the last 30 days, at most 366 days)
+func (u *CarUsecase) GetCarStats(carID uint, fromStr, toStr string) (*CarStats, error) {
+	from, to, err := parseDateRange(fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return nil, errors.New("car not found")
+	}
+
+	var rows []*entities.CarDailyStat
+	if err := u.StatRepo.FindDaily(carID, from, to, &rows); err != nil {
+		return nil, err
+	}
+	byDay := map[string]int{}
+	for _, r := range rows {
+		byDay[r.Day.Format("2006-01-02")] = r.Views
+	}
+
+	stats := &CarStats{
+		CarID:      car.ID,
+		TotalViews: car.Views,
+		CallCount:  car.CallCount,
+		LineCount:  car.LineCount,
+		LeadCount:  car.LeadCount,
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+		Days:       []DailyViews{},
+	}
+	// Zero-fill so charts get one point per day
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		stats.Days = append(stats.Days, DailyViews{Date: key, Views: byDay[key]})
+		stats.PeriodViews += byDay[key]
+	}
+	return stats, nil
+}
+
+func shortHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:12])
+}
@@ -0,0 +1,52 @@
+package car
+
+import (
+	"Backend_Go/internal/entities"
+	"testing"
+)
+
+func TestViewerKey(t *testing.T) {
+	browser := Viewer{IP: "203.0.113.5", UserAgent: "Mozilla/5.0"}
+
+	if got := viewerKey(Viewer{UserID: 42, IP: "203.0.113.5"}); got != "u:42" {
+		t.Errorf("signed-in viewer key = %q, want u:42", got)
+	}
+	if viewerKey(browser) != viewerKey(Viewer{IP: "203.0.113.5", UserAgent: "Mozilla/5.0"}) {
+		t.Error("the same browser got two keys")
+	}
+	if viewerKey(browser) == viewerKey(Viewer{IP: "203.0.113.6", UserAgent: "Mozilla/5.0"}) {
+		t.Error("different IPs share a key")
+	}
+	if viewerKey(browser) == viewerKey(Viewer{IP: "203.0.113.5", UserAgent: "Other/1.0"}) {
+		t.Error("different user agents share a key")
+	}
+}
+
+func TestQueueViewDropsWhenFull(t *testing.T) {
+	u := &CarUsecase{}
+	// No workers: the queue only fills up
+	u.viewQueueOnce.Do(func() { u.viewQueue = make(chan queuedView, 2) })
+
+	car := &entities.Car{Status: entities.CarStatusSelling}
+	v := Viewer{IP: "203.0.113.5", UserAgent: "Mozilla/5.0"}
+	for i := 0; i < 2; i++ {
+		if !u.QueueView(car, v) {
+			t.Fatalf("view %d was dropped with room in the queue", i)
+		}
+	}
+	if u.QueueView(car, v) {
+		t.Error("view was queued beyond the queue size")
+	}
+}
+
+func TestQueueViewRecordsInBackground(t *testing.T) {
+	t.Setenv("VIEW_RECORD_WORKERS", "1")
+	u := &CarUsecase{}
+	// Drafts are skipped by RecordView before touching the database
+	if !u.QueueView(&entities.Car{Status: entities.CarStatusDraft}, Viewer{}) {
+		t.Fatal("view was dropped")
+	}
+	if cap(u.viewQueue) != 1000 {
+		t.Errorf("queue size = %d, want the default 1000", cap(u.viewQueue))
+	}
+}
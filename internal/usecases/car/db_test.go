@@ -0,0 +1,58 @@
+package car
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// mockDB returns a postgres gorm DB backed by sqlmock. Every expected statement
+// must run by the end of the test.
+func mockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	return db, mock
+}
+
+// expectTransition expects the guarded status update of a car and its history
+// entry. With rows 0 the car has left the from status and the update is rolled back.
+func expectTransition(mock sqlmock.Sqlmock, carID uint, from, to string, rows int64) {
+	anyArg := sqlmock.AnyArg()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "cars" SET "updated_at"=\$1,"status"=\$2,.* WHERE status = \$8 AND "cars"."deleted_at" IS NULL AND "id" = \$9`).
+		WithArgs(anyArg, to, anyArg, anyArg, anyArg, anyArg, anyArg, from, carID).
+		WillReturnResult(sqlmock.NewResult(0, rows))
+	if rows == 0 {
+		mock.ExpectRollback()
+		return
+	}
+	mock.ExpectQuery(`INSERT INTO "car_status_histories"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(carID))
+	mock.ExpectCommit()
+}
+
+// expectNotification expects one stored notification of the given type for userID
+func expectNotification(mock sqlmock.Sqlmock, userID uint, kind string) {
+	anyArg := sqlmock.AnyArg()
+	mock.ExpectQuery(`INSERT INTO "notifications"`).
+		WithArgs(anyArg, anyArg, anyArg, userID, kind, anyArg, anyArg, anyArg, anyArg).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
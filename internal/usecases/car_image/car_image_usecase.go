@@ -1,105 +1,146 @@
-package carimage
-
-import (
-	"Backend_Go/internal/entities"
-	"Backend_Go/internal/repositories"
-	"errors"
-)
-
-type CarImageUsecase struct {
-	CarImageRepo *repositories.CarImageRepository
-	CarRepo      *repositories.CarRepository
-}
-
-// CreateCarImage creates a new car image
-func (u *CarImageUsecase) CreateCarImage(image *entities.CarImage) error {
-	// Validate required fields
-	if image.CarID == 0 {
-		return errors.New("car_id is required")
-	}
-
-	if image.ImageURL == "" {
-		return errors.New("image_url is required")
-	}
-
-	// Verify car exists
-	var car entities.Car
-	if err := u.CarRepo.FindByID(image.CarID, &car); err != nil {
-		return errors.New("car not found")
-	}
-
-	return u.CarImageRepo.Create(image)
-}
-
-// GetCarImages retrieves all images for a car
-func (u *CarImageUsecase) GetCarImages(carID uint) ([]*entities.CarImage, error) {
-	if carID == 0 {
-		return nil, errors.New("car_id is required")
-	}
-
-	// Verify car exists
-	var car entities.Car
-	if err := u.CarRepo.FindByID(carID, &car); err != nil {
-		return nil, errors.New("car not found")
-	}
-
-	return u.CarImageRepo.FindByCarID(carID)
-}
-
-// GetCarImage retrieves a specific image
-func (u *CarImageUsecase) GetCarImage(imageID uint) (*entities.CarImage, error) {
-	if imageID == 0 {
-		return nil, errors.New("image_id is required")
-	}
-
-	return u.CarImageRepo.FindByID(imageID)
-}
-
-// UpdateCarImage updates a car image
-func (u *CarImageUsecase) UpdateCarImage(image *entities.CarImage) error {
-	if image.ID == 0 {
-		return errors.New("image_id is required")
-	}
-
-	if image.ImageURL == "" {
-		return errors.New("image_url is required")
-	}
-
-	// Verify image exists
-	_, err := u.CarImageRepo.FindByID(image.ID)
-	if err != nil {
-		return errors.New("image not found")
-	}
-
-	return u.CarImageRepo.Update(image)
-}
-
-// DeleteCarImage deletes a car image
-func (u *CarImageUsecase) DeleteCarImage(imageID uint) error {
-	if imageID == 0 {
-		return errors.New("image_id is required")
-	}
-
-	// Verify image exists
-	_, err := u.CarImageRepo.FindByID(imageID)
-	if err != nil {
-		return errors.New("image not found")
-	}
-
-	return u.CarImageRepo.Delete(imageID)
-}
-
-// DeleteCarImages deletes all images for a car
-func (u *CarImageUsecase) DeleteCarImages(carID uint) error {
-	if carID == 0 {
-		return errors.New("car_id is required")
-	}
-
-	// Verify car exists
-	var car entities.Car
-	if err := u.CarRepo.FindByID(carID, &car); err != nil {
-		return errors.New("car not found")
-	}
-
-	return u.CarImageRepo.DeleteByCarID(carID)
-}
+package carimage
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/car"
+	"errors"
+	"math"
+)
+
+// ErrImageNotFound is returned when no live image has the given id
+var ErrImageNotFound = errors.New("image not found")
+
+type CarImageUsecase struct {
+	CarImageRepo *repositories.CarImageRepository
+	CarRepo      *repositories.CarRepository
+	CarUsecase   *car.CarUsecase
+	// 0 means no limit
+	MaxImagesPerCar int
+}
+
+// RemainingImageSlots returns how many more photos a car may have, counting
+// the ones that will be live after its pending revision is reviewed
+func (u *CarImageUsecase) RemainingImageSlots(carID uint) (int, error) {
+	if u.MaxImagesPerCar <= 0 {
+		return math.MaxInt, nil
+	}
+	count, err := u.CarImageRepo.CountAfterReview(carID)
+	if err != nil {
+		return 0, err
+	}
+	return max(0, u.MaxImagesPerCar-int(count)), nil
+}
+
+// PrepareNewImages returns the pending revision new photos of a car must be
+// attached to, or nil when they can go live directly
+func (u *CarImageUsecase) PrepareNewImages(carID uint, dealerUserID uint) (*uint, error) {
+	return u.CarUsecase.PrepareNewImage(carID, car.Actor{UserID: dealerUserID, Role: car.ActorDealer})
+}
+
+// CreateCarImage creates a new car image
+func (u *CarImageUsecase) CreateCarImage(image *entities.CarImage) error {
+	// Validate required fields
+	if image.CarID == 0 {
+		return errors.New("car_id is required")
+	}
+
+	if image.ImageURL == "" {
+		return errors.New("image_url is required")
+	}
+
+	// Verify car exists
+	var car entities.Car
+	if err := u.CarRepo.FindByID(image.CarID, &car); err != nil {
+		return errors.New("car not found")
+	}
+
+	return u.CarImageRepo.Create(image)
+}
+
+// SaveNewImages saves processed uploads of a car, up to MaxImagesPerCar. The
+// limit is checked again while saving, since RemainingImageSlots runs before
+// processing and another upload may have finished meanwhile. Images that no
+// longer fit are returned unsaved.
+func (u *CarImageUsecase) SaveNewImages(carID uint, images []*entities.CarImage) ([]*entities.CarImage, error) {
+	if carID == 0 {
+		return nil, errors.New("car_id is required")
+	}
+	for _, image := range images {
+		if image.CarID != carID || image.ImageURL == "" {
+			return nil, errors.New("every image needs the car's id and an image_url")
+		}
+	}
+	return u.CarImageRepo.CreateWithinLimit(carID, images, u.MaxImagesPerCar)
+}
+
+// GetCarImages retrieves all images of a car the user may view
+func (u *CarImageUsecase) GetCarImages(carID uint, userID uint, role string) ([]*entities.CarImage, error) {
+	if carID == 0 {
+		return nil, errors.New("car_id is required")
+	}
+
+	// Verify car exists and is visible to the user
+	var c entities.Car
+	if err := u.CarRepo.FindByID(carID, &c); err != nil || !car.CanView(&c, userID, role) {
+		return nil, errors.New("car not found")
+	}
+
+	return u.CarImageRepo.FindByCarID(carID)
+}
+
+// GetCarImage retrieves a specific image
+func (u *CarImageUsecase) GetCarImage(imageID uint) (*entities.CarImage, error) {
+	if imageID == 0 {
+		return nil, errors.New("image_id is required")
+	}
+
+	return u.CarImageRepo.FindByID(imageID)
+}
+
+// UpdateSortOrder moves a live car image. The file, renditions and revision of
+// an image only change through upload and review, never through this update.
+func (u *CarImageUsecase) UpdateSortOrder(imageID uint, sortOrder int) (*entities.CarImage, error) {
+	if imageID == 0 {
+		return nil, errors.New("image_id is required")
+	}
+
+	updated, err := u.CarImageRepo.UpdateSortOrder(imageID, sortOrder)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrImageNotFound
+	}
+	return u.CarImageRepo.FindByID(imageID)
+}
+
+// DeleteCarImage deletes a car image
+func (u *CarImageUsecase) DeleteCarImage(imageID uint) error {
+	if imageID == 0 {
+		return errors.New("image_id is required")
+	}
+
+	// Verify image exists
+	_, err := u.CarImageRepo.FindByID(imageID)
+	if err != nil {
+		return errors.New("image not found")
+	}
+
+	return u.CarImageRepo.Delete(imageID)
+}
+
+// DeleteCarImages deletes all images for a car
+func (u *CarImageUsecase) DeleteCarImages(carID uint) error {
+	if carID == 0 {
+		return errors.New("car_id is required")
+	}
+
+	// Verify car exists
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return errors.New("car not found")
+	}
+
+	return u.CarImageRepo.DeleteByCarID(carID)
+}
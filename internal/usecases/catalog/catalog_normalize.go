@@ -0,0 +1,204 @@
+package catalog
+
+import (
+	"Backend_Go/internal/entities"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Normalize maps a car to the catalog. Catalog IDs win over text; otherwise the
+// brand and model text is matched by name or alias. Matched cars get the
+// canonical names, and specs left empty are filled from the model and trim.
+// Body type, fuel and transmission get the catalog spelling when the catalog
+// uses them. Unknown brands and models stay free text unless CATALOG_STRICT=true.
+func (u *CatalogUsecase) Normalize(car *entities.Car) error {
+	return u.normalize(car, strictCatalog())
+}
+
+func (u *CatalogUsecase) normalize(car *entities.Car, strict bool) error {
+	if err := u.normalizeNames(car, strict); err != nil {
+		return err
+	}
+	u.normalizeSpecs(car)
+	return nil
+}
+
+// normalizeNames maps the brand, model and trim of the car
+func (u *CatalogUsecase) normalizeNames(car *entities.Car, strict bool) error {
+	brand, err := u.findBrand(car)
+	if err != nil {
+		return err
+	}
+	if brand == nil {
+		car.ModelID, car.TrimID = nil, nil
+		if strict {
+			return fmt.Errorf("brand %q is not in the catalog", car.Brand)
+		}
+		return nil
+	}
+	car.BrandID, car.Brand = &brand.ID, brand.Name
+
+	model, err := u.findModel(car, brand)
+	if err != nil {
+		return err
+	}
+	if model == nil {
+		car.TrimID = nil
+		if strict {
+			return fmt.Errorf("model %q is not a %s model in the catalog", car.ModelName, brand.Name)
+		}
+		return nil
+	}
+	car.ModelID, car.ModelName = &model.ID, model.Name
+	if car.CarType == "" {
+		car.CarType = model.CarType
+	}
+
+	if car.TrimID == nil {
+		return nil
+	}
+	var trim entities.CatalogTrim
+	if err := u.CatalogRepo.FindTrimByID(*car.TrimID, &trim); err != nil || trim.ModelID != model.ID {
+		return fmt.Errorf("trim_id %d is not a %s %s trim", *car.TrimID, brand.Name, model.Name)
+	}
+	if car.Year != 0 && !trim.Covers(car.Year) {
+		return fmt.Errorf("trim %s was not sold in %d", trim.Name, car.Year)
+	}
+	if car.FuelType == "" {
+		car.FuelType = trim.FuelType
+	}
+	if car.Transmission == "" {
+		car.Transmission = trim.Transmission
+	}
+	return nil
+}
+
+// findBrand loads the brand by ID, or matches the brand text when no ID is set.
+// A nil brand means the text matched nothing.
+func (u *CatalogUsecase) findBrand(car *entities.Car) (*entities.CatalogBrand, error) {
+	var brand entities.CatalogBrand
+	if car.BrandID != nil {
+		if err := u.CatalogRepo.FindBrandByID(*car.BrandID, &brand); err != nil {
+			return nil, fmt.Errorf("brand_id %d is not in the catalog", *car.BrandID)
+		}
+		return &brand, nil
+	}
+	if err := u.CatalogRepo.FindBrandByKey(Key(car.Brand), &brand); err != nil {
+		return nil, nil
+	}
+	return &brand, nil
+}
+
+// findModel loads the model by ID, or matches the model text within the brand.
+// A nil model means the text matched nothing.
+func (u *CatalogUsecase) findModel(car *entities.Car, brand *entities.CatalogBrand) (*entities.CatalogModel, error) {
+	var model entities.CatalogModel
+	if car.ModelID != nil {
+		if err := u.CatalogRepo.FindModelByID(*car.ModelID, &model); err != nil || model.BrandID != brand.ID {
+			return nil, fmt.Errorf("model_id %d is not a %s model", *car.ModelID, brand.Name)
+		}
+		return &model, nil
+	}
+	if err := u.CatalogRepo.FindModelByKey(brand.ID, Key(car.ModelName), &model); err != nil {
+		return nil, nil
+	}
+	return &model, nil
+}
+
+// normalizeSpecs replaces the body type, fuel and transmission with the spelling
+// the catalog uses for them ("sedan " -> "Sedan"); values unknown to the catalog
+// are only trimmed
+func (u *CatalogUsecase) normalizeSpecs(car *entities.Car) {
+	car.CarType = u.specSpelling(&entities.CatalogModel{}, "car_type", car.CarType)
+	car.FuelType = u.specSpelling(&entities.CatalogTrim{}, "fuel_type", car.FuelType)
+	car.Transmission = u.specSpelling(&entities.CatalogTrim{}, "transmission", car.Transmission)
+}
+
+func (u *CatalogUsecase) specSpelling(table interface{}, column, value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return value
+	}
+	if spelling, err := u.CatalogRepo.FindSpelling(table, column, Key(value)); err == nil && spelling != "" {
+		return spelling
+	}
+	return value
+}
+
+// NormalizeReport summarizes a NormalizeExistingCars run
+type NormalizeReport struct {
+	Scanned   int
+	Updated   int
+	Unmatched []UnmatchedName // most frequent first
+}
+
+// UnmatchedName is a brand/model spelling that no catalog entry matched
+type UnmatchedName struct {
+	Brand     string
+	ModelName string
+	Cars      int
+}
+
+// NormalizeExistingCars maps every stored car to the catalog (migration
+// command). Cars that do not match keep their text; their spellings are
+// reported so admins can add aliases and run it again.
+func (u *CatalogUsecase) NormalizeExistingCars() (*NormalizeReport, error) {
+	report := &NormalizeReport{}
+	unmatched := map[[2]string]int{}
+
+	err := u.CarRepo.EachCar(func(car *entities.Car) error {
+		report.Scanned++
+		before := Columns(car)
+		if err := u.normalize(car, false); err != nil {
+			// Stale IDs: match the text again
+			car.BrandID, car.ModelID, car.TrimID = nil, nil, nil
+			if err := u.normalize(car, false); err != nil {
+				return err
+			}
+		}
+		if car.ModelID == nil {
+			unmatched[[2]string{car.Brand, car.ModelName}]++
+		}
+
+		after := Columns(car)
+		for column, value := range after {
+			if fmt.Sprint(value) != fmt.Sprint(before[column]) {
+				report.Updated++
+				return u.CarRepo.UpdateFields(car, after)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for names, count := range unmatched {
+		report.Unmatched = append(report.Unmatched, UnmatchedName{Brand: names[0], ModelName: names[1], Cars: count})
+	}
+	sort.Slice(report.Unmatched, func(i, j int) bool {
+		return report.Unmatched[i].Cars > report.Unmatched[j].Cars
+	})
+	return report, nil
+}
+
+// Columns returns the car columns Normalize may change, keyed by column name
+func Columns(car *entities.Car) map[string]interface{} {
+	id := func(p *uint) interface{} {
+		if p == nil {
+			return nil
+		}
+		return *p
+	}
+	return map[string]interface{}{
+		"brand":        car.Brand,
+		"model_name":   car.ModelName,
+		"car_type":     car.CarType,
+		"fuel_type":    car.FuelType,
+		"transmission": car.Transmission,
+		"brand_id":     id(car.BrandID),
+		"model_id":     id(car.ModelID),
+		"trim_id":      id(car.TrimID),
+	}
+}
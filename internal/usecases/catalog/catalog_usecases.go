@@ -0,0 +1,245 @@
+package catalog
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type CatalogUsecase struct {
+	CatalogRepo *repositories.CatalogRepository
+	CarRepo     *repositories.CarRepository
+}
+
+// Key normalizes a brand or model name for matching: trimmed, single-spaced, lower case
+func Key(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// strictCatalog is CATALOG_STRICT: when true, cars must match a catalog brand and model
+func strictCatalog() bool {
+	return utils.GetEnv("CATALOG_STRICT", "false") == "true"
+}
+
+// ---------- Public lookup ----------
+
+func (u *CatalogUsecase) GetBrands() ([]*entities.CatalogBrand, error) {
+	brands := []*entities.CatalogBrand{}
+	if err := u.CatalogRepo.FindBrands(&brands); err != nil {
+		return nil, err
+	}
+	return brands, nil
+}
+
+func (u *CatalogUsecase) GetModels(brandID uint) ([]*entities.CatalogModel, error) {
+	var brand entities.CatalogBrand
+	if err := u.CatalogRepo.FindBrandByID(brandID, &brand); err != nil {
+		return nil, errors.New("brand not found")
+	}
+	models := []*entities.CatalogModel{}
+	if err := u.CatalogRepo.FindModelsByBrandID(brandID, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// GetTrims returns the trims of a model, only those sold in year when year != 0
+func (u *CatalogUsecase) GetTrims(modelID uint, year int) ([]*entities.CatalogTrim, error) {
+	var model entities.CatalogModel
+	if err := u.CatalogRepo.FindModelByID(modelID, &model); err != nil {
+		return nil, errors.New("model not found")
+	}
+	trims := []*entities.CatalogTrim{}
+	if err := u.CatalogRepo.FindTrimsByModelID(modelID, year, &trims); err != nil {
+		return nil, err
+	}
+	return trims, nil
+}
+
+// ---------- Admin CRUD ----------
+
+func (u *CatalogUsecase) CreateBrand(in *entities.CatalogBrand) error {
+	if err := u.prepareBrand(in); err != nil {
+		return err
+	}
+	return u.CatalogRepo.CreateBrand(in)
+}
+
+func (u *CatalogUsecase) UpdateBrand(id uint, in *entities.CatalogBrand) (*entities.CatalogBrand, error) {
+	var brand entities.CatalogBrand
+	if err := u.CatalogRepo.FindBrandByID(id, &brand); err != nil {
+		return nil, errors.New("brand not found")
+	}
+	brand.Name, brand.Aliases = in.Name, in.Aliases
+	if err := u.prepareBrand(&brand); err != nil {
+		return nil, err
+	}
+	if err := u.CatalogRepo.UpdateBrand(&brand); err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+func (u *CatalogUsecase) DeleteBrand(id uint) error {
+	if err := u.ensureUnused(&entities.CatalogModel{}, "brand_id", id, "brand still has models"); err != nil {
+		return err
+	}
+	return u.CatalogRepo.DeleteBrand(id)
+}
+
+func (u *CatalogUsecase) CreateModel(brandID uint, in *entities.CatalogModel) error {
+	var brand entities.CatalogBrand
+	if err := u.CatalogRepo.FindBrandByID(brandID, &brand); err != nil {
+		return errors.New("brand not found")
+	}
+	in.BrandID = brandID
+	if err := u.prepareModel(in); err != nil {
+		return err
+	}
+	return u.CatalogRepo.CreateModel(in)
+}
+
+func (u *CatalogUsecase) UpdateModel(id uint, in *entities.CatalogModel) (*entities.CatalogModel, error) {
+	var model entities.CatalogModel
+	if err := u.CatalogRepo.FindModelByID(id, &model); err != nil {
+		return nil, errors.New("model not found")
+	}
+	model.Name, model.Aliases, model.CarType = in.Name, in.Aliases, strings.TrimSpace(in.CarType)
+	if err := u.prepareModel(&model); err != nil {
+		return nil, err
+	}
+	if err := u.CatalogRepo.UpdateModel(&model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (u *CatalogUsecase) DeleteModel(id uint) error {
+	if err := u.ensureUnused(&entities.CatalogTrim{}, "model_id", id, "model still has trims"); err != nil {
+		return err
+	}
+	return u.CatalogRepo.DeleteModel(id)
+}
+
+func (u *CatalogUsecase) CreateTrim(modelID uint, in *entities.CatalogTrim) error {
+	var model entities.CatalogModel
+	if err := u.CatalogRepo.FindModelByID(modelID, &model); err != nil {
+		return errors.New("model not found")
+	}
+	in.ModelID = modelID
+	if err := validateTrim(in); err != nil {
+		return err
+	}
+	return u.CatalogRepo.CreateTrim(in)
+}
+
+func (u *CatalogUsecase) UpdateTrim(id uint, in *entities.CatalogTrim) (*entities.CatalogTrim, error) {
+	var trim entities.CatalogTrim
+	if err := u.CatalogRepo.FindTrimByID(id, &trim); err != nil {
+		return nil, errors.New("trim not found")
+	}
+	in.ID, in.CreatedAt, in.ModelID = trim.ID, trim.CreatedAt, trim.ModelID
+	if err := validateTrim(in); err != nil {
+		return nil, err
+	}
+	if err := u.CatalogRepo.UpdateTrim(in); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (u *CatalogUsecase) DeleteTrim(id uint) error {
+	if err := u.ensureUnused(nil, "trim_id", id, ""); err != nil {
+		return err
+	}
+	return u.CatalogRepo.DeleteTrim(id)
+}
+
+// prepareBrand cleans the name and aliases and rejects names already used by another brand
+func (u *CatalogUsecase) prepareBrand(brand *entities.CatalogBrand) error {
+	brand.Name = strings.Join(strings.Fields(brand.Name), " ")
+	if brand.Name == "" {
+		return errors.New("name is required")
+	}
+	brand.Aliases = cleanAliases(brand.Name, brand.Aliases)
+	for _, key := range append([]string{Key(brand.Name)}, brand.Aliases...) {
+		var other entities.CatalogBrand
+		if err := u.CatalogRepo.FindBrandByKey(key, &other); err == nil && other.ID != brand.ID {
+			return fmt.Errorf("%q is already used by brand %s", key, other.Name)
+		}
+	}
+	return nil
+}
+
+// prepareModel cleans the name and aliases and rejects names already used by another model of the brand
+func (u *CatalogUsecase) prepareModel(model *entities.CatalogModel) error {
+	model.Name = strings.Join(strings.Fields(model.Name), " ")
+	if model.Name == "" {
+		return errors.New("name is required")
+	}
+	model.Aliases = cleanAliases(model.Name, model.Aliases)
+	for _, key := range append([]string{Key(model.Name)}, model.Aliases...) {
+		var other entities.CatalogModel
+		if err := u.CatalogRepo.FindModelByKey(model.BrandID, key, &other); err == nil && other.ID != model.ID {
+			return fmt.Errorf("%q is already used by model %s", key, other.Name)
+		}
+	}
+	return nil
+}
+
+func validateTrim(trim *entities.CatalogTrim) error {
+	trim.Name = strings.Join(strings.Fields(trim.Name), " ")
+	if trim.Name == "" {
+		return errors.New("name is required")
+	}
+	if trim.YearFrom < 1900 || trim.YearFrom > time.Now().Year()+1 {
+		return errors.New("year_from must be between 1900 and next year")
+	}
+	if trim.YearTo != 0 && trim.YearTo < trim.YearFrom {
+		return errors.New("year_to must not be before year_from")
+	}
+	if trim.EngineCC < 0 || trim.Seats < 0 {
+		return errors.New("engine_cc and seats cannot be negative")
+	}
+	return nil
+}
+
+// ensureUnused refuses to delete a catalog entry that still has children or cars
+func (u *CatalogUsecase) ensureUnused(child interface{}, column string, id uint, childMsg string) error {
+	if child != nil {
+		count, err := u.CatalogRepo.CountChildren(child, column, id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New(childMsg)
+		}
+	}
+	count, err := u.CatalogRepo.CountCars(column, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%d cars still use this catalog entry", count)
+	}
+	return nil
+}
+
+// cleanAliases normalizes aliases, dropping blanks, duplicates and the name itself
+func cleanAliases(name string, aliases []string) []string {
+	seen := map[string]bool{Key(name): true}
+	out := []string{}
+	for _, a := range aliases {
+		key := Key(a)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, key)
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"Backend_Go/internal/entities"
+	"reflect"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	tests := map[string]string{
+		"Toyota":          "toyota",
+		"  Mercedes-Benz": "mercedes-benz",
+		"Land   Cruiser ": "land cruiser",
+		"โตโยต้า":         "โตโยต้า",
+		"":                "",
+	}
+	for name, want := range tests {
+		if got := Key(name); got != want {
+			t.Errorf("Key(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCleanAliases(t *testing.T) {
+	got := cleanAliases("Toyota", []string{"โตโยต้า", " TOYOTA ", "", "Toyata", "toyata"})
+	want := []string{"โตโยต้า", "toyata"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cleanAliases = %q, want %q", got, want)
+	}
+}
+
+func TestValidateTrim(t *testing.T) {
+	tests := []struct {
+		trim entities.CatalogTrim
+		ok   bool
+	}{
+		{entities.CatalogTrim{Name: " 1.5  G ", YearFrom: 2019}, true},
+		{entities.CatalogTrim{Name: "", YearFrom: 2019}, false},
+		{entities.CatalogTrim{Name: "G", YearFrom: 1800}, false},
+		{entities.CatalogTrim{Name: "G", YearFrom: 2019, YearTo: 2018}, false},
+		{entities.CatalogTrim{Name: "G", YearFrom: 2019, Seats: -1}, false},
+	}
+	for _, tt := range tests {
+		trim := tt.trim
+		if err := validateTrim(&trim); (err == nil) != tt.ok {
+			t.Errorf("validateTrim(%+v) = %v, want ok=%v", tt.trim, err, tt.ok)
+		}
+	}
+
+	trim := entities.CatalogTrim{Name: " 1.5  G ", YearFrom: 2019}
+	_ = validateTrim(&trim)
+	if trim.Name != "1.5 G" {
+		t.Errorf("trim name = %q, want it single-spaced", trim.Name)
+	}
+}
+
+func TestColumnsCoversEverySpec(t *testing.T) {
+	cols := Columns(&entities.Car{CarType: "Sedan", FuelType: "Hybrid", Transmission: "Auto"})
+	for _, column := range []string{"brand", "model_name", "car_type", "fuel_type", "transmission", "brand_id", "model_id", "trim_id"} {
+		if _, ok := cols[column]; !ok {
+			t.Errorf("Columns is missing %s", column)
+		}
+	}
+	if cols["brand_id"] != nil {
+		t.Errorf("unset brand_id = %v, want nil", cols["brand_id"])
+	}
+}
@@ -0,0 +1,261 @@
+package feed
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/utils"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ขนาดหน้าของฟีด (จำนวนประกาศต่อหน้า)
+const (
+	DefaultFeedLimit = 500
+	MaxFeedLimit     = 1000
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ฟีดประกาศรถสำหรับเว็บพาร์ทเนอร์ (JSON / XML)
+type FeedUsecase struct {
+	CarRepo    *repositories.CarRepository
+	DealerRepo *repositories.DealerRepository
+}
+
+// FeedQuery selects one page of a feed. DealerID 0 is the marketplace feed;
+// Cursor is the NextCursor of the previous page.
+type FeedQuery struct {
+	DealerID     uint
+	UpdatedSince *time.Time
+	Cursor       string
+	Limit        int
+}
+
+// Feed is a syndication feed of public car listings. With UpdatedSince set it
+// only holds listings changed since then plus (on the first page) the ids of
+// removed listings. NextCursor is set while more listings follow.
+type Feed struct {
+	XMLName      xml.Name      `xml:"listings" json:"-"`
+	GeneratedAt  time.Time     `xml:"generated_at,attr" json:"generated_at"`
+	UpdatedSince *time.Time    `xml:"updated_since,attr,omitempty" json:"updated_since,omitempty"`
+	Count        int           `xml:"count,attr" json:"count"`
+	Listings     []FeedListing `xml:"listing" json:"listings"`
+	RemovedIDs   []uint        `xml:"removed>vehicle_id,omitempty" json:"removed_ids,omitempty"`
+	NextCursor   string        `xml:"next_cursor,attr,omitempty" json:"next_cursor,omitempty"`
+}
+
+type FeedListing struct {
+	VehicleID      uint        `xml:"vehicle_id" json:"vehicle_id"`
+	Title          string      `xml:"title" json:"title"`
+	Description    string      `xml:"description" json:"description"`
+	URL            string      `xml:"url" json:"url"`
+	Make           string      `xml:"make" json:"make"`
+	Model          string      `xml:"model" json:"model"`
+	Year           int         `xml:"year" json:"year"`
+	Mileage        FeedMileage `xml:"mileage" json:"mileage"`
+	Price          FeedPrice   `xml:"price" json:"price"`
+	BodyStyle      string      `xml:"body_style,omitempty" json:"body_style,omitempty"`
+	FuelType       string      `xml:"fuel_type,omitempty" json:"fuel_type,omitempty"`
+	Transmission   string      `xml:"transmission,omitempty" json:"transmission,omitempty"`
+	ExteriorColor  string      `xml:"exterior_color,omitempty" json:"exterior_color,omitempty"`
+	StateOfVehicle string      `xml:"state_of_vehicle" json:"state_of_vehicle"`
+	Images         []FeedImage `xml:"image" json:"images"`
+	Dealer         FeedDealer  `xml:"dealer" json:"dealer"`
+	UpdatedAt      time.Time   `xml:"updated_at" json:"updated_at"`
+}
+
+type FeedMileage struct {
+	Value int    `xml:"value" json:"value"`
+	Unit  string `xml:"unit" json:"unit"`
+}
+
+type FeedPrice struct {
+	Amount   float64 `xml:"amount" json:"amount"`
+	Currency string  `xml:"currency" json:"currency"`
+}
+
+type FeedImage struct {
+	URL string `xml:"url" json:"url"`
+}
+
+type FeedDealer struct {
+	ID        uint     `xml:"id" json:"id"`
+	Name      string   `xml:"name" json:"name"`
+	Phone     string   `xml:"phone,omitempty" json:"phone,omitempty"`
+	Province  string   `xml:"province,omitempty" json:"province,omitempty"`
+	Latitude  *float64 `xml:"latitude,omitempty" json:"latitude,omitempty"`
+	Longitude *float64 `xml:"longitude,omitempty" json:"longitude,omitempty"`
+}
+
+// FeedVersion identifies the current content of a feed for HTTP caching
+type FeedVersion struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// GetVersion returns the cache validators of one page of the marketplace feed
+// or of one approved dealer's feed
+func (u *FeedUsecase) GetVersion(query FeedQuery, format string) (*FeedVersion, error) {
+	if err := u.checkDealer(query.DealerID); err != nil {
+		return nil, err
+	}
+
+	count, lastModified, err := u.CarRepo.FeedVersion(query.DealerID)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%d|%s|%d|%d|%s|%d", query.DealerID, format, count, lastModified.UnixNano(),
+		query.Cursor, feedLimit(query.Limit))
+	if query.UpdatedSince != nil {
+		key += "|" + query.UpdatedSince.UTC().Format(time.RFC3339Nano)
+	}
+	sum := sha1.Sum([]byte(key))
+	return &FeedVersion{
+		ETag:         `W/"` + hex.EncodeToString(sum[:10]) + `"`,
+		LastModified: lastModified,
+	}, nil
+}
+
+// GetFeed builds one page of the feed; UpdatedSince limits it to listings
+// changed after that time
+func (u *FeedUsecase) GetFeed(query FeedQuery) (*Feed, error) {
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkDealer(query.DealerID); err != nil {
+		return nil, err
+	}
+
+	// ดึงเกินมา 1 คันเพื่อรู้ว่ายังมีหน้าถัดไปไหม
+	limit := feedLimit(query.Limit)
+	var cars []*entities.Car
+	if err := u.CarRepo.FindFeedCars(query.DealerID, query.UpdatedSince, after, limit+1, &cars); err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		GeneratedAt:  time.Now(),
+		UpdatedSince: query.UpdatedSince,
+	}
+	if len(cars) > limit {
+		cars = cars[:limit]
+		last := cars[limit-1]
+		feed.NextCursor = encodeCursor(repositories.FeedCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+	feed.Listings = make([]FeedListing, 0, len(cars))
+	for _, c := range cars {
+		feed.Listings = append(feed.Listings, toListing(c))
+	}
+	feed.Count = len(feed.Listings)
+
+	if query.UpdatedSince != nil && after == nil {
+		removed, err := u.CarRepo.FindFeedRemovedIDs(query.DealerID, *query.UpdatedSince)
+		if err != nil {
+			return nil, err
+		}
+		feed.RemovedIDs = removed
+	}
+	return feed, nil
+}
+
+func feedLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultFeedLimit
+	}
+	if limit > MaxFeedLimit {
+		return MaxFeedLimit
+	}
+	return limit
+}
+
+// cursor = base64url("<updated_at unix nano>.<car id>")
+func encodeCursor(c repositories.FeedCursor) string {
+	raw := strconv.FormatInt(c.UpdatedAt.UnixNano(), 10) + "." + strconv.FormatUint(uint64(c.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*repositories.FeedCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	carID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil || carID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &repositories.FeedCursor{UpdatedAt: time.Unix(0, n).UTC(), ID: uint(carID)}, nil
+}
+
+func (u *FeedUsecase) checkDealer(dealerID uint) error {
+	if dealerID == 0 {
+		return nil
+	}
+	var dealer entities.Dealer
+	if err := u.DealerRepo.FindByID(dealerID, &dealer); err != nil {
+		return errors.New("dealer not found")
+	}
+	if dealer.Status != "approved" && !(dealer.Status == "" && dealer.IsApproved) {
+		return errors.New("dealer not found")
+	}
+	return nil
+}
+
+func toListing(c *entities.Car) FeedListing {
+	baseURL := strings.TrimRight(utils.GetEnv("PUBLIC_BASE_URL", ""), "/")
+	siteURL := strings.TrimRight(utils.GetEnv("PUBLIC_SITE_URL", baseURL), "/")
+
+	images := make([]FeedImage, 0, len(c.CarImages))
+	for _, img := range c.CarImages {
+		url := img.ImageURL
+		if strings.HasPrefix(url, "/") {
+			url = baseURL + url
+		}
+		images = append(images, FeedImage{URL: url})
+	}
+
+	return FeedListing{
+		VehicleID:      c.ID,
+		Title:          strings.TrimSpace(fmt.Sprintf("%s %s %d", c.Brand, c.ModelName, c.Year)),
+		Description:    c.Description,
+		URL:            fmt.Sprintf("%s/cars/%d", siteURL, c.ID),
+		Make:           c.Brand,
+		Model:          c.ModelName,
+		Year:           c.Year,
+		Mileage:        FeedMileage{Value: c.Mileage, Unit: "KM"},
+		Price:          FeedPrice{Amount: c.Price, Currency: "THB"},
+		BodyStyle:      c.CarType,
+		FuelType:       c.FuelType,
+		Transmission:   c.Transmission,
+		ExteriorColor:  c.Color,
+		StateOfVehicle: "used",
+		Images:         images,
+		Dealer: FeedDealer{
+			ID:        c.Dealer.ID,
+			Name:      c.Dealer.ShopName,
+			Phone:     c.Dealer.Phone,
+			Province:  c.Dealer.Province,
+			Latitude:  c.Dealer.Latitude,
+			Longitude: c.Dealer.Longitude,
+		},
+		UpdatedAt: c.UpdatedAt,
+	}
+}
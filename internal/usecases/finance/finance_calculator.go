@@ -0,0 +1,79 @@
+package finance
+
+import (
+	"Backend_Go/utils"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// Installment is a hire-purchase (เช่าซื้อ) payment plan. Interest is flat:
+// financed amount x yearly rate x years, spread evenly over the term. VAT is
+// charged on each installment.
+type Installment struct {
+	Price            float64 `json:"price"`
+	DownPercent      float64 `json:"down_percent"`
+	DownPayment      float64 `json:"down_payment"`
+	Financed         float64 `json:"financed"`
+	TermMonths       int     `json:"term_months"`
+	RatePercent      float64 `json:"rate_percent"`
+	Interest         float64 `json:"interest"`
+	MonthlyBeforeVAT float64 `json:"monthly_before_vat"`
+	VAT              float64 `json:"vat"`
+	Monthly          float64 `json:"monthly"`
+	TotalPayments    float64 `json:"total_payments"`
+	VATIncluded      bool    `json:"vat_included"`
+}
+
+// vatPercent is FINANCE_VAT_PERCENT (default 7)
+func vatPercent() float64 {
+	vat, err := strconv.ParseFloat(utils.GetEnv("FINANCE_VAT_PERCENT", "7"), 64)
+	if err != nil || vat < 0 {
+		return 7
+	}
+	return vat
+}
+
+// Calculate computes the monthly installment of a car. The monthly payment is
+// rounded up to the whole baht, as finance companies do.
+func Calculate(price, downPercent float64, termMonths int, ratePercent float64, includeVAT bool) (*Installment, error) {
+	for _, v := range []float64{price, downPercent, ratePercent} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, errors.New("price, down_percent and rate must be finite numbers")
+		}
+	}
+	if price <= 0 {
+		return nil, errors.New("price must be greater than 0")
+	}
+	if downPercent < 0 || downPercent >= 100 {
+		return nil, errors.New("down_percent must be between 0 and 100")
+	}
+	if termMonths < 6 || termMonths > 120 {
+		return nil, errors.New("term must be between 6 and 120 months")
+	}
+	if ratePercent < 0 || ratePercent > 30 {
+		return nil, errors.New("rate must be between 0 and 30 percent")
+	}
+
+	in := &Installment{
+		Price:       price,
+		DownPercent: downPercent,
+		TermMonths:  termMonths,
+		RatePercent: ratePercent,
+		VATIncluded: includeVAT,
+	}
+	in.DownPayment = roundBaht(price * downPercent / 100)
+	in.Financed = price - in.DownPayment
+	in.Interest = roundBaht(in.Financed * ratePercent / 100 * float64(termMonths) / 12)
+	in.MonthlyBeforeVAT = math.Ceil((in.Financed + in.Interest) / float64(termMonths))
+	if includeVAT {
+		in.VAT = math.Ceil(in.MonthlyBeforeVAT * vatPercent() / 100)
+	}
+	in.Monthly = in.MonthlyBeforeVAT + in.VAT
+	in.TotalPayments = in.Monthly * float64(termMonths)
+	return in, nil
+}
+
+func roundBaht(v float64) float64 {
+	return math.Round(v*100) / 100
+}
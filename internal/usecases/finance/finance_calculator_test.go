@@ -0,0 +1,71 @@
+package finance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculate(t *testing.T) {
+	t.Setenv("FINANCE_VAT_PERCENT", "7")
+	tests := []struct {
+		name                          string
+		price, down                   float64
+		term                          int
+		rate                          float64
+		vat                           bool
+		downPayment, interest         float64
+		beforeVAT, vatAmount, monthly float64
+	}{
+		{"with VAT", 500000, 25, 48, 3, true, 125000, 45000, 8750, 613, 9363},
+		{"without VAT", 500000, 25, 48, 3, false, 125000, 45000, 8750, 0, 8750},
+		{"zero rate", 120000, 0, 12, 0, true, 0, 0, 10000, 700, 10700},
+		{"rounds satang and monthly up", 333333, 10, 60, 2.5, false, 33333.3, 37499.96, 5625, 0, 5625},
+	}
+	for _, tt := range tests {
+		in, err := Calculate(tt.price, tt.down, tt.term, tt.rate, tt.vat)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for _, f := range []struct {
+			field     string
+			got, want float64
+		}{
+			{"down payment", in.DownPayment, tt.downPayment},
+			{"interest", in.Interest, tt.interest},
+			{"monthly before VAT", in.MonthlyBeforeVAT, tt.beforeVAT},
+			{"VAT", in.VAT, tt.vatAmount},
+			{"monthly", in.Monthly, tt.monthly},
+			{"total", in.TotalPayments, tt.monthly * float64(tt.term)},
+		} {
+			if math.Abs(f.got-f.want) > 0.005 {
+				t.Errorf("%s: %s = %v, want %v", tt.name, f.field, f.got, f.want)
+			}
+		}
+	}
+}
+
+func TestCalculateRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name        string
+		price, down float64
+		term        int
+		rate        float64
+	}{
+		{"zero price", 0, 10, 48, 3},
+		{"NaN price", math.NaN(), 10, 48, 3},
+		{"infinite price", math.Inf(1), 10, 48, 3},
+		{"NaN down payment", 500000, math.NaN(), 48, 3},
+		{"full down payment", 500000, 100, 48, 3},
+		{"short term", 500000, 10, 5, 3},
+		{"long term", 500000, 10, 121, 3},
+		{"NaN rate", 500000, 10, 48, math.NaN()},
+		{"infinite rate", 500000, 10, 48, math.Inf(-1)},
+		{"rate too high", 500000, 10, 48, 31},
+	}
+	for _, tt := range tests {
+		if _, err := Calculate(tt.price, tt.down, tt.term, tt.rate, true); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package finance
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// ข้อเสนอไฟแนนซ์ของดีลเลอร์ และค่างวดเริ่มต้นที่แสดงในประกาศ
+type FinanceUsecase struct {
+	FinanceOfferRepo *repositories.FinanceOfferRepository
+	CarRepo          *repositories.CarRepository
+}
+
+// ---------- Dealer offers ----------
+
+func (u *FinanceUsecase) GetOffers(dealerID uint) ([]*entities.FinanceOffer, error) {
+	offers := []*entities.FinanceOffer{}
+	if err := u.FinanceOfferRepo.FindByDealerID(dealerID, &offers); err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+func (u *FinanceUsecase) CreateOffer(dealerID uint, offer *entities.FinanceOffer) error {
+	offer.DealerID = dealerID
+	if err := u.validateOffer(offer); err != nil {
+		return err
+	}
+	if err := u.FinanceOfferRepo.Create(offer); err != nil {
+		return err
+	}
+	return u.refreshOfferCars(offer)
+}
+
+func (u *FinanceUsecase) UpdateOffer(dealerID, id uint, in *entities.FinanceOffer) (*entities.FinanceOffer, error) {
+	var offer entities.FinanceOffer
+	if err := u.FinanceOfferRepo.FindByID(id, &offer); err != nil || offer.DealerID != dealerID {
+		return nil, errors.New("finance offer not found")
+	}
+	previous := offer
+
+	offer.CarID, offer.Name, offer.MinDownPercent = in.CarID, in.Name, in.MinDownPercent
+	offer.Rates, offer.ValidUntil, offer.IsActive = in.Rates, in.ValidUntil, in.IsActive
+	if err := u.validateOffer(&offer); err != nil {
+		return nil, err
+	}
+	if err := u.FinanceOfferRepo.Update(&offer); err != nil {
+		return nil, err
+	}
+
+	// Cars the offer applied to before the change need a new price too
+	if err := u.refreshOfferCars(&previous); err != nil {
+		return nil, err
+	}
+	sameScope := (previous.CarID == nil && offer.CarID == nil) ||
+		(previous.CarID != nil && offer.CarID != nil && *previous.CarID == *offer.CarID)
+	if !sameScope {
+		if err := u.refreshOfferCars(&offer); err != nil {
+			return nil, err
+		}
+	}
+	return &offer, nil
+}
+
+func (u *FinanceUsecase) DeleteOffer(dealerID, id uint) error {
+	var offer entities.FinanceOffer
+	if err := u.FinanceOfferRepo.FindByID(id, &offer); err != nil || offer.DealerID != dealerID {
+		return errors.New("finance offer not found")
+	}
+	if err := u.FinanceOfferRepo.Delete(id); err != nil {
+		return err
+	}
+	return u.refreshOfferCars(&offer)
+}
+
+func (u *FinanceUsecase) validateOffer(offer *entities.FinanceOffer) error {
+	if offer.CarID != nil {
+		dealerID, err := u.CarRepo.FindDealerID(*offer.CarID)
+		if err != nil || dealerID != offer.DealerID {
+			return errors.New("car not found")
+		}
+	}
+	if offer.MinDownPercent < 0 || offer.MinDownPercent >= 100 {
+		return errors.New("min_down_percent must be between 0 and 100")
+	}
+	if len(offer.Rates) == 0 {
+		return errors.New("at least one rate is required")
+	}
+	seen := map[int]bool{}
+	for _, r := range offer.Rates {
+		// Validate the rate with a sample calculation
+		if _, err := Calculate(1, offer.MinDownPercent, r.TermMonths, r.RatePercent, false); err != nil {
+			return fmt.Errorf("rate for %d months: %v", r.TermMonths, err)
+		}
+		if seen[r.TermMonths] {
+			return fmt.Errorf("term %d months is listed twice", r.TermMonths)
+		}
+		seen[r.TermMonths] = true
+	}
+	sort.Slice(offer.Rates, func(i, j int) bool { return offer.Rates[i].TermMonths < offer.Rates[j].TermMonths })
+	if offer.ValidUntil != nil && !offer.ValidUntil.After(time.Now()) {
+		return errors.New("valid_until must be in the future")
+	}
+	return nil
+}
+
+// ---------- Quotes ----------
+
+// OfferQuote is the installment table of one finance offer for a car
+type OfferQuote struct {
+	Offer        *entities.FinanceOffer `json:"offer"`
+	DownPercent  float64                `json:"down_percent"`
+	Installments []*Installment         `json:"installments"`
+}
+
+// GetCarFinance quotes every offer that applies to a listed car. The down
+// payment is raised to an offer's minimum where needed.
+func (u *FinanceUsecase) GetCarFinance(carID uint, downPercent float64) ([]*OfferQuote, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil || !car.IsPublic(time.Now()) {
+		return nil, errors.New("car not found")
+	}
+
+	var offers []*entities.FinanceOffer
+	if err := u.FinanceOfferRepo.FindActiveForCar(car.DealerID, car.ID, &offers); err != nil {
+		return nil, err
+	}
+	quotes := []*OfferQuote{}
+	for _, o := range offers {
+		quote := &OfferQuote{Offer: o, DownPercent: downPercent}
+		if quote.DownPercent < o.MinDownPercent {
+			quote.DownPercent = o.MinDownPercent
+		}
+		for _, r := range o.Rates {
+			in, err := Calculate(car.Price, quote.DownPercent, r.TermMonths, r.RatePercent, true)
+			if err != nil {
+				return nil, err
+			}
+			quote.Installments = append(quote.Installments, in)
+		}
+		quotes = append(quotes, quote)
+	}
+	return quotes, nil
+}
+
+// ---------- "from ฿X/month" ----------
+
+// RefreshCar recomputes the lowest monthly installment shown on a listing:
+// the cheapest term of any applicable offer at its minimum down payment
+func (u *FinanceUsecase) RefreshCar(car *entities.Car) error {
+	var offers []*entities.FinanceOffer
+	if err := u.FinanceOfferRepo.FindActiveForCar(car.DealerID, car.ID, &offers); err != nil {
+		return err
+	}
+
+	var lowest *float64
+	for _, o := range offers {
+		for _, r := range o.Rates {
+			in, err := Calculate(car.Price, o.MinDownPercent, r.TermMonths, r.RatePercent, true)
+			if err != nil {
+				continue
+			}
+			if lowest == nil || in.Monthly < *lowest {
+				monthly := in.Monthly
+				lowest = &monthly
+			}
+		}
+	}
+	car.MonthlyFrom = lowest
+	return u.CarRepo.UpdateMonthlyFrom(car.ID, lowest)
+}
+
+// refreshOfferCars recomputes the cars an offer applies to
+func (u *FinanceUsecase) refreshOfferCars(offer *entities.FinanceOffer) error {
+	var cars []*entities.Car
+	if offer.CarID != nil {
+		var car entities.Car
+		if err := u.CarRepo.FindByID(*offer.CarID, &car); err != nil {
+			return nil // the car was deleted
+		}
+		cars = append(cars, &car)
+	} else if err := u.CarRepo.FindByDealerID(offer.DealerID, &cars); err != nil {
+		return err
+	}
+
+	for _, c := range cars {
+		if err := u.RefreshCar(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpireOffers switches off offers past their end date and updates the
+// installments shown on the affected listings (background job)
+func (u *FinanceUsecase) ExpireOffers() error {
+	offers, err := u.FinanceOfferRepo.DeactivateExpired()
+	if err != nil {
+		return err
+	}
+	for _, o := range offers {
+		if err := u.refreshOfferCars(o); err != nil {
+			log.Printf("finance refresh failed for offer %d: %v", o.ID, err)
+		}
+	}
+	return nil
+}
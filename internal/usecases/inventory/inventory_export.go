@@ -0,0 +1,104 @@
+package inventory
+
+import (
+	"Backend_Go/internal/entities"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// exportColumns uses the import header names so an export can be edited and re-imported
+var exportColumns = []string{
+	"id", "brand", "model_name", "year", "mileage", "price", "car_type", "fuel_type",
+	"transmission", "color", "status", "views", "call_count", "line_count", "lead_count",
+	"is_promoted", "image_count", "created_at", "updated_at", "description",
+}
+
+// ExportInventory returns all cars of the dealer as a CSV or XLSX file
+func (u *InventoryUsecase) ExportInventory(dealerID uint, format string) ([]byte, error) {
+	if format != "csv" && format != "xlsx" {
+		return nil, errors.New("format must be csv or xlsx")
+	}
+
+	var cars []*entities.Car
+	if err := u.CarRepo.FindByDealerID(dealerID, &cars); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(cars)+1)
+	rows = append(rows, exportColumns)
+	for _, c := range cars {
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(c.ID), 10),
+			c.Brand,
+			c.ModelName,
+			strconv.Itoa(c.Year),
+			strconv.Itoa(c.Mileage),
+			strconv.FormatFloat(c.Price, 'f', -1, 64),
+			c.CarType,
+			c.FuelType,
+			c.Transmission,
+			c.Color,
+			c.Status,
+			strconv.Itoa(c.Views),
+			strconv.Itoa(c.CallCount),
+			strconv.Itoa(c.LineCount),
+			strconv.Itoa(c.LeadCount),
+			strconv.FormatBool(c.IsPromoted),
+			strconv.Itoa(len(c.CarImages)),
+			c.CreatedAt.Format(time.RFC3339),
+			c.UpdatedAt.Format(time.RFC3339),
+			c.Description,
+		})
+	}
+
+	if format == "xlsx" {
+		return writeXLSX(rows)
+	}
+
+	var buf bytes.Buffer
+	// BOM so Excel opens Thai text as UTF-8
+	buf.WriteString("\xef\xbb\xbf")
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeXLSX(rows [][]string) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "Inventory"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		values := make([]interface{}, len(row))
+		for j, v := range row {
+			values[j] = v
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := sw.SetRow(cell, values); err != nil {
+			return nil, err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return nil, err
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
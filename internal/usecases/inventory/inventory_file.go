@@ -0,0 +1,142 @@
+package inventory
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/usecases/car"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// readRows returns all rows of a CSV file or of the first XLSX sheet
+func readRows(format string, data []byte) ([][]string, error) {
+	if format == "xlsx" {
+		f, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("workbook has no sheets")
+		}
+		return f.GetRows(sheets[0])
+	}
+
+	// Excel exports CSV with a UTF-8 BOM
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	return r.ReadAll()
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func findImportField(name string) *importField {
+	for i := range importFields {
+		if importFields[i].Name == name {
+			return &importFields[i]
+		}
+	}
+	return nil
+}
+
+// resolveColumns maps each car field to its column index using the explicit
+// mapping first and the header aliases otherwise
+func resolveColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := map[string]int{}
+	for i, h := range header {
+		index[normalizeHeader(h)] = i
+	}
+
+	columns := map[string]int{}
+	for _, f := range importFields {
+		if h, ok := mapping[f.Name]; ok && strings.TrimSpace(h) != "" {
+			i, found := index[normalizeHeader(h)]
+			if !found {
+				return nil, fmt.Errorf("column %q mapped to %s not found", h, f.Name)
+			}
+			columns[f.Name] = i
+			continue
+		}
+		for _, alias := range f.Aliases {
+			if i, found := index[normalizeHeader(alias)]; found {
+				columns[f.Name] = i
+				break
+			}
+		}
+		if _, found := columns[f.Name]; !found && f.Required {
+			return nil, fmt.Errorf("missing required column for %s", f.Name)
+		}
+	}
+	return columns, nil
+}
+
+func normalizeHeader(h string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(h)), " ", "_")
+}
+
+// rowToCar converts one row to a car and collects its validation errors
+func rowToCar(row []string, columns map[string]int) (*entities.Car, []entities.ImportRowError) {
+	cell := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var errs []entities.ImportRowError
+	invalid := map[string]bool{}
+	number := func(field string) float64 {
+		raw := cell(field)
+		if raw == "" {
+			return 0
+		}
+		v, err := parseNumber(raw)
+		if err != nil {
+			invalid[field] = true
+			errs = append(errs, entities.ImportRowError{Field: field, Message: fmt.Sprintf("%q is not a number", raw)})
+		}
+		return v
+	}
+
+	c := &entities.Car{
+		Brand:        cell("brand"),
+		ModelName:    cell("model_name"),
+		Year:         int(number("year")),
+		Mileage:      int(number("mileage")),
+		Price:        number("price"),
+		CarType:      cell("car_type"),
+		FuelType:     cell("fuel_type"),
+		Transmission: cell("transmission"),
+		Color:        cell("color"),
+		Description:  cell("description"),
+	}
+
+	for _, fe := range car.ValidateCar(c) {
+		if !invalid[fe.Field] {
+			errs = append(errs, entities.ImportRowError{Field: fe.Field, Message: fe.Message})
+		}
+	}
+	return c, errs
+}
+
+// parseNumber accepts values like "1,250,000", "฿ 459000" or "85000 km"
+func parseNumber(raw string) (float64, error) {
+	s := strings.NewReplacer(",", "", "฿", "", "บาท", "", "km", "", "กม.", "", " ", "").Replace(strings.ToLower(raw))
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,249 @@
+package inventory
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/car"
+	"Backend_Go/internal/usecases/notification"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxImportErrors caps the row errors stored on a job
+const maxImportErrors = 1000
+
+// นำเข้า/ส่งออกสต็อกรถของดีลเลอร์
+type InventoryUsecase struct {
+	ImportJobRepo       *repositories.ImportJobRepository
+	CarRepo             *repositories.CarRepository
+	CarUsecase          *car.CarUsecase
+	NotificationUsecase *notification.NotificationUsecase
+
+	// importSlots bounds how many imports run at once (INVENTORY_IMPORT_WORKERS, default 2)
+	importSlots     chan struct{}
+	importSlotsOnce sync.Once
+}
+
+// importField is a car field an inventory file can fill, with accepted header names
+type importField struct {
+	Name     string
+	Aliases  []string
+	Required bool
+}
+
+var importFields = []importField{
+	{"brand", []string{"brand", "make", "ยี่ห้อ"}, true},
+	{"model_name", []string{"model_name", "model", "รุ่น"}, true},
+	{"year", []string{"year", "ปี"}, true},
+	{"mileage", []string{"mileage", "km", "เลขไมล์"}, false},
+	{"price", []string{"price", "ราคา"}, true},
+	{"car_type", []string{"car_type", "type", "ประเภท"}, false},
+	{"fuel_type", []string{"fuel_type", "fuel", "เชื้อเพลิง"}, false},
+	{"transmission", []string{"transmission", "gear", "เกียร์"}, false},
+	{"color", []string{"color", "colour", "สี"}, false},
+	{"description", []string{"description", "รายละเอียด"}, false},
+}
+
+// StartImport queues an inventory file for import and processes it in the background.
+// mapping optionally maps car fields to column headers; otherwise headers are matched by name.
+// With dryRun the rows are only validated.
+func (u *InventoryUsecase) StartImport(dealerID, userID uint, fileName string, data []byte, mapping map[string]string, dryRun bool) (*entities.ImportJob, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	if format != "csv" && format != "xlsx" {
+		return nil, errors.New("file must be .csv or .xlsx")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	for field := range mapping {
+		if findImportField(field) == nil {
+			return nil, fmt.Errorf("unknown field %q in mapping", field)
+		}
+	}
+
+	job := &entities.ImportJob{
+		DealerID: dealerID,
+		UserID:   userID,
+		FileName: filepath.Base(fileName),
+		Format:   format,
+		DryRun:   dryRun,
+		Mapping:  mapping,
+		Status:   entities.ImportQueued,
+		Errors:   []entities.ImportRowError{},
+	}
+	if err := u.ImportJobRepo.Create(job); err != nil {
+		return nil, err
+	}
+
+	go u.runImport(job, data)
+	return job, nil
+}
+
+// GetImportJob returns a job owned by the dealer
+func (u *InventoryUsecase) GetImportJob(dealerID, jobID uint) (*entities.ImportJob, error) {
+	var job entities.ImportJob
+	if err := u.ImportJobRepo.FindByID(jobID, &job); err != nil || job.DealerID != dealerID {
+		return nil, errors.New("import job not found")
+	}
+	return &job, nil
+}
+
+func (u *InventoryUsecase) GetImportJobs(dealerID uint) ([]*entities.ImportJob, error) {
+	jobs := []*entities.ImportJob{}
+	if err := u.ImportJobRepo.FindByDealerID(dealerID, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// FailInterruptedImports fails the jobs left queued or running by a previous process
+func (u *InventoryUsecase) FailInterruptedImports() error {
+	n, err := u.ImportJobRepo.FailUnfinished("import was interrupted by a server restart, please upload the file again")
+	if err == nil && n > 0 {
+		log.Printf("inventory import: marked %d interrupted jobs as failed", n)
+	}
+	return err
+}
+
+func (u *InventoryUsecase) slots() chan struct{} {
+	u.importSlotsOnce.Do(func() {
+		workers, err := strconv.Atoi(utils.GetEnv("INVENTORY_IMPORT_WORKERS", "2"))
+		if err != nil || workers < 1 {
+			workers = 2
+		}
+		u.importSlots = make(chan struct{}, workers)
+	})
+	return u.importSlots
+}
+
+// runImport waits for a free slot (the job stays queued meanwhile) and processes the file
+func (u *InventoryUsecase) runImport(job *entities.ImportJob, data []byte) {
+	slots := u.slots()
+	slots <- struct{}{}
+	defer func() { <-slots }()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("inventory import %d panicked: %v", job.ID, r)
+			u.finishImport(job, entities.ImportFailed, "internal error")
+		}
+	}()
+
+	now := time.Now()
+	job.Status = entities.ImportRunning
+	job.StartedAt = &now
+	if err := u.ImportJobRepo.Update(job); err != nil {
+		log.Printf("inventory import %d: %v", job.ID, err)
+	}
+
+	rows, err := readRows(job.Format, data)
+	if err != nil {
+		u.finishImport(job, entities.ImportFailed, "cannot read file: "+err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		u.finishImport(job, entities.ImportFailed, "file is empty")
+		return
+	}
+	for _, row := range rows[1:] {
+		if !isEmptyRow(row) {
+			job.TotalRows++
+		}
+	}
+	if job.TotalRows == 0 {
+		u.finishImport(job, entities.ImportFailed, "file has no data rows")
+		return
+	}
+	maxRows, _ := strconv.Atoi(utils.GetEnv("INVENTORY_IMPORT_MAX_ROWS", "1000"))
+	if maxRows > 0 && job.TotalRows > maxRows {
+		u.finishImport(job, entities.ImportFailed, fmt.Sprintf("file has more than %d rows", maxRows))
+		return
+	}
+
+	columns, err := resolveColumns(rows[0], job.Mapping)
+	if err != nil {
+		u.finishImport(job, entities.ImportFailed, err.Error())
+		return
+	}
+
+	for i, row := range rows[1:] {
+		if isEmptyRow(row) {
+			continue
+		}
+		rowNumber := i + 2
+		c, rowErrs := rowToCar(row, columns)
+		if len(rowErrs) == 0 {
+			if err := u.importRow(job, c); err != nil {
+				rowErrs = append(rowErrs, entities.ImportRowError{Message: err.Error()})
+			}
+		}
+		for _, e := range rowErrs {
+			if len(job.Errors) < maxImportErrors {
+				e.Row = rowNumber
+				job.Errors = append(job.Errors, e)
+			}
+		}
+
+		job.ProcessedRows++
+		if job.ProcessedRows%50 == 0 {
+			if err := u.ImportJobRepo.Update(job); err != nil {
+				log.Printf("inventory import %d: %v", job.ID, err)
+			}
+		}
+	}
+
+	u.finishImport(job, entities.ImportCompleted, "")
+}
+
+// importRow checks a parsed row the way CreateCar does (catalog, validation,
+// dealer) and, unless the job is a dry run, creates the car. Only rows that
+// pass are counted as valid.
+func (u *InventoryUsecase) importRow(job *entities.ImportJob, c *entities.Car) error {
+	c.DealerID = job.DealerID
+	if job.DryRun {
+		if err := u.CarUsecase.PrepareCar(c); err != nil {
+			return err
+		}
+		job.ValidRows++
+		return nil
+	}
+	if err := u.CarUsecase.CreateCar(c); err != nil {
+		return err
+	}
+	job.ValidRows++
+	job.CreatedCount++
+	return nil
+}
+
+func (u *InventoryUsecase) finishImport(job *entities.ImportJob, status, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Message = message
+	job.FinishedAt = &now
+	if err := u.ImportJobRepo.Update(job); err != nil {
+		log.Printf("inventory import %d: %v", job.ID, err)
+	}
+
+	if u.NotificationUsecase == nil || job.DryRun {
+		return
+	}
+	body := fmt.Sprintf("นำเข้ารถ %d คัน จาก %d แถว (ผิดพลาด %d แถว)", job.CreatedCount, job.TotalRows, job.TotalRows-job.CreatedCount)
+	if status == entities.ImportFailed {
+		body = "นำเข้าไม่สำเร็จ: " + message
+	}
+	if err := u.NotificationUsecase.Notify(&entities.Notification{
+		UserID: job.UserID,
+		Type:   "inventory_import",
+		Title:  "นำเข้าไฟล์ " + job.FileName + " เสร็จสิ้น",
+		Body:   body,
+	}); err != nil {
+		log.Printf("inventory import %d notification: %v", job.ID, err)
+	}
+}
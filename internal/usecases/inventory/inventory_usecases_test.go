@@ -0,0 +1,169 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/car"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestImportSlots(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int
+	}{
+		{"", 2},
+		{"3", 3},
+		{"0", 2},
+		{"abc", 2},
+	}
+	for _, tt := range tests {
+		t.Setenv("INVENTORY_IMPORT_WORKERS", tt.env)
+		u := &InventoryUsecase{}
+		if got := cap(u.slots()); got != tt.want {
+			t.Errorf("INVENTORY_IMPORT_WORKERS=%q: %d slots, want %d", tt.env, got, tt.want)
+		}
+		if u.slots() != u.slots() {
+			t.Errorf("slots are recreated on every call")
+		}
+	}
+}
+
+func TestImportSlotsBlockWhenFull(t *testing.T) {
+	t.Setenv("INVENTORY_IMPORT_WORKERS", "1")
+	u := &InventoryUsecase{}
+	slots := u.slots()
+	slots <- struct{}{}
+
+	acquired := make(chan struct{})
+	go func() {
+		slots <- struct{}{}
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("a second import started while the only slot was taken")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	<-slots
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("the waiting import did not start after the slot was freed")
+	}
+}
+
+func TestResolveColumns(t *testing.T) {
+	header := []string{"ยี่ห้อ", "Model", "Year", "Price", "Fuel Type"}
+
+	columns, err := resolveColumns(header, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"brand": 0, "model_name": 1, "year": 2, "price": 3, "fuel_type": 4}
+	for field, i := range want {
+		if columns[field] != i {
+			t.Errorf("%s -> column %d, want %d", field, columns[field], i)
+		}
+	}
+
+	if _, err := resolveColumns(header, map[string]string{"color": "Colour"}); err == nil {
+		t.Error("mapping to a missing column was accepted")
+	}
+	if _, err := resolveColumns([]string{"brand", "model"}, nil); err == nil {
+		t.Error("missing required columns were accepted")
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want float64
+	}{
+		{"1,250,000", 1250000},
+		{"฿ 459000", 459000},
+		{"85000 km", 85000},
+	}
+	for _, tt := range tests {
+		got, err := parseNumber(tt.raw)
+		if err != nil || got != tt.want {
+			t.Errorf("parseNumber(%q) = %v, %v; want %v", tt.raw, got, err, tt.want)
+		}
+	}
+	if _, err := parseNumber("n/a"); err == nil {
+		t.Error(`parseNumber("n/a") succeeded`)
+	}
+}
+
+// mockDB returns a postgres gorm DB backed by sqlmock
+func mockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	return db, mock
+}
+
+func TestImportRowChecksDryRunsLikeImports(t *testing.T) {
+	newCar := func() *entities.Car {
+		return &entities.Car{Brand: "Toyota", ModelName: "Vios", Year: 2019, Price: 389000, Mileage: 60000,
+			CarType: "sedan", FuelType: "gasoline", Transmission: "auto"}
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		db, mock := mockDB(t)
+		u := &InventoryUsecase{CarUsecase: &car.CarUsecase{
+			CarRepo:    &repositories.CarRepository{DB: db},
+			DealerRepo: &repositories.DealerRepository{DB: db},
+		}}
+		job := &entities.ImportJob{DealerID: 4, DryRun: dryRun}
+
+		// The dealer no longer exists: the row fails in both modes and is not counted
+		mock.ExpectQuery(`SELECT \* FROM "dealers" WHERE "dealers"."id" = \$1`).
+			WithArgs(4, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		if err := u.importRow(job, newCar()); err == nil {
+			t.Errorf("dry run %v: row of a missing dealer was accepted", dryRun)
+		}
+		if job.ValidRows != 0 || job.CreatedCount != 0 {
+			t.Errorf("dry run %v: failed row counted: valid %d, created %d", dryRun, job.ValidRows, job.CreatedCount)
+		}
+
+		mock.ExpectQuery(`SELECT \* FROM "dealers" WHERE "dealers"."id" = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		if !dryRun {
+			mock.ExpectQuery(`INSERT INTO "cars"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+		}
+		if err := u.importRow(job, newCar()); err != nil {
+			t.Fatalf("dry run %v: %v", dryRun, err)
+		}
+		wantCreated := 1
+		if dryRun {
+			wantCreated = 0
+		}
+		if job.ValidRows != 1 || job.CreatedCount != wantCreated {
+			t.Errorf("dry run %v: valid %d, created %d; want 1, %d", dryRun, job.ValidRows, job.CreatedCount, wantCreated)
+		}
+	}
+}
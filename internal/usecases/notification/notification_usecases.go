@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/ws"
+	"Backend_Go/utils"
+	"log"
+)
+
+// แจ้งเตือนในแอป + websocket + webhook (ถ้าตั้งค่า NOTIFY_WEBHOOK_URL)
+type NotificationUsecase struct {
+	NotificationRepo *repositories.NotificationRepository
+	Hub              *ws.Hub
+}
+
+// Notify stores the notification and pushes it to every configured channel
+func (u *NotificationUsecase) Notify(n *entities.Notification) error {
+	if err := u.NotificationRepo.Create(n); err != nil {
+		return err
+	}
+
+	if u.Hub != nil {
+		u.Hub.BroadcastToUser(n.UserID, map[string]interface{}{
+			"type":         "notification",
+			"notification": n,
+		})
+	}
+
+	if webhook := utils.GetEnv("NOTIFY_WEBHOOK_URL", ""); webhook != "" {
+		go func() {
+			if err := utils.SendWebhookNotification(webhook, map[string]interface{}{
+				"event":        "notification",
+				"notification": n,
+			}); err != nil {
+				log.Printf("notification webhook failed: %v", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// ดูการแจ้งเตือนของผู้ใช้
+func (u *NotificationUsecase) GetNotifications(userID uint, unreadOnly bool) ([]*entities.Notification, error) {
+	notifications := []*entities.Notification{}
+	if err := u.NotificationRepo.FindByUserID(userID, unreadOnly, 100, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (u *NotificationUsecase) GetUnreadCount(userID uint) (int64, error) {
+	return u.NotificationRepo.CountUnread(userID)
+}
+
+// MarkRead marks one notification as read; id 0 marks all of them
+func (u *NotificationUsecase) MarkRead(userID, id uint) error {
+	return u.NotificationRepo.MarkRead(userID, id)
+}
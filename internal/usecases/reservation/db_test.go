@@ -0,0 +1,41 @@
+package reservation
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// mockDB returns a postgres gorm DB backed by sqlmock. Every expected statement
+// must run by the end of the test.
+func mockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	return db, mock
+}
+
+// expectNotification expects one stored notification of the given type for userID
+func expectNotification(mock sqlmock.Sqlmock, userID uint, kind string) {
+	anyArg := sqlmock.AnyArg()
+	mock.ExpectQuery(`INSERT INTO "notifications"`).
+		WithArgs(anyArg, anyArg, anyArg, userID, kind, anyArg, anyArg, anyArg, anyArg).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
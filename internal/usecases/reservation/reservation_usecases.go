@@ -0,0 +1,370 @@
+package reservation
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/car"
+	"Backend_Go/internal/usecases/notification"
+	"Backend_Go/utils"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// จองรถพร้อมมัดจำ
+type ReservationUsecase struct {
+	ReservationRepo     *repositories.ReservationRepository
+	CarRepo             *repositories.CarRepository
+	LeadRepo            *repositories.LeadRepository
+	CarUsecase          *car.CarUsecase
+	NotificationUsecase *notification.NotificationUsecase
+}
+
+// Parties of a reservation
+const (
+	PartyCustomer = "customer"
+	PartyDealer   = "dealer"
+)
+
+// Party identifies who acts on a reservation. ID is the customer's user id or
+// the dealer id; UserID is the acting user, recorded in the audit trail.
+type Party struct {
+	Role   string
+	ID     uint
+	UserID uint
+}
+
+// maxHoldHours caps how long a dealer can hold a car for one reservation
+const maxHoldHours = 14 * 24
+
+// holdHours is RESERVATION_HOLD_HOURS (default 72): the hold when the dealer does not set one
+func holdHours() int {
+	n, err := strconv.Atoi(utils.GetEnv("RESERVATION_HOLD_HOURS", "72"))
+	if err != nil || n <= 0 || n > maxHoldHours {
+		return 72
+	}
+	return n
+}
+
+// Request asks the dealer to hold an available car for the customer. The
+// request is linked to the customer's lead for the car.
+func (u *ReservationUsecase) Request(customerID, carID uint, note string) (*entities.Reservation, error) {
+	var c entities.Car
+	if err := u.CarRepo.FindByID(carID, &c); err != nil || c.IsHidden {
+		return nil, errors.New("car not found")
+	}
+	if c.Status == entities.CarStatusReserved {
+		return nil, errors.New("this car is already reserved")
+	}
+	if c.Status != entities.CarStatusApproved && c.Status != entities.CarStatusSelling {
+		return nil, errors.New("this car is not available for reservation")
+	}
+	if c.Dealer.UserID == customerID {
+		return nil, errors.New("you cannot reserve your own car")
+	}
+	open, err := u.ReservationRepo.HasOpen(c.ID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, errors.New("you already have a reservation request for this car")
+	}
+
+	reservation := &entities.Reservation{
+		CarID:      c.ID,
+		DealerID:   c.DealerID,
+		CustomerID: customerID,
+		Status:     entities.ReservationRequested,
+		Note:       strings.TrimSpace(note),
+	}
+	if leadID, err := u.linkLead(&c, customerID); err != nil {
+		log.Printf("lead link failed for reservation on car %d: %v", c.ID, err)
+	} else {
+		reservation.LeadID = &leadID
+	}
+	if err := u.ReservationRepo.Create(reservation, &entities.ReservationEvent{
+		ToStatus:  entities.ReservationRequested,
+		ActorID:   customerID,
+		ActorRole: PartyCustomer,
+	}); err != nil {
+		return nil, err
+	}
+
+	reservation.Car = c
+	u.notify(c.Dealer.UserID, reservation, "reservation_requested", "มีคำขอจองรถใหม่",
+		fmt.Sprintf("ลูกค้าขอจองรถ %s %s", c.Brand, c.ModelName))
+	return reservation, nil
+}
+
+// linkLead returns the customer's lead for the car, creating one for a first contact
+func (u *ReservationUsecase) linkLead(c *entities.Car, customerID uint) (uint, error) {
+	lead := entities.Lead{CarID: c.ID, DealerID: c.DealerID, CustomerID: &customerID, ContactVia: "reservation"}
+	created, err := u.LeadRepo.FindOrCreate(&lead)
+	if err != nil {
+		return 0, err
+	}
+	if created {
+		if err := u.CarRepo.IncrementLeadCount(c.ID); err != nil {
+			return 0, err
+		}
+	}
+	return lead.ID, nil
+}
+
+// Get returns a reservation with its audit trail to the customer or dealer
+func (u *ReservationUsecase) Get(party Party, id uint) (*entities.Reservation, error) {
+	return u.load(id, party)
+}
+
+// GetMyReservations lists a customer's reservations, latest first
+func (u *ReservationUsecase) GetMyReservations(customerID uint) ([]*entities.Reservation, error) {
+	reservations := []*entities.Reservation{}
+	if err := u.ReservationRepo.FindByCustomerID(customerID, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// GetDealerReservations lists the dealer's reservations, optionally in one status
+func (u *ReservationUsecase) GetDealerReservations(dealerID uint, status string) ([]*entities.Reservation, error) {
+	reservations := []*entities.Reservation{}
+	if err := u.ReservationRepo.FindByDealerID(dealerID, status, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// Accept holds the car for the customer against a deposit. The car shows as
+// reserved until the hold (hours, default RESERVATION_HOLD_HOURS) ends, and
+// the other requests for the car are rejected.
+func (u *ReservationUsecase) Accept(party Party, id uint, deposit float64, hours int) (*entities.Reservation, error) {
+	r, err := u.load(id, party)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != entities.ReservationRequested {
+		return nil, fmt.Errorf("a %s reservation cannot be accepted", r.Status)
+	}
+	if deposit < 0 {
+		return nil, errors.New("deposit_amount must not be negative")
+	}
+	if hours == 0 {
+		hours = holdHours()
+	}
+	if hours < 1 || hours > maxHoldHours {
+		return nil, fmt.Errorf("hours must be between 1 and %d", maxHoldHours)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(hours) * time.Hour)
+	r.Status, r.DepositAmount, r.AcceptedAt, r.ExpiresAt = entities.ReservationAccepted, deposit, &now, &expiresAt
+
+	// The car row is locked while its status is checked, so only one request can win it
+	rejectReason := fmt.Sprintf("the car was reserved for another customer (reservation #%d)", r.ID)
+	rejected, err := u.ReservationRepo.Accept(r,
+		&entities.ReservationEvent{
+			FromStatus: entities.ReservationRequested,
+			ToStatus:   entities.ReservationAccepted,
+			ActorID:    party.UserID,
+			ActorRole:  party.Role,
+		},
+		&entities.CarStatusHistory{
+			CarID:     r.CarID,
+			ToStatus:  entities.CarStatusReserved,
+			ActorID:   party.UserID,
+			ActorRole: car.ActorDealer,
+			Reason:    fmt.Sprintf("reservation #%d", r.ID),
+		},
+		func(c *entities.Car) error {
+			return car.CanTransition(c.Status, entities.CarStatusReserved, car.ActorDealer)
+		},
+		rejectReason)
+	if err != nil {
+		return nil, err
+	}
+
+	u.notify(r.CustomerID, r, "reservation_accepted", "ร้านรับจองรถแล้ว",
+		fmt.Sprintf("รถ %s %s ถูกจองให้คุณถึงวันที่ %s", r.Car.Brand, r.Car.ModelName, formatTime(expiresAt)))
+	for _, other := range rejected {
+		u.notify(other.CustomerID, other, "reservation_rejected", "ร้านปฏิเสธคำขอจองรถ",
+			fmt.Sprintf("รถ %s %s ถูกจองให้ลูกค้ารายอื่นแล้ว", r.Car.Brand, r.Car.ModelName))
+	}
+	return r, nil
+}
+
+// Reject declines a reservation request (dealer)
+func (u *ReservationUsecase) Reject(party Party, id uint, reason string) (*entities.Reservation, error) {
+	r, err := u.load(id, party)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != entities.ReservationRequested {
+		return nil, fmt.Errorf("a %s reservation cannot be rejected", r.Status)
+	}
+	if err := u.changeStatus(r, entities.ReservationRejected, party, reason); err != nil {
+		return nil, err
+	}
+
+	u.notify(r.CustomerID, r, "reservation_rejected", "ร้านปฏิเสธคำขอจองรถ",
+		withReason(fmt.Sprintf("คำขอจองรถ %s %s ถูกปฏิเสธ", r.Car.Brand, r.Car.ModelName), reason))
+	return r, nil
+}
+
+// Cancel withdraws a request or gives up an accepted reservation (customer).
+// A held car goes back on sale.
+func (u *ReservationUsecase) Cancel(party Party, id uint, reason string) (*entities.Reservation, error) {
+	r, err := u.load(id, party)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != entities.ReservationRequested && r.Status != entities.ReservationAccepted {
+		return nil, fmt.Errorf("a %s reservation cannot be cancelled", r.Status)
+	}
+	if r.Status == entities.ReservationAccepted {
+		err = u.endHold(r, entities.ReservationCancelled, party, reason,
+			car.Actor{UserID: party.UserID, Role: car.ActorSystem}, fmt.Sprintf("reservation #%d cancelled by customer", r.ID))
+	} else {
+		err = u.changeStatus(r, entities.ReservationCancelled, party, reason)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	u.notify(r.Car.Dealer.UserID, r, "reservation_cancelled", "ลูกค้ายกเลิกการจองรถ",
+		withReason(fmt.Sprintf("การจองรถ %s %s ถูกยกเลิกโดยลูกค้า", r.Car.Brand, r.Car.ModelName), reason))
+	return r, nil
+}
+
+// Release ends an accepted reservation early and puts the car back on sale (dealer)
+func (u *ReservationUsecase) Release(party Party, id uint, reason string) (*entities.Reservation, error) {
+	r, err := u.load(id, party)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != entities.ReservationAccepted {
+		return nil, fmt.Errorf("a %s reservation cannot be released", r.Status)
+	}
+	if err := u.endHold(r, entities.ReservationReleased, party, reason,
+		car.Actor{UserID: party.UserID, Role: car.ActorDealer}, fmt.Sprintf("reservation #%d released", r.ID)); err != nil {
+		return nil, err
+	}
+
+	u.notify(r.CustomerID, r, "reservation_released", "ร้านยกเลิกการจองรถ",
+		withReason(fmt.Sprintf("การจองรถ %s %s สิ้นสุดแล้ว", r.Car.Brand, r.Car.ModelName), reason))
+	return r, nil
+}
+
+// Complete closes an accepted reservation with the sale of the car to the
+// customer (dealer). The sale details default to the asking price and now.
+func (u *ReservationUsecase) Complete(party Party, id uint, in car.SaleInput) (*entities.Reservation, error) {
+	r, err := u.load(id, party)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != entities.ReservationAccepted {
+		return nil, fmt.Errorf("a %s reservation cannot be completed", r.Status)
+	}
+	if r.Car.Status != entities.CarStatusReserved {
+		return nil, errors.New("the car is no longer reserved")
+	}
+
+	// Selling the reserved car completes the reservation (see CarUsecase.Transition)
+	in.BuyerID, in.ReservationID = &r.CustomerID, &r.ID
+	if _, err := u.CarUsecase.MarkSold(r.CarID, car.Actor{UserID: party.UserID, Role: car.ActorDealer}, in); err != nil {
+		return nil, err
+	}
+	return u.load(id, party)
+}
+
+// ExpireReservations releases cars whose reservation hold has ended (background
+// job). A reservation that fails is logged and retried on the next run.
+func (u *ReservationUsecase) ExpireReservations() error {
+	var expired []*entities.Reservation
+	if err := u.ReservationRepo.FindExpired(&expired); err != nil {
+		return err
+	}
+
+	system := Party{Role: car.ActorSystem}
+	for _, r := range expired {
+		if err := u.endHold(r, entities.ReservationExpired, system, "reservation hold ended",
+			car.Actor{Role: car.ActorSystem}, fmt.Sprintf("reservation #%d expired", r.ID)); err != nil {
+			log.Printf("failed to expire reservation %d: %v", r.ID, err)
+			continue
+		}
+
+		body := fmt.Sprintf("การจองรถ %s %s หมดเวลาแล้ว รถกลับมาประกาศขายอีกครั้ง", r.Car.Brand, r.Car.ModelName)
+		u.notify(r.CustomerID, r, "reservation_expired", "การจองรถหมดเวลา", body)
+		u.notify(r.Car.Dealer.UserID, r, "reservation_expired", "การจองรถหมดเวลา", body)
+	}
+	return nil
+}
+
+// changeStatus moves a reservation to a new status and records the audit event.
+// It fails with ErrReservationChanged if the reservation was acted on meanwhile.
+func (u *ReservationUsecase) changeStatus(r *entities.Reservation, to string, party Party, reason string) error {
+	return u.ReservationRepo.UpdateStatus(r, statusEvent(r, to, party, reason))
+}
+
+// endHold is changeStatus for an accepted reservation: the car it holds goes
+// back on sale in the same transaction. A car that has meanwhile left the
+// reserved status is left alone.
+func (u *ReservationUsecase) endHold(r *entities.Reservation, to string, party Party, reason string, actor car.Actor, carReason string) error {
+	return u.ReservationRepo.EndHold(r, statusEvent(r, to, party, reason), func(c *entities.Car) (*entities.CarStatusHistory, error) {
+		return car.PrepareTransition(c, entities.CarStatusSelling, actor, carReason)
+	})
+}
+
+// statusEvent sets the reservation's new status and returns its audit event
+func statusEvent(r *entities.Reservation, to string, party Party, reason string) *entities.ReservationEvent {
+	from := r.Status
+	r.Status = to
+	return &entities.ReservationEvent{
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    party.UserID,
+		ActorRole:  party.Role,
+		Reason:     strings.TrimSpace(reason),
+	}
+}
+
+// load returns a reservation of the customer or dealer acting on it
+func (u *ReservationUsecase) load(id uint, party Party) (*entities.Reservation, error) {
+	var r entities.Reservation
+	if err := u.ReservationRepo.FindByID(id, &r); err != nil {
+		return nil, errors.New("reservation not found")
+	}
+	if (party.Role == PartyCustomer && r.CustomerID != party.ID) || (party.Role == PartyDealer && r.DealerID != party.ID) {
+		return nil, errors.New("reservation not found")
+	}
+	return &r, nil
+}
+
+func (u *ReservationUsecase) notify(userID uint, r *entities.Reservation, kind, title, body string) {
+	if u.NotificationUsecase == nil || userID == 0 {
+		return
+	}
+	carID := r.CarID
+	if err := u.NotificationUsecase.Notify(&entities.Notification{
+		UserID: userID,
+		Type:   kind,
+		Title:  title,
+		Body:   body,
+		CarID:  &carID,
+	}); err != nil {
+		log.Printf("%s notification failed for reservation %d: %v", kind, r.ID, err)
+	}
+}
+
+func withReason(body, reason string) string {
+	if reason = strings.TrimSpace(reason); reason != "" {
+		return body + " เหตุผล: " + reason
+	}
+	return body
+}
+
+func formatTime(t time.Time) string {
+	return t.In(utils.LocalTimezone()).Format("02/01/2006 15:04")
+}
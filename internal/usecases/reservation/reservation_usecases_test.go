@@ -0,0 +1,97 @@
+package reservation
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/notification"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestHoldHours(t *testing.T) {
+	tests := map[string]int{
+		"":                             72,
+		"24":                           24,
+		"0":                            72,
+		"-5":                           72,
+		"abc":                          72,
+		strconv.Itoa(maxHoldHours):     maxHoldHours,
+		strconv.Itoa(maxHoldHours + 1): 72,
+	}
+	for env, want := range tests {
+		t.Setenv("RESERVATION_HOLD_HOURS", env)
+		if got := holdHours(); got != want {
+			t.Errorf("RESERVATION_HOLD_HOURS=%q: holdHours() = %d, want %d", env, got, want)
+		}
+	}
+}
+
+func TestWithReason(t *testing.T) {
+	if got := withReason("ถูกปฏิเสธ", "  "); got != "ถูกปฏิเสธ" {
+		t.Errorf("blank reason appended: %q", got)
+	}
+	if got := withReason("ถูกปฏิเสธ", " รถขายแล้ว "); got != "ถูกปฏิเสธ เหตุผล: รถขายแล้ว" {
+		t.Errorf("withReason = %q", got)
+	}
+}
+
+func TestExpireReservations(t *testing.T) {
+	db, mock := mockDB(t)
+	u := &ReservationUsecase{
+		ReservationRepo:     &repositories.ReservationRepository{DB: db},
+		NotificationUsecase: &notification.NotificationUsecase{NotificationRepo: &repositories.NotificationRepository{DB: db}},
+	}
+	anyArg := sqlmock.AnyArg()
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT \* FROM "reservations" WHERE \(status = \$1 AND expires_at <= NOW\(\)\)`).
+		WithArgs(entities.ReservationAccepted).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "car_id", "dealer_id", "customer_id", "status"}).
+			AddRow(1, 11, 5, 100, entities.ReservationAccepted).
+			AddRow(2, 12, 5, 101, entities.ReservationAccepted))
+	mock.ExpectQuery(`SELECT \* FROM "cars"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dealer_id", "brand", "model_name", "status"}).
+			AddRow(11, 5, "Honda", "Jazz", entities.CarStatusReserved).
+			AddRow(12, 5, "Toyota", "Vios", entities.CarStatusReserved))
+	mock.ExpectQuery(`SELECT \* FROM "dealers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(5, 50))
+
+	// The first reservation was released meanwhile: it is skipped, not fatal
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE "cars"."id" = \$1 .* FOR UPDATE`).
+		WithArgs(11, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(11, entities.CarStatusSelling))
+	mock.ExpectExec(`UPDATE "reservations" SET "updated_at"=\$1,"status"=\$2 WHERE status = \$3`).
+		WithArgs(anyArg, entities.ReservationExpired, entities.ReservationAccepted, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// The second expires and its car goes back on sale in the same transaction
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "cars" WHERE "cars"."id" = \$1 .* FOR UPDATE`).
+		WithArgs(12, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dealer_id", "status", "expires_at", "reserved_until"}).
+			AddRow(12, 5, entities.CarStatusReserved, now.Add(24*time.Hour), now.Add(-time.Minute)))
+	mock.ExpectExec(`UPDATE "reservations" SET "updated_at"=\$1,"status"=\$2 WHERE status = \$3`).
+		WithArgs(anyArg, entities.ReservationExpired, entities.ReservationAccepted, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "reservation_events"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE "cars" SET "updated_at"=\$1,"status"=\$2,.* WHERE status = \$8 AND "cars"."deleted_at" IS NULL AND "id" = \$9`).
+		WithArgs(anyArg, entities.CarStatusSelling, anyArg, anyArg, nil, anyArg, anyArg, entities.CarStatusReserved, 12).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "car_status_histories"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	expectNotification(mock, 101, "reservation_expired")
+	expectNotification(mock, 50, "reservation_expired")
+
+	if err := u.ExpireReservations(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,27 +1,45 @@
-package review
-
-import (
-	"Backend_Go/internal/entities"
-	"Backend_Go/internal/repositories"
-	"errors"
-)
-
-type ReviewUsecase struct {
-	ReviewRepo *repositories.ReviewRepository
-	DealerRepo *repositories.DealerRepository
-}
-
-// ลูกค้ารีวิวร้าน
-func (u *ReviewUsecase) CreateReview(review interface{}, dealerID uint) error {
-	// ตรวจสอบร้าน
-	var dealer entities.Dealer
-	if err := u.DealerRepo.FindByID(dealerID, &dealer); err != nil {
-		return errors.New("ไม่พบร้านค้า")
-	}
-	return u.ReviewRepo.Create(review)
-}
-
-// ดูรีวิวร้าน
-func (u *ReviewUsecase) GetReviewsByDealer(dealerID uint, reviews interface{}) error {
-	return u.ReviewRepo.FindByDealerID(dealerID, reviews)
-}
+package review
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"errors"
+)
+
+type ReviewUsecase struct {
+	ReviewRepo *repositories.ReviewRepository
+	DealerRepo *repositories.DealerRepository
+	SaleRepo   *repositories.SaleRepository
+}
+
+// ลูกค้ารีวิวร้าน
+// A reviewer who bought a car from the dealer gets a verified-buyer review,
+// linked to a sale that has no review yet.
+func (u *ReviewUsecase) CreateReview(review *entities.Review) error {
+	if review.Rating < 1 || review.Rating > 5 {
+		return errors.New("rating must be between 1 and 5")
+	}
+
+	// ตรวจสอบร้าน
+	var dealer entities.Dealer
+	if err := u.DealerRepo.FindByID(review.DealerID, &dealer); err != nil {
+		return errors.New("ไม่พบร้านค้า")
+	}
+	if dealer.UserID == review.UserID {
+		return errors.New("ไม่สามารถรีวิวร้านของตัวเองได้")
+	}
+
+	review.SaleID, review.VerifiedBuyer = nil, false
+	if u.SaleRepo != nil {
+		var sale entities.Sale
+		if err := u.SaleRepo.FindUnreviewed(review.UserID, review.DealerID, &sale); err == nil {
+			review.SaleID, review.VerifiedBuyer = &sale.ID, true
+		}
+	}
+	return u.ReviewRepo.Create(review)
+}
+
+// ดูรีวิวร้าน
+func (u *ReviewUsecase) GetReviewsByDealer(dealerID uint, reviews interface{}) error {
+	return u.ReviewRepo.FindByDealerID(dealerID, reviews)
+}
@@ -0,0 +1,235 @@
+package savedsearch
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/search"
+	"Backend_Go/internal/usecases/notification"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const maxSavedSearchesPerUser = 20
+
+// บันทึกการค้นหา + แจ้งเตือนรถใหม่ที่ตรงเงื่อนไข
+type SavedSearchUsecase struct {
+	SavedSearchRepo     *repositories.SavedSearchRepository
+	CarRepo             *repositories.CarRepository
+	NotificationUsecase *notification.NotificationUsecase
+}
+
+func (u *SavedSearchUsecase) CreateSavedSearch(userID uint, s *entities.SavedSearch) error {
+	count, err := u.SavedSearchRepo.CountByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if count >= maxSavedSearchesPerUser {
+		return fmt.Errorf("saved search limit reached (%d)", maxSavedSearchesPerUser)
+	}
+
+	s.UserID = userID
+	s.IsActive = true
+	if err := validateSavedSearch(s); err != nil {
+		return err
+	}
+	return u.SavedSearchRepo.Create(s)
+}
+
+func (u *SavedSearchUsecase) GetSavedSearches(userID uint) ([]*entities.SavedSearch, error) {
+	searches := []*entities.SavedSearch{}
+	if err := u.SavedSearchRepo.FindByUserID(userID, &searches); err != nil {
+		return nil, err
+	}
+	return searches, nil
+}
+
+// UpdateSavedSearch replaces name, filters, frequency and active flag of the user's search
+func (u *SavedSearchUsecase) UpdateSavedSearch(userID, id uint, in *entities.SavedSearch) (*entities.SavedSearch, error) {
+	var s entities.SavedSearch
+	if err := u.SavedSearchRepo.FindByID(id, &s); err != nil || s.UserID != userID {
+		return nil, errors.New("saved search not found")
+	}
+
+	s.Name = in.Name
+	s.Filters = in.Filters
+	s.Frequency = in.Frequency
+	s.IsActive = in.IsActive
+	if err := validateSavedSearch(&s); err != nil {
+		return nil, err
+	}
+	return &s, u.SavedSearchRepo.Update(&s)
+}
+
+func (u *SavedSearchUsecase) DeleteSavedSearch(userID, id uint) error {
+	var s entities.SavedSearch
+	if err := u.SavedSearchRepo.FindByID(id, &s); err != nil || s.UserID != userID {
+		return errors.New("saved search not found")
+	}
+	return u.SavedSearchRepo.Delete(id)
+}
+
+// MatchNewCar is called when a car becomes public. Every matching saved search
+// gets a match record; instant searches are notified right away, daily ones
+// wait for SendDailyDigests.
+func (u *SavedSearchUsecase) MatchNewCar(carID uint) error {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil {
+		return err
+	}
+
+	// The repository narrows the searches in SQL; matchesFilter checks the rest.
+	// One subscriber's failure must not cost the others their alert
+	return u.SavedSearchRepo.EachCandidate(&car, func(s *entities.SavedSearch) error {
+		if !matchesFilter(s.Filters, &car) {
+			return nil
+		}
+		if err := u.recordMatch(s, &car); err != nil {
+			log.Printf("saved search %d: match for car %d failed: %v", s.ID, car.ID, err)
+		}
+		return nil
+	})
+}
+
+// recordMatch stores the match and alerts instant searches
+func (u *SavedSearchUsecase) recordMatch(s *entities.SavedSearch, car *entities.Car) error {
+	match := &entities.SavedSearchMatch{SavedSearchID: s.ID, CarID: car.ID, UserID: s.UserID}
+	created, err := u.SavedSearchRepo.CreateMatch(match)
+	if err != nil || !created || s.Frequency != "instant" {
+		return err
+	}
+
+	carID := car.ID
+	if err := u.NotificationUsecase.Notify(&entities.Notification{
+		UserID: s.UserID,
+		Type:   "saved_search",
+		Title:  fmt.Sprintf("รถใหม่ตรงกับการค้นหา \"%s\"", s.Name),
+		Body:   fmt.Sprintf("%s %s ปี %d ราคา %.0f บาท", car.Brand, car.ModelName, car.Year, car.Price),
+		CarID:  &carID,
+	}); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := u.SavedSearchRepo.MarkMatchesNotified([]uint{match.ID}, now); err != nil {
+		return err
+	}
+	return u.SavedSearchRepo.SetLastNotified(s.ID, now)
+}
+
+// SendDailyDigests sends one summary notification per daily saved search with
+// undelivered matches, at most once every 24 hours
+func (u *SavedSearchUsecase) SendDailyDigests() error {
+	now := time.Now()
+	var matches []*entities.SavedSearchMatch
+	if err := u.SavedSearchRepo.FindPendingDigestMatches(now.Add(-24*time.Hour), &matches); err != nil {
+		return err
+	}
+
+	bySearch := map[uint][]*entities.SavedSearchMatch{}
+	for _, m := range matches {
+		bySearch[m.SavedSearchID] = append(bySearch[m.SavedSearchID], m)
+	}
+
+	for searchID, group := range bySearch {
+		if err := u.sendDigest(searchID, group, now); err != nil {
+			log.Printf("saved search %d: daily digest failed: %v", searchID, err)
+		}
+	}
+	return nil
+}
+
+// sendDigest sends one daily summary and marks its matches delivered
+func (u *SavedSearchUsecase) sendDigest(searchID uint, group []*entities.SavedSearchMatch, now time.Time) error {
+	var s entities.SavedSearch
+	if err := u.SavedSearchRepo.FindByID(searchID, &s); err != nil {
+		return err
+	}
+
+	n := &entities.Notification{
+		UserID: s.UserID,
+		Type:   "saved_search_digest",
+		Title:  fmt.Sprintf("รถใหม่ %d คันตรงกับการค้นหา \"%s\"", len(group), s.Name),
+		Body:   "ดูรถใหม่ที่ตรงกับการค้นหาที่บันทึกไว้ของคุณ",
+	}
+	if len(group) == 1 {
+		n.CarID = &group[0].CarID
+	}
+	if err := u.NotificationUsecase.Notify(n); err != nil {
+		return err
+	}
+
+	ids := make([]uint, len(group))
+	for i, m := range group {
+		ids[i] = m.ID
+	}
+	if err := u.SavedSearchRepo.MarkMatchesNotified(ids, now); err != nil {
+		return err
+	}
+	return u.SavedSearchRepo.SetLastNotified(s.ID, now)
+}
+
+func validateSavedSearch(s *entities.SavedSearch) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if s.Frequency == "" {
+		s.Frequency = "instant"
+	}
+	if s.Frequency != "instant" && s.Frequency != "daily" {
+		return errors.New("frequency must be instant or daily")
+	}
+
+	f := s.Filters
+	if f.YearMin > 0 && f.YearMax > 0 && f.YearMin > f.YearMax {
+		return errors.New("year_min must not be greater than year_max")
+	}
+	if f.PriceMin > 0 && f.PriceMax > 0 && f.PriceMin > f.PriceMax {
+		return errors.New("price_min must not be greater than price_max")
+	}
+	return nil
+}
+
+// matchesFilter applies the saved filter to a single car, mirroring the public listing filters
+func matchesFilter(f entities.SavedSearchFilter, car *entities.Car) bool {
+	switch {
+	case len(f.Brands) > 0 && !containsFold(f.Brands, car.Brand),
+		f.ModelName != "" && !strings.Contains(strings.ToLower(car.ModelName), strings.ToLower(f.ModelName)),
+		f.YearMin > 0 && car.Year < f.YearMin,
+		f.YearMax > 0 && car.Year > f.YearMax,
+		f.PriceMin > 0 && car.Price < f.PriceMin,
+		f.PriceMax > 0 && car.Price > f.PriceMax,
+		f.MileageMax > 0 && car.Mileage > f.MileageMax,
+		len(f.FuelTypes) > 0 && !containsFold(f.FuelTypes, car.FuelType),
+		len(f.Transmission) > 0 && !containsFold(f.Transmission, car.Transmission),
+		len(f.CarTypes) > 0 && !containsFold(f.CarTypes, car.CarType),
+		len(f.Colors) > 0 && !containsFold(f.Colors, car.Color),
+		f.Province != "" && car.Dealer.Province != f.Province:
+		return false
+	}
+
+	if f.Query != "" {
+		words := map[string]bool{}
+		for _, t := range strings.Fields(car.SearchText) {
+			words[t] = true
+		}
+		for _, t := range search.Tokenize(f.Query) {
+			if !words[t] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, v string) bool {
+	for _, x := range values {
+		if strings.EqualFold(x, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package savedsearch
+
+import (
+	"Backend_Go/internal/entities"
+	"testing"
+)
+
+func TestMatchesFilter(t *testing.T) {
+	car := &entities.Car{
+		Brand:        "Toyota",
+		ModelName:    "Camry Hybrid",
+		Year:         2020,
+		Price:        850000,
+		Mileage:      40000,
+		FuelType:     "hybrid",
+		Transmission: "auto",
+		Dealer:       entities.Dealer{Province: "Bangkok"},
+	}
+
+	tests := []struct {
+		name   string
+		filter entities.SavedSearchFilter
+		want   bool
+	}{
+		{"empty filter", entities.SavedSearchFilter{}, true},
+		{"brand case-insensitive", entities.SavedSearchFilter{Brands: []string{"toyota"}}, true},
+		{"other brand", entities.SavedSearchFilter{Brands: []string{"Honda"}}, false},
+		{"model substring", entities.SavedSearchFilter{ModelName: "camry"}, true},
+		{"year range", entities.SavedSearchFilter{YearMin: 2018, YearMax: 2021}, true},
+		{"too old", entities.SavedSearchFilter{YearMin: 2021}, false},
+		{"over budget", entities.SavedSearchFilter{PriceMax: 800000}, false},
+		{"mileage", entities.SavedSearchFilter{MileageMax: 30000}, false},
+		{"fuel", entities.SavedSearchFilter{FuelTypes: []string{"Hybrid", "EV"}}, true},
+		{"province", entities.SavedSearchFilter{Province: "Chiang Mai"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchesFilter(tt.filter, car); got != tt.want {
+			t.Errorf("%s: matchesFilter = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSavedSearch(t *testing.T) {
+	s := &entities.SavedSearch{Name: "  SUV  "}
+	if err := validateSavedSearch(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Name != "SUV" || s.Frequency != "instant" {
+		t.Errorf("got name %q frequency %q, want trimmed name and instant", s.Name, s.Frequency)
+	}
+
+	invalid := []*entities.SavedSearch{
+		{Name: " "},
+		{Name: "x", Frequency: "weekly"},
+		{Name: "x", Filters: entities.SavedSearchFilter{YearMin: 2022, YearMax: 2020}},
+		{Name: "x", Filters: entities.SavedSearchFilter{PriceMin: 10, PriceMax: 5}},
+	}
+	for i, s := range invalid {
+		if err := validateSavedSearch(s); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
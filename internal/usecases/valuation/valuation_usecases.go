@@ -0,0 +1,287 @@
+package valuation
+
+import (
+	"Backend_Go/internal/entities"
+	"Backend_Go/internal/repositories"
+	"Backend_Go/internal/usecases/catalog"
+	"Backend_Go/utils"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ประเมินราคากลางจากประกาศที่เทียบเคียงได้ในระบบ
+type ValuationUsecase struct {
+	CarRepo        *repositories.CarRepository
+	CatalogUsecase *catalog.CatalogUsecase
+}
+
+// Confidence of an estimate, from the sample size and how tightly prices agree
+const (
+	ConfidenceHigh         = "high"
+	ConfidenceMedium       = "medium"
+	ConfidenceLow          = "low"
+	ConfidenceInsufficient = "insufficient"
+)
+
+// Deal ratings of a listing price against the estimate
+const (
+	DealGood        = "good_deal"
+	DealFair        = "fair"
+	DealAboveMarket = "above_market"
+)
+
+// minSample is the fewest comparables an estimate is given for
+const minSample = 3
+
+// Query describes the car to value; Mileage is optional
+type Query struct {
+	Brand     string
+	ModelName string
+	Year      int
+	Mileage   *int
+}
+
+// Valuation is a fair price range (25th-75th percentile of comparable
+// listings adjusted to the car's year and mileage). Low, Fair and High are
+// zero when the confidence is "insufficient".
+type Valuation struct {
+	Brand      string  `json:"brand"`
+	ModelName  string  `json:"model_name"`
+	Year       int     `json:"year"`
+	Mileage    *int    `json:"mileage,omitempty"`
+	Low        float64 `json:"low,omitempty"`
+	Fair       float64 `json:"fair,omitempty"`
+	High       float64 `json:"high,omitempty"`
+	SampleSize int     `json:"sample_size"`
+	SoldCount  int     `json:"sold_count"`
+	Confidence string  `json:"confidence"`
+	// Set when valuing a listing
+	CarID            uint     `json:"car_id,omitempty"`
+	ListingPrice     float64  `json:"listing_price,omitempty"`
+	DealRating       string   `json:"deal_rating,omitempty"`
+	PriceDiffPercent *float64 `json:"price_diff_percent,omitempty"` // listing price vs. fair price
+}
+
+// Estimate values a car described by brand, model, year and mileage
+func (u *ValuationUsecase) Estimate(q Query) (*Valuation, error) {
+	q.Brand, q.ModelName = strings.TrimSpace(q.Brand), strings.TrimSpace(q.ModelName)
+	if q.Brand == "" || q.ModelName == "" {
+		return nil, errors.New("brand and model are required")
+	}
+	if q.Year < 1900 || q.Year > time.Now().Year()+1 {
+		return nil, errors.New("year must be between 1900 and next year")
+	}
+	if q.Mileage != nil && *q.Mileage < 0 {
+		return nil, errors.New("mileage cannot be negative")
+	}
+
+	// Match spellings such as "โตโยต้า" through the catalog
+	subject := &entities.Car{Brand: q.Brand, ModelName: q.ModelName, Year: q.Year}
+	if u.CatalogUsecase != nil {
+		if err := u.CatalogUsecase.Normalize(subject); err != nil {
+			return nil, err
+		}
+	}
+	return u.estimate(subject, q.Mileage)
+}
+
+// EstimateCar values a public listing and rates its price against the market
+func (u *ValuationUsecase) EstimateCar(carID uint) (*Valuation, error) {
+	var car entities.Car
+	if err := u.CarRepo.FindByID(carID, &car); err != nil || !car.IsPublic(time.Now()) {
+		return nil, errors.New("car not found")
+	}
+	return u.estimateCar(&car)
+}
+
+// RefreshCar re-rates a listing and stores the rating on the car, so listing
+// responses can show it without valuing every car on the page
+func (u *ValuationUsecase) RefreshCar(car *entities.Car) error {
+	v, err := u.estimateCar(car)
+	if err != nil {
+		return err
+	}
+	if v.DealRating == car.DealRating {
+		return nil
+	}
+	car.DealRating = v.DealRating
+	return u.CarRepo.UpdateDealRating(car.ID, v.DealRating)
+}
+
+// RefreshDealRatings re-rates every public listing as the market moves.
+// A failing car is logged and skipped.
+func (u *ValuationUsecase) RefreshDealRatings() error {
+	return u.CarRepo.EachPublicCar(func(car *entities.Car) error {
+		if err := u.RefreshCar(car); err != nil {
+			log.Printf("deal rating failed for car %d: %v", car.ID, err)
+		}
+		return nil
+	})
+}
+
+func (u *ValuationUsecase) estimateCar(car *entities.Car) (*Valuation, error) {
+	mileage := car.Mileage
+	v, err := u.estimate(car, &mileage)
+	if err != nil {
+		return nil, err
+	}
+	v.CarID, v.ListingPrice = car.ID, car.Price
+	if v.Confidence == ConfidenceInsufficient {
+		return v, nil
+	}
+
+	v.DealRating = rate(car.Price, v)
+	diff := math.Round((car.Price-v.Fair)/v.Fair*1000) / 10
+	v.PriceDiffPercent = &diff
+	return v, nil
+}
+
+// rate compares a listing price with the fair price range
+func rate(price float64, v *Valuation) string {
+	switch {
+	case price < v.Low:
+		return DealGood
+	case price > v.High:
+		return DealAboveMarket
+	default:
+		return DealFair
+	}
+}
+
+type sample struct {
+	price float64
+	sold  bool
+}
+
+func (u *ValuationUsecase) estimate(subject *entities.Car, mileage *int) (*Valuation, error) {
+	window := envInt("VALUATION_YEAR_WINDOW", 3)
+	var comps []*entities.Car
+	if err := u.CarRepo.FindComparables(repositories.ComparableFilter{
+		ModelID:   subject.ModelID,
+		Brand:     subject.Brand,
+		ModelName: subject.ModelName,
+		YearMin:   subject.Year - window,
+		YearMax:   subject.Year + window,
+		SoldSince: time.Now().AddDate(-1, 0, 0),
+		ExcludeID: subject.ID,
+	}, &comps); err != nil {
+		return nil, err
+	}
+
+	samples := make([]sample, 0, len(comps))
+	for _, c := range comps {
+		samples = append(samples, sample{
+			price: adjust(c, subject.Year, mileage),
+			sold:  c.Status == entities.CarStatusSold,
+		})
+	}
+	v := &Valuation{
+		Brand:     subject.Brand,
+		ModelName: subject.ModelName,
+		Year:      subject.Year,
+		Mileage:   mileage,
+	}
+	summarize(v, dropOutliers(samples))
+	return v, nil
+}
+
+// summarize fills the price range and confidence of v from the comparables
+func summarize(v *Valuation, samples []sample) {
+	v.SampleSize = len(samples)
+	v.Confidence = ConfidenceInsufficient
+	for _, s := range samples {
+		if s.sold {
+			v.SoldCount++
+		}
+	}
+	if len(samples) < minSample {
+		return
+	}
+
+	v.Low = roundPrice(percentile(samples, 0.25))
+	v.Fair = roundPrice(percentile(samples, 0.5))
+	v.High = roundPrice(percentile(samples, 0.75))
+
+	// Comparables priced under 500 baht round to a zero fair price; there is
+	// nothing sensible to compare against, so report insufficient data
+	if v.Fair <= 0 {
+		v.Low, v.Fair, v.High = 0, 0, 0
+		return
+	}
+
+	spread := (v.High - v.Low) / v.Fair
+	switch {
+	case len(samples) >= 15 && spread <= 0.25:
+		v.Confidence = ConfidenceHigh
+	case len(samples) >= 6 && spread <= 0.5:
+		v.Confidence = ConfidenceMedium
+	default:
+		v.Confidence = ConfidenceLow
+	}
+}
+
+// adjust moves a comparable's price to the subject's model year and mileage:
+// VALUATION_DEPRECIATION_RATE (default 0.08) per year of age and
+// VALUATION_MILEAGE_RATE (default 0.01) per 10,000 km, capped at ±30%
+func adjust(c *entities.Car, year int, mileage *int) float64 {
+	price := c.Price * math.Pow(1-envFloat("VALUATION_DEPRECIATION_RATE", 0.08), float64(c.Year-year))
+	if mileage != nil {
+		factor := 1 - envFloat("VALUATION_MILEAGE_RATE", 0.01)*float64(*mileage-c.Mileage)/10000
+		price *= math.Max(0.7, math.Min(1.3, factor))
+	}
+	return price
+}
+
+// dropOutliers sorts the samples by price and removes those outside 1.5 IQR
+func dropOutliers(samples []sample) []sample {
+	sort.Slice(samples, func(i, j int) bool { return samples[i].price < samples[j].price })
+	if len(samples) < 4 {
+		return samples
+	}
+	q1, q3 := percentile(samples, 0.25), percentile(samples, 0.75)
+	lo, hi := q1-1.5*(q3-q1), q3+1.5*(q3-q1)
+
+	kept := samples[:0]
+	for _, s := range samples {
+		if s.price >= lo && s.price <= hi {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// percentile interpolates the p-th percentile of samples sorted by price
+func percentile(samples []sample, p float64) float64 {
+	pos := p * float64(len(samples)-1)
+	i := int(pos)
+	if i+1 >= len(samples) {
+		return samples[len(samples)-1].price
+	}
+	return samples[i].price + (samples[i+1].price-samples[i].price)*(pos-float64(i))
+}
+
+// roundPrice rounds to the nearest 1,000 baht
+func roundPrice(price float64) float64 {
+	return math.Round(price/1000) * 1000
+}
+
+func envInt(key string, fallback int) int {
+	n, err := strconv.Atoi(utils.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+func envFloat(key string, fallback float64) float64 {
+	f, err := strconv.ParseFloat(utils.GetEnv(key, ""), 64)
+	if err != nil || f < 0 || f >= 1 {
+		return fallback
+	}
+	return f
+}
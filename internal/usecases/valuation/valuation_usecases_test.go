@@ -0,0 +1,80 @@
+package valuation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func samplesOf(prices ...float64) []sample {
+	out := make([]sample, 0, len(prices))
+	for _, p := range prices {
+		out = append(out, sample{price: p})
+	}
+	return out
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name       string
+		prices     []float64
+		confidence string
+		fair       float64
+	}{
+		{"too few comparables", []float64{500000, 520000}, ConfidenceInsufficient, 0},
+		{"prices that round to zero", []float64{100, 200, 300, 400}, ConfidenceInsufficient, 0},
+		{"small sample", []float64{480000, 500000, 520000}, ConfidenceLow, 500000},
+		{"tight agreement", []float64{490000, 495000, 500000, 500000, 505000, 510000}, ConfidenceMedium, 500000},
+	}
+	for _, tt := range tests {
+		v := &Valuation{}
+		summarize(v, samplesOf(tt.prices...))
+		if v.Confidence != tt.confidence || v.Fair != tt.fair {
+			t.Errorf("%s: confidence %s, fair %v; want %s, %v", tt.name, v.Confidence, v.Fair, tt.confidence, tt.fair)
+		}
+		if v.Confidence == ConfidenceInsufficient && (v.Low != 0 || v.High != 0) {
+			t.Errorf("%s: insufficient estimate has a range %v-%v", tt.name, v.Low, v.High)
+		}
+		if _, err := json.Marshal(v); err != nil {
+			t.Errorf("%s: response cannot be encoded: %v", tt.name, err)
+		}
+	}
+}
+
+func TestRoundPrice(t *testing.T) {
+	tests := map[float64]float64{
+		499.0:     0,
+		500.0:     1000,
+		512345.0:  512000,
+		1499500.0: 1500000,
+	}
+	for in, want := range tests {
+		if got := roundPrice(in); got != want {
+			t.Errorf("roundPrice(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	s := samplesOf(100, 200, 300, 400, 500)
+	if got := percentile(s, 0.5); got != 300 {
+		t.Errorf("median = %v, want 300", got)
+	}
+	if got := percentile(s, 0.25); got != 200 {
+		t.Errorf("25th percentile = %v, want 200", got)
+	}
+}
+
+func TestRate(t *testing.T) {
+	v := &Valuation{Low: 450000, Fair: 500000, High: 550000}
+	tests := map[float64]string{
+		449000: DealGood,
+		450000: DealFair,
+		550000: DealFair,
+		551000: DealAboveMarket,
+	}
+	for price, want := range tests {
+		if got := rate(price, v); got != want {
+			t.Errorf("rate(%v) = %s, want %s", price, got, want)
+		}
+	}
+}
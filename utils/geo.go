@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseCoordinates parses a latitude/longitude pair given as text.
+// Both empty means "no location" and returns nil, nil.
+func ParseCoordinates(latitude, longitude string) (*float64, *float64, error) {
+	latitude = strings.TrimSpace(latitude)
+	longitude = strings.TrimSpace(longitude)
+	if latitude == "" && longitude == "" {
+		return nil, nil, nil
+	}
+	if latitude == "" || longitude == "" {
+		return nil, nil, errors.New("latitude and longitude must be given together")
+	}
+
+	lat, err := strconv.ParseFloat(latitude, 64)
+	if err != nil {
+		return nil, nil, errors.New("invalid latitude")
+	}
+	lng, err := strconv.ParseFloat(longitude, 64)
+	if err != nil {
+		return nil, nil, errors.New("invalid longitude")
+	}
+	if err := ValidateCoordinates(lat, lng); err != nil {
+		return nil, nil, err
+	}
+	return &lat, &lng, nil
+}
+
+// ValidateCoordinates checks that a point lies on the globe.
+// The ranges are written so that NaN fails them too.
+func ValidateCoordinates(lat, lng float64) error {
+	if !(lat >= -90 && lat <= 90) {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if !(lng >= -180 && lng <= 180) {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// HaversineKm returns the great-circle distance between two points in km
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusKm = 6371
+	toRad := func(d float64) float64 { return d * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadiusKm * 2 * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lng string
+		wantNil  bool
+		wantErr  bool
+	}{
+		{name: "valid", lat: "13.7563", lng: "100.5018"},
+		{name: "both empty", lat: "", lng: " ", wantNil: true},
+		{name: "one empty", lat: "13.7", lng: "", wantErr: true},
+		{name: "not a number", lat: "abc", lng: "100", wantErr: true},
+		{name: "out of range", lat: "91", lng: "100", wantErr: true},
+		{name: "NaN", lat: "NaN", lng: "100", wantErr: true},
+		{name: "infinite", lat: "13", lng: "+Inf", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lng, err := ParseCoordinates(tt.lat, tt.lng)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (lat == nil) != tt.wantNil {
+				t.Fatalf("lat = %v, wantNil %v", lat, tt.wantNil)
+			}
+			if err == nil && !tt.wantNil && (lat == nil || lng == nil) {
+				t.Fatal("expected both coordinates")
+			}
+		})
+	}
+}
+
+func TestValidateCoordinatesRejectsNaN(t *testing.T) {
+	if err := ValidateCoordinates(math.NaN(), 0); err == nil {
+		t.Error("NaN latitude accepted")
+	}
+	if err := ValidateCoordinates(0, math.NaN()); err == nil {
+		t.Error("NaN longitude accepted")
+	}
+	if err := ValidateCoordinates(-90, 180); err != nil {
+		t.Errorf("edge of range rejected: %v", err)
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	// Bangkok to Chiang Mai is roughly 580 km
+	d := HaversineKm(13.7563, 100.5018, 18.7883, 98.9853)
+	if d < 560 || d > 600 {
+		t.Errorf("HaversineKm = %.1f, want about 580", d)
+	}
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	localTZ     *time.Location
+	localTZOnce sync.Once
+)
+
+// LocalTimezone returns the marketplace time zone (APP_TIMEZONE, default Asia/Bangkok)
+// used for calendar days in stats and schedules
+func LocalTimezone() *time.Location {
+	localTZOnce.Do(func() {
+		name := GetEnv("APP_TIMEZONE", "Asia/Bangkok")
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			log.Printf("unknown APP_TIMEZONE %q, using UTC+7: %v", name, err)
+			loc = time.FixedZone("ICT", 7*60*60)
+		}
+		localTZ = loc
+	})
+	return localTZ
+}
+
+// StartOfDay returns midnight of t's calendar day in the marketplace time zone
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(LocalTimezone())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}